@@ -0,0 +1,346 @@
+package snmpsimclient
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/soniah/gosnmp"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+LabAccess describes how SNMP managers can access the endpoints of a lab, see DescribeLabAccess.
+*/
+type LabAccess struct {
+	LabID   int          `json:"lab_id"`
+	Lab     string       `json:"lab"`
+	Targets []SNMPTarget `json:"targets"`
+}
+
+/*
+SNMPTarget is a single way to query an endpoint of a lab: SNMPv2c with a community or SNMPv3 with a user.
+*/
+type SNMPTarget struct {
+	Agent    string `json:"agent"`
+	Engine   string `json:"engine"`
+	Endpoint string `json:"endpoint"`
+	Address  string `json:"address"`
+	Protocol string `json:"protocol"`
+	// Version is "2c" or "3". Targets with SNMPv2c can be queried with SNMPv1 as well.
+	Version string `json:"version"`
+	// Community is the community of SNMPv2c targets.
+	Community string `json:"community,omitempty"`
+	// User contains the credentials of SNMPv3 targets.
+	User *USMCredentials `json:"user,omitempty"`
+	// ContextName selects the record file of SNMPv3 targets, it is the community of the record file.
+	ContextName string `json:"context_name,omitempty"`
+	// ContextEngineID is the engine id of SNMPv3 targets as hex string, it is empty if the engine id is generated by snmpsim.
+	ContextEngineID string `json:"context_engine_id,omitempty"`
+}
+
+/*
+USMCredentials are the credentials of an SNMPv3 user.
+*/
+type USMCredentials struct {
+	User      string       `json:"user"`
+	AuthProto AuthProtocol `json:"auth_proto"`
+	AuthKey   string       `json:"auth_key,omitempty"`
+	PrivProto PrivProtocol `json:"priv_proto"`
+	PrivKey   string       `json:"priv_key,omitempty"`
+}
+
+/*
+SecurityLevel returns the SNMPv3 security level of the credentials: noAuthNoPriv, authNoPriv or authPriv.
+*/
+func (u USMCredentials) SecurityLevel() string {
+	if u.AuthProto == AuthNone {
+		return "noAuthNoPriv"
+	}
+	if u.PrivProto == PrivNone {
+		return "authNoPriv"
+	}
+	return "authPriv"
+}
+
+/*
+DescribeLabAccess returns how every endpoint of the lab with the given id can be queried.
+There is an SNMPv2c target for every community and an SNMPv3 target for every user and community, the communities are taken from the paths of the record files in the data dir of the agent, e.g. public.snmprec is served with the community public.
+Endpoints of agents without record files are left out, as there is nothing snmpsim could answer with.
+*/
+func (c *ManagementClient) DescribeLabAccess(labID int) (LabAccess, error) {
+	return c.DescribeLabAccessCtx(context.Background(), labID)
+}
+
+/*
+DescribeLabAccessCtx is like DescribeLabAccess but uses the given context for the requests.
+*/
+func (c *ManagementClient) DescribeLabAccessCtx(ctx context.Context, labID int) (LabAccess, error) {
+	if !c.isValid() {
+		return LabAccess{}, &NotValidError{}
+	}
+
+	lab, err := c.GetLabCtx(ctx, labID)
+	if err != nil {
+		return LabAccess{}, errors.Wrap(err, "error while getting lab")
+	}
+	recordings, err := c.GetRecordFilesCtx(ctx)
+	if err != nil {
+		return LabAccess{}, errors.Wrap(err, "error while getting record files")
+	}
+
+	access := LabAccess{LabID: lab.ID, Lab: lab.Name, Targets: []SNMPTarget{}}
+	for _, agent := range lab.Agents {
+		communities := communitiesOfDataDir(recordings, agent.DataDir)
+		if len(communities) == 0 {
+			continue
+		}
+		for _, engine := range agent.Engines {
+			var contextEngineID string
+			if id, err := ParseEngineID(engine.EngineID); err == nil {
+				contextEngineID = id.String()
+			}
+			for _, endpoint := range engine.Endpoints {
+				target := SNMPTarget{
+					Agent:    agent.Name,
+					Engine:   engine.Name,
+					Endpoint: endpoint.Name,
+					Address:  endpoint.Address,
+					Protocol: endpoint.Protocol,
+				}
+				for _, community := range communities {
+					v2c := target
+					v2c.Version = "2c"
+					v2c.Community = community
+					access.Targets = append(access.Targets, v2c)
+				}
+				for _, user := range engine.Users {
+					credentials, err := usmCredentials(user)
+					if err != nil {
+						return LabAccess{}, err
+					}
+					for _, community := range communities {
+						v3 := target
+						v3.Version = "3"
+						v3.User = &credentials
+						v3.ContextName = community
+						v3.ContextEngineID = contextEngineID
+						access.Targets = append(access.Targets, v3)
+					}
+				}
+			}
+		}
+	}
+	return access, nil
+}
+
+func usmCredentials(user User) (USMCredentials, error) {
+	auth, err := ParseAuthProtocol(user.AuthProto)
+	if err != nil {
+		return USMCredentials{}, errors.Wrap(err, "invalid user "+user.Name)
+	}
+	priv, err := ParsePrivProtocol(user.PrivProto)
+	if err != nil {
+		return USMCredentials{}, errors.Wrap(err, "invalid user "+user.Name)
+	}
+	return USMCredentials{User: user.User, AuthProto: auth, AuthKey: user.AuthKey, PrivProto: priv, PrivKey: user.PrivKey}, nil
+}
+
+/*
+Inventory returns the lab access as indented JSON.
+*/
+func (l LabAccess) Inventory() ([]byte, error) {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "error during marshal")
+	}
+	return b, nil
+}
+
+/*
+GoSNMP returns a gosnmp client configured for the target, it still has to be connected.
+An error is returned if gosnmp does not support the protocols of the user.
+*/
+func (t SNMPTarget) GoSNMP() (*gosnmp.GoSNMP, error) {
+	host, port, err := t.hostAndPort()
+	if err != nil {
+		return nil, err
+	}
+	snmp := &gosnmp.GoSNMP{
+		Target:             host,
+		Port:               port,
+		Transport:          "udp",
+		Community:          t.Community,
+		Version:            gosnmp.Version2c,
+		Timeout:            2 * time.Second,
+		Retries:            3,
+		ExponentialTimeout: true,
+		MaxOids:            gosnmp.MaxOids,
+	}
+	if t.Protocol == "udpv6" {
+		snmp.Transport = "udp6"
+	}
+	if t.User == nil {
+		return snmp, nil
+	}
+
+	user := User{User: t.User.User, AuthKey: t.User.AuthKey, AuthProto: string(t.User.AuthProto), PrivKey: t.User.PrivKey, PrivProto: string(t.User.PrivProto)}
+	if !setSNMPv3User(snmp, user, t.ContextName) {
+		return nil, errors.New("protocols " + string(t.User.AuthProto) + "/" + string(t.User.PrivProto) + " of user " + t.User.User + " are not supported by gosnmp")
+	}
+	if t.ContextEngineID != "" {
+		id, err := ParseEngineID(t.ContextEngineID)
+		if err != nil {
+			return nil, err
+		}
+		snmp.ContextEngineID = string(id.Bytes())
+	}
+	return snmp, nil
+}
+
+/*
+NetSNMPCommand returns the command line to run the given net-snmp command, e.g. snmpwalk, against the target.
+The arguments are appended after the agent, e.g. the oids to query.
+*/
+func (t SNMPTarget) NetSNMPCommand(command string, args ...string) (string, error) {
+	agent, err := t.netSNMPAgent()
+	if err != nil {
+		return "", err
+	}
+	options, err := t.netSNMPOptions()
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{command}
+	for _, option := range options {
+		parts = append(parts, option[0], shellQuote(option[1]))
+	}
+	parts = append(parts, shellQuote(agent))
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+/*
+NetSNMPConfig returns a net-snmp configuration with the credentials of the target as defaults.
+It can be used as snmp.conf or as hosts/HOST.conf in the net-snmp config dir to apply it to a single host only.
+*/
+func (t SNMPTarget) NetSNMPConfig() (string, error) {
+	options, err := t.netSNMPOptions()
+	if err != nil {
+		return "", err
+	}
+	tokens := map[string]string{
+		"-v": "defVersion",
+		"-c": "defCommunity",
+		"-u": "defSecurityName",
+		"-l": "defSecurityLevel",
+		"-a": "defAuthType",
+		"-A": "defAuthPassphrase",
+		"-x": "defPrivType",
+		"-X": "defPrivPassphrase",
+		"-n": "defContext",
+	}
+
+	lines := []string{"# " + t.Endpoint + " (" + t.Address + ") of engine " + t.Engine + " of agent " + t.Agent}
+	for _, option := range options {
+		token, ok := tokens[option[0]]
+		if !ok {
+			continue
+		}
+		lines = append(lines, token+" "+option[1])
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// netSNMPOptions returns the options of net-snmp commands for the credentials of the target.
+func (t SNMPTarget) netSNMPOptions() ([][2]string, error) {
+	if t.User == nil {
+		return [][2]string{{"-v", "2c"}, {"-c", t.Community}}, nil
+	}
+
+	authTypes := map[AuthProtocol]string{AuthMD5: "MD5", AuthSHA: "SHA", AuthSHA224: "SHA-224", AuthSHA256: "SHA-256", AuthSHA384: "SHA-384", AuthSHA512: "SHA-512"}
+	//snmpsim extends the keys of aes192 and aes256 like cisco does, net-snmp calls these protocols AES-192-C and AES-256-C
+	privTypes := map[PrivProtocol]string{PrivDES: "DES", PrivAES: "AES", PrivAES128: "AES", PrivAES192: "AES-192-C", PrivAES256: "AES-256-C"}
+
+	options := [][2]string{{"-v", "3"}, {"-u", t.User.User}, {"-l", t.User.SecurityLevel()}}
+	if t.User.AuthProto != AuthNone {
+		authType, ok := authTypes[t.User.AuthProto]
+		if !ok {
+			return nil, errors.New("auth protocol " + string(t.User.AuthProto) + " is not supported by net-snmp")
+		}
+		options = append(options, [2]string{"-a", authType}, [2]string{"-A", t.User.AuthKey})
+	}
+	if t.User.PrivProto != PrivNone {
+		privType, ok := privTypes[t.User.PrivProto]
+		if !ok {
+			return nil, errors.New("priv protocol " + string(t.User.PrivProto) + " is not supported by net-snmp")
+		}
+		options = append(options, [2]string{"-x", privType}, [2]string{"-X", t.User.PrivKey})
+	}
+	if t.ContextName != "" {
+		options = append(options, [2]string{"-n", t.ContextName})
+	}
+	if t.ContextEngineID != "" {
+		options = append(options, [2]string{"-E", "0x" + t.ContextEngineID})
+	}
+	return options, nil
+}
+
+// netSNMPAgent returns the agent specification of net-snmp commands, e.g. udp:127.0.0.1:1161 or udp6:[::1]:1161.
+func (t SNMPTarget) netSNMPAgent() (string, error) {
+	host, port, err := t.hostAndPort()
+	if err != nil {
+		return "", err
+	}
+	transport := "udp"
+	if t.Protocol == "udpv6" {
+		transport = "udp6"
+	}
+	return transport + ":" + net.JoinHostPort(host, strconv.Itoa(int(port))), nil
+}
+
+func (t SNMPTarget) hostAndPort() (string, uint16, error) {
+	host, portString, err := net.SplitHostPort(t.Address)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "invalid endpoint address "+t.Address)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return "", 0, errors.Wrap(err, "invalid endpoint port "+portString)
+	}
+	return host, uint16(port), nil
+}
+
+// shellQuote quotes s for a posix shell if it contains characters other than letters, digits and a few safe punctuation characters.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.,:/@[]+=") == "" {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// communitiesOfDataDir returns the sorted and distinct communities of all record files in the given data dir, which are their paths relative to the data dir without the .snmprec extension.
+// Other files, e.g. data files of variation modules, are not served as communities.
+func communitiesOfDataDir(recordings Recordings, dataDir string) []string {
+	seen := make(map[string]bool)
+	var communities []string
+	for _, recording := range recordings {
+		relativePath, ok := pathInDataDir(recording.Path, dataDir)
+		if !ok || !strings.HasSuffix(relativePath, ".snmprec") {
+			continue
+		}
+		community := strings.TrimSuffix(relativePath, ".snmprec")
+		if !seen[community] {
+			seen[community] = true
+			communities = append(communities, community)
+		}
+	}
+	sort.Strings(communities)
+	return communities
+}
@@ -0,0 +1,135 @@
+package snmpsimclient
+
+import (
+	"encoding/json"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestManagementClient_DescribeLabAccess(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab := setUpBundleTestLab(t, client)
+	content := "1.3.6.1.2.1.1.1.0|4|private\n"
+	assert.NoError(t, client.UploadRecordFileString(&content, "test-LabSpec-agent1/sub/private.snmprec"))
+
+	access, err := client.DescribeLabAccess(lab.ID)
+	if !assert.NoError(t, err, "error during DescribeLabAccess") {
+		return
+	}
+	assert.Equal(t, lab.ID, access.LabID)
+	assert.Equal(t, "test-LabSpec-lab1", access.Lab)
+	if !assert.Len(t, access.Targets, 4) {
+		return
+	}
+
+	v2c := access.Targets[0]
+	assert.Equal(t, SNMPTarget{
+		Agent:     "test-LabSpec-agent1",
+		Engine:    "test-LabSpec-engine1",
+		Endpoint:  "test-LabSpec-endpoint1",
+		Address:   "127.0.0.1:1161",
+		Protocol:  "udpv4",
+		Version:   "2c",
+		Community: "public",
+	}, v2c)
+	assert.Equal(t, "sub/private", access.Targets[1].Community)
+
+	v3 := access.Targets[2]
+	assert.Equal(t, "3", v3.Version)
+	assert.Equal(t, "public", v3.ContextName)
+	assert.Equal(t, "010203040507080c00000000", v3.ContextEngineID)
+	if assert.NotNil(t, v3.User) {
+		assert.Equal(t, USMCredentials{User: "test-LabSpec-user1", AuthProto: AuthMD5, AuthKey: "authkey1", PrivProto: PrivNone}, *v3.User)
+		assert.Equal(t, "authNoPriv", v3.User.SecurityLevel())
+	}
+	assert.Equal(t, "sub/private", access.Targets[3].ContextName)
+
+	snmp, err := v2c.GoSNMP()
+	if assert.NoError(t, err, "error during GoSNMP") {
+		assert.Equal(t, "127.0.0.1", snmp.Target)
+		assert.Equal(t, uint16(1161), snmp.Port)
+		assert.Equal(t, gosnmp.Version2c, snmp.Version)
+		assert.Equal(t, "public", snmp.Community)
+	}
+	snmp, err = v3.GoSNMP()
+	if assert.NoError(t, err, "error during GoSNMP") {
+		assert.Equal(t, gosnmp.Version3, snmp.Version)
+		assert.Equal(t, gosnmp.AuthNoPriv, snmp.MsgFlags)
+		assert.Equal(t, "public", snmp.ContextName)
+		assert.Equal(t, "\x01\x02\x03\x04\x05\x07\x08\x0c\x00\x00\x00\x00", snmp.ContextEngineID)
+		if params, ok := snmp.SecurityParameters.(*gosnmp.UsmSecurityParameters); assert.True(t, ok) {
+			assert.Equal(t, "test-LabSpec-user1", params.UserName)
+			assert.Equal(t, gosnmp.MD5, params.AuthenticationProtocol)
+		}
+	}
+
+	command, err := v2c.NetSNMPCommand("snmpget", "1.3.6.1.2.1.1.1.0")
+	if assert.NoError(t, err, "error during NetSNMPCommand") {
+		assert.Equal(t, "snmpget -v 2c -c public udp:127.0.0.1:1161 1.3.6.1.2.1.1.1.0", command)
+	}
+	command, err = access.Targets[3].NetSNMPCommand("snmpwalk")
+	if assert.NoError(t, err, "error during NetSNMPCommand") {
+		assert.Equal(t, "snmpwalk -v 3 -u test-LabSpec-user1 -l authNoPriv -a MD5 -A authkey1 -n sub/private -E 0x010203040507080c00000000 udp:127.0.0.1:1161", command)
+	}
+	config, err := v3.NetSNMPConfig()
+	if assert.NoError(t, err, "error during NetSNMPConfig") {
+		assert.Equal(t, "# test-LabSpec-endpoint1 (127.0.0.1:1161) of engine test-LabSpec-engine1 of agent test-LabSpec-agent1\n"+
+			"defVersion 3\ndefSecurityName test-LabSpec-user1\ndefSecurityLevel authNoPriv\ndefAuthType MD5\ndefAuthPassphrase authkey1\ndefContext public\n", config)
+	}
+
+	inventory, err := access.Inventory()
+	if assert.NoError(t, err, "error during Inventory") {
+		var decoded LabAccess
+		if assert.NoError(t, json.Unmarshal(inventory, &decoded)) {
+			assert.Equal(t, access, decoded)
+		}
+	}
+
+	_, err = client.DescribeLabAccess(-1)
+	assert.Error(t, err, "no error for unknown lab")
+}
+
+func TestSNMPTarget_NetSNMP(t *testing.T) {
+	target := SNMPTarget{
+		Address:  "[::1]:1161",
+		Protocol: "udpv6",
+		Version:  "3",
+		User:     &USMCredentials{User: "user", AuthProto: AuthSHA256, AuthKey: "it's secret", PrivProto: PrivAES256, PrivKey: "privkey1"},
+	}
+	command, err := target.NetSNMPCommand("snmpget", "sysDescr.0")
+	if assert.NoError(t, err) {
+		assert.Equal(t, `snmpget -v 3 -u user -l authPriv -a SHA-256 -A 'it'\''s secret' -x AES-256-C -X privkey1 udp6:[::1]:1161 sysDescr.0`, command)
+	}
+	_, err = target.GoSNMP()
+	assert.Error(t, err, "no error for protocols gosnmp does not support")
+
+	target.User.PrivProto = Priv3DES
+	_, err = target.NetSNMPCommand("snmpget")
+	assert.Error(t, err, "no error for protocol net-snmp does not support")
+}
+
+func TestCommunitiesOfDataDir(t *testing.T) {
+	recordings := Recordings{
+		{Path: "foo/public.snmprec"},
+		{Path: "foo/public.snmpwalk"},
+		{Path: "foo/walk.sapwalk"},
+		{Path: "foo/variation/data.db"},
+		{Path: "foo/sub/private.snmprec"},
+		{Path: "foobar/other.snmprec"},
+		{Path: "bar/public.snmprec"},
+	}
+	for _, dataDir := range []string{"foo", "foo/", "./foo", "/foo"} {
+		assert.Equal(t, []string{"public", "sub/private"}, communitiesOfDataDir(recordings, dataDir), "data dir "+dataDir)
+	}
+	assert.Empty(t, communitiesOfDataDir(recordings, "baz"))
+	assert.Equal(t, "public", communityOfDataDir(recordings, "./foo"))
+	assert.Equal(t, "other", communityOfDataDir(recordings, "foobar"))
+}
@@ -0,0 +1,160 @@
+package snmpsimclient
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"net/http"
+	"sync"
+)
+
+/*
+Authenticator adds credentials to every request of a client, see SetAuthenticator.
+*/
+type Authenticator interface {
+	// Authenticate sets the credentials in the header of a request.
+	Authenticate(ctx context.Context, header http.Header) error
+}
+
+/*
+RefreshingAuthenticator is an Authenticator whose credentials can expire.
+If the api answers a request with 401, Refresh is called and the request is sent once more with the new credentials.
+*/
+type RefreshingAuthenticator interface {
+	Authenticator
+	// Refresh discards the current credentials and obtains new ones.
+	Refresh(ctx context.Context) error
+}
+
+/*
+AuthenticatorFunc is an adapter to use a func as Authenticator.
+*/
+type AuthenticatorFunc func(ctx context.Context, header http.Header) error
+
+/*
+Authenticate calls f.
+*/
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, header http.Header) error {
+	return f(ctx, header)
+}
+
+/*
+BasicAuth returns an Authenticator for http basic auth.
+*/
+func BasicAuth(username, password string) Authenticator {
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return AuthenticatorFunc(func(ctx context.Context, header http.Header) error {
+		header.Set("Authorization", "Basic "+credentials)
+		return nil
+	})
+}
+
+/*
+BearerToken returns an Authenticator which sends a static bearer token.
+*/
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, header http.Header) error {
+		header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+/*
+APIKey returns an Authenticator which sends the key in a custom header, e.g. APIKey("X-API-Key", key).
+*/
+func APIKey(headerName, key string) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, header http.Header) error {
+		header.Set(headerName, key)
+		return nil
+	})
+}
+
+/*
+TokenSource obtains bearer tokens, e.g. from an OAuth2 or OIDC provider.
+*/
+type TokenSource interface {
+	// Token returns a new token.
+	Token(ctx context.Context) (string, error)
+}
+
+/*
+TokenSourceFunc is an adapter to use a func as TokenSource.
+*/
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+/*
+Token calls f.
+*/
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+/*
+TokenSourceAuthenticator sends bearer tokens obtained from a TokenSource.
+The token is requested once and reused until the api answers with 401, then a new token is requested.
+*/
+type TokenSourceAuthenticator struct {
+	source TokenSource
+	mutex  sync.Mutex
+	token  string
+}
+
+/*
+NewTokenSourceAuthenticator creates a new authenticator for the given token source.
+*/
+func NewTokenSourceAuthenticator(source TokenSource) *TokenSourceAuthenticator {
+	return &TokenSourceAuthenticator{source: source}
+}
+
+/*
+Authenticate sets the current token, a token is requested from the token source if there is none yet.
+*/
+func (t *TokenSourceAuthenticator) Authenticate(ctx context.Context, header http.Header) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.token == "" {
+		if err := t.refresh(ctx); err != nil {
+			return err
+		}
+	}
+	header.Set("Authorization", "Bearer "+t.token)
+	return nil
+}
+
+/*
+Refresh requests a new token from the token source.
+*/
+func (t *TokenSourceAuthenticator) Refresh(ctx context.Context) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.refresh(ctx)
+}
+
+func (t *TokenSourceAuthenticator) refresh(ctx context.Context) error {
+	if t.source == nil {
+		return errors.New("no token source")
+	}
+	token, err := t.source.Token(ctx)
+	if err != nil {
+		t.token = ""
+		return errors.Wrap(err, "error while getting token")
+	}
+	if token == "" {
+		t.token = ""
+		return errors.New("token source returned an empty token")
+	}
+	t.token = token
+	return nil
+}
+
+/*
+SetAuthenticator sets the authenticator which adds credentials to every request, nil disables authentication.
+It replaces credentials set with SetUsernameAndPassword.
+*/
+func (c *client) SetAuthenticator(authenticator Authenticator) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	c.authenticator = authenticator
+	return nil
+}
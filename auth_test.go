@@ -0,0 +1,109 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestClient_Authenticators(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(newOptionsTestHandler(&requests))
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	_, err = client.GetLabs(nil)
+	if assert.NoError(t, err, "error during GetLabs") {
+		assert.Empty(t, requests[len(requests)-1].Header.Get("Authorization"))
+	}
+
+	assert.NoError(t, client.SetUsernameAndPassword("user", "pass"))
+	_, err = client.GetLabs(nil)
+	if assert.NoError(t, err, "error during GetLabs") {
+		username, password, ok := requests[len(requests)-1].BasicAuth()
+		assert.True(t, ok, "no basic auth")
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+	}
+
+	assert.NoError(t, client.SetAuthenticator(BearerToken("secret")))
+	_, err = client.GetLabs(nil)
+	if assert.NoError(t, err, "error during GetLabs") {
+		assert.Equal(t, "Bearer secret", requests[len(requests)-1].Header.Get("Authorization"))
+	}
+
+	assert.NoError(t, client.SetAuthenticator(APIKey("X-API-Key", "key")))
+	_, err = client.GetLabs(nil)
+	if assert.NoError(t, err, "error during GetLabs") {
+		assert.Equal(t, "key", requests[len(requests)-1].Header.Get("X-API-Key"))
+		assert.Empty(t, requests[len(requests)-1].Header.Get("Authorization"))
+	}
+
+	assert.NoError(t, client.SetAuthenticator(AuthenticatorFunc(func(ctx context.Context, header http.Header) error {
+		return errors.New("no credentials")
+	})))
+	count := len(requests)
+	_, err = client.GetLabs(nil)
+	assert.Error(t, err, "no error for failing authenticator")
+	assert.Len(t, requests, count, "request was sent despite failing authenticator")
+}
+
+func TestClient_TokenSourceAuthenticator(t *testing.T) {
+	validToken := "token-1"
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			w.WriteHeader(401)
+			_, _ = w.Write([]byte(`{"message": "Unauthorized"}`))
+			return
+		}
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	var issued int
+	authenticator := NewTokenSourceAuthenticator(TokenSourceFunc(func(ctx context.Context) (string, error) {
+		issued++
+		return "token-" + strconv.Itoa(issued), nil
+	}))
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	assert.NoError(t, client.SetAuthenticator(authenticator))
+
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "error during GetLabs")
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "error during GetLabs")
+	assert.Equal(t, 1, issued, "token was not reused")
+	assert.Equal(t, 2, requests)
+
+	//the token expires, the request is sent once more with a new token
+	validToken = "token-2"
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "error during GetLabs after token expiry")
+	assert.Equal(t, 2, issued, "token was not refreshed")
+	assert.Equal(t, 4, requests)
+
+	//a refreshed token which is rejected as well is not refreshed again
+	validToken = "none"
+	_, err = client.GetLabs(nil)
+	var httpErr HTTPError
+	if assert.True(t, errors.As(err, &httpErr), "error is not an HTTPError") {
+		assert.Equal(t, 401, httpErr.StatusCode)
+	}
+	assert.Equal(t, 3, issued)
+	assert.Equal(t, 6, requests)
+}
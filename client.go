@@ -1,6 +1,7 @@
 package snmpsimclient
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/go-resty/resty/v2"
 	"github.com/pkg/errors"
@@ -22,12 +23,20 @@ type client struct {
 }
 
 type clientData struct {
-	baseURL  string
-	username string
-	password string
+	baseURL string
 
-	resty   *resty.Client
-	useAuth bool
+	resty         *resty.Client
+	authenticator Authenticator
+
+	logger    Logger
+	logBodies bool
+
+	retryPolicy *RetryPolicy
+
+	validateRecordFiles bool
+	validateFilters     bool
+
+	portAllocator *PortAllocator
 }
 
 /*
@@ -46,6 +55,7 @@ func (c *client) isValid() bool {
 
 /*
 SetUsernameAndPassword is used to set a username and password for https auth
+It replaces an authenticator set with SetAuthenticator.
 */
 func (c *client) SetUsernameAndPassword(username, password string) error {
 	if !c.isValid() {
@@ -57,21 +67,56 @@ func (c *client) SetUsernameAndPassword(username, password string) error {
 	if password == "" {
 		return errors.New("invalid password")
 	}
-	c.username = username
-	c.password = password
-	c.useAuth = true
+	c.authenticator = BasicAuth(username, password)
 	return nil
 }
 
 /*
 SetTimeout can be used to set a timeout for requests raised from client.
+For a per-call deadline or cancellation use the ...Ctx variants of the client methods.
 */
 func (c *client) SetTimeout(timeout time.Duration) {
 	c.resty.SetTimeout(timeout)
 }
 
-func (c *client) request(method string, path string, body string, header, queryParams map[string]string) (*resty.Response, error) {
+func (c *client) request(ctx context.Context, method string, path string, body string, header, queryParams map[string]string) (*resty.Response, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, err := c.sendAuthenticated(ctx, method, path, body, header, queryParams)
+		if !c.retryPolicy.shouldRetry(ctx, method, attempt, response, err) {
+			return response, err
+		}
+		err = c.retryPolicy.wait(ctx, attempt, method, path, response, err)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while waiting for retry")
+		}
+	}
+}
+
+//sendAuthenticated sends a request and sends it once more with refreshed credentials if the api answers with 401.
+func (c *client) sendAuthenticated(ctx context.Context, method string, path string, body string, header, queryParams map[string]string) (*resty.Response, error) {
+	response, err := c.send(ctx, method, path, body, header, queryParams)
+	if err != nil || response.StatusCode() != 401 {
+		return response, err
+	}
+	refresher, ok := c.authenticator.(RefreshingAuthenticator)
+	if !ok {
+		return response, nil
+	}
+	err = refresher.Refresh(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while refreshing credentials")
+	}
+	return c.send(ctx, method, path, body, header, queryParams)
+}
+
+//send performs a single attempt of a request.
+func (c *client) send(ctx context.Context, method string, path string, body string, header, queryParams map[string]string) (*resty.Response, error) {
 	request := c.resty.R()
+	request.SetContext(ctx)
 	request.SetHeader("Content-Type", "application/json")
 
 	if header != nil {
@@ -86,8 +131,11 @@ func (c *client) request(method string, path string, body string, header, queryP
 		request.SetBody(body)
 	}
 
-	if c.useAuth {
-		request.SetBasicAuth(c.username, c.password)
+	if c.authenticator != nil {
+		err := c.authenticator.Authenticate(ctx, request.Header)
+		if err != nil {
+			return nil, errors.Wrap(err, "error during authentication")
+		}
 	}
 
 	var response *resty.Response
@@ -96,6 +144,19 @@ func (c *client) request(method string, path string, body string, header, queryP
 	var err error
 	err = nil
 
+	if c.logger != nil {
+		started := time.Now()
+		defer func() {
+			var status int
+			var responseBody []byte
+			if response != nil {
+				status = response.StatusCode()
+				responseBody = response.Body()
+			}
+			c.logRequest(method, path, queryParams, request.Header, body, started, status, responseBody, err)
+		}()
+	}
+
 	switch method {
 	case "GET":
 		response, err = request.Get(c.baseURL + urlEscapePath(path))
@@ -116,28 +177,77 @@ func (c *client) request(method string, path string, body string, header, queryP
 
 //Http error handling
 
+var (
+	// ErrValidation is matched by http errors caused by invalid parameters (400, 422).
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized is matched by http errors caused by missing or wrong credentials (401).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is matched by http errors caused by insufficient permissions (403).
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is matched by http errors caused by a non existing object (404).
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is matched by http errors caused by a conflict with an existing object (409).
+	ErrConflict = errors.New("conflict")
+	// ErrServer is matched by http errors caused by an internal failure of the api (5xx).
+	ErrServer = errors.New("server error")
+)
+
 /*
 HTTPError represents an http error returned by the api.
+It can be checked against the sentinel errors with errors.Is, e.g. errors.Is(err, ErrNotFound).
 */
 type HTTPError struct {
 	StatusCode int
 	Status     string
 	Body       *ErrorResponse
+	Method     string
+	Path       string
 }
 
 func (h HTTPError) Error() string {
 	msg := "http error: status code: " + strconv.Itoa(h.StatusCode) + " // status: " + h.Status
+	if h.Method != "" {
+		msg += " // request: " + h.Method + " " + h.Path
+	}
 	if h.Body != nil {
 		msg += " // message: " + h.Body.Message
 	}
 	return msg
 }
 
+/*
+Is reports whether the http error belongs to the category of the given sentinel error.
+*/
+func (h HTTPError) Is(target error) bool {
+	switch target {
+	case ErrValidation:
+		return h.StatusCode == 400 || h.StatusCode == 422
+	case ErrUnauthorized:
+		return h.StatusCode == 401
+	case ErrForbidden:
+		return h.StatusCode == 403
+	case ErrNotFound:
+		return h.StatusCode == 404
+	case ErrConflict:
+		return h.StatusCode == 409
+	case ErrServer:
+		return h.StatusCode >= 500 && h.StatusCode < 600
+	}
+	return false
+}
+
 func getHTTPError(response *resty.Response) error {
 	httpError := HTTPError{
 		StatusCode: response.StatusCode(),
 		Status:     response.Status(),
 	}
+	if response.Request != nil {
+		httpError.Method = response.Request.Method
+		httpError.Path = response.Request.URL
+		if u, err := url.Parse(response.Request.URL); err == nil {
+			httpError.Path = u.Path
+		}
+	}
 	var errorResponse ErrorResponse
 	err := json.Unmarshal(response.Body(), &errorResponse)
 	if err != nil {
@@ -0,0 +1,96 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_RequestContextDeadline(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.GetLabsCtx(ctx, nil)
+	assert.Error(t, err, "no error when the context deadline was exceeded")
+	assert.True(t, time.Since(start) < 5*time.Second, "request was not cancelled by the context deadline")
+}
+
+func TestClient_RequestContextCanceled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = client.GetPacketsCtx(ctx, nil)
+	assert.Error(t, err, "no error when the context was already canceled")
+	assert.Equal(t, 0, requests, "request was sent although the context was canceled")
+}
+
+func TestClient_HTTPErrorTaxonomy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + mgmtEndpointPath + "labs/1":
+			w.WriteHeader(404)
+			_, _ = w.Write([]byte(`{"message": "lab not found", "status": 404}`))
+		case "/" + mgmtEndpointPath + "labs":
+			w.WriteHeader(400)
+		default:
+			w.WriteHeader(503)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	_, err = client.GetLab(1)
+	if assert.Error(t, err, "no error when getting a non existing lab") {
+		assert.True(t, errors.Is(err, ErrNotFound), "error is not ErrNotFound")
+		assert.False(t, errors.Is(err, ErrValidation), "error is ErrValidation")
+		var httpError HTTPError
+		if assert.True(t, errors.As(err, &httpError), "error is not a http error") {
+			assert.Equal(t, "GET", httpError.Method)
+			assert.Equal(t, "/"+mgmtEndpointPath+"labs/1", httpError.Path)
+			assert.Equal(t, "lab not found", httpError.Body.Message)
+		}
+	}
+
+	_, err = client.CreateLab("lab")
+	assert.True(t, errors.Is(err, ErrValidation), "error is not ErrValidation")
+
+	err = client.DeleteEngine(1)
+	assert.True(t, errors.Is(err, ErrServer), "error is not ErrServer")
+	assert.True(t, errors.Is(errors.Wrap(err, "wrapped"), ErrServer), "wrapped error is not ErrServer")
+}
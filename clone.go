@@ -0,0 +1,235 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+)
+
+/*
+CloneOptions contains the options for CloneLab.
+*/
+type CloneOptions struct {
+	// NameSuffix is appended to the names of the cloned lab, agents, engines, endpoints and users. It must not be empty.
+	// As SNMPv3 user names have to be unique, it is appended to the user names of the cloned users as well.
+	NameSuffix string
+	// ShareUsers links the users of the lab to the cloned engines instead of cloning them, so the clone accepts the same SNMPv3 credentials.
+	ShareUsers bool
+	// Address returns the address of a cloned endpoint, e.g. PortOffset(100). If it is nil, the addresses are kept.
+	Address func(address string) (string, error)
+	// CopyRecordings copies the record files inside of the data dirs of the agents to the data dirs of the cloned agents.
+	CopyRecordings bool
+	// DataDir returns the data dir of a cloned agent. If it is nil, the data dir is kept, or NameSuffix is appended if CopyRecordings is set.
+	// It is required to copy the record files of agents which use the root data dir, e.g. "" or ".".
+	DataDir func(dataDir string) string
+}
+
+/*
+PortOffset returns an address mapping for CloneOptions which adds the offset to the port of an address.
+*/
+func PortOffset(offset int) func(address string) (string, error) {
+	return func(address string) (string, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return "", errors.Wrap(err, "invalid address "+address)
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return "", errors.Wrap(err, "invalid port "+port)
+		}
+		p += offset
+		if p < 1 || p > 65535 {
+			return "", errors.New("port " + strconv.Itoa(p) + " of the cloned endpoint of " + address + " is out of range")
+		}
+		return net.JoinHostPort(host, strconv.Itoa(p)), nil
+	}
+}
+
+/*
+CloneLab creates a copy of the lab with the given id, including copies of all of its agents, engines, endpoints and users and all links between them.
+Objects which are linked more than once inside of the lab are copied once. The clone is powered off and tags are not copied.
+If one of the steps fails, everything that was created so far is deleted again.
+*/
+func (c *ManagementClient) CloneLab(labID int, opts CloneOptions) (Lab, error) {
+	return c.CloneLabCtx(context.Background(), labID, opts)
+}
+
+/*
+CloneLabCtx is like CloneLab but uses the given context for the requests.
+*/
+func (c *ManagementClient) CloneLabCtx(ctx context.Context, labID int, opts CloneOptions) (Lab, error) {
+	if !c.isValid() {
+		return Lab{}, &NotValidError{}
+	}
+	if opts.NameSuffix == "" {
+		return Lab{}, errors.New("invalid name suffix")
+	}
+
+	lab, err := c.GetLabCtx(ctx, labID)
+	if err != nil {
+		return Lab{}, errors.Wrap(err, "error while getting lab")
+	}
+
+	var recordings Recordings
+	if opts.CopyRecordings {
+		recordings, err = c.GetRecordFilesCtx(ctx)
+		if err != nil {
+			return Lab{}, errors.Wrap(err, "error while getting record files")
+		}
+	}
+
+	tx := c.BeginTransactionCtx(ctx)
+	clone, err := c.cloneLab(tx, lab, recordings, opts)
+	if err != nil {
+		//failed transaction calls roll back by themselves, other errors need an explicit rollback
+		if !tx.finished {
+			if errs := tx.rollback(context.Background()); len(errs) != 0 {
+				return Lab{}, &RollbackError{Err: err, RollbackErrors: errs}
+			}
+		}
+		return Lab{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return Lab{}, err
+	}
+	return c.GetLabCtx(ctx, clone.ID)
+}
+
+func (c *ManagementClient) cloneLab(tx *Transaction, lab Lab, recordings Recordings, opts CloneOptions) (Lab, error) {
+	clone, err := tx.CreateLab(lab.Name + opts.NameSuffix)
+	if err != nil {
+		return Lab{}, errors.Wrap(err, "error while creating lab")
+	}
+
+	agents := make(map[int]int)
+	engines := make(map[int]int)
+	endpoints := make(map[int]int)
+	users := make(map[int]int)
+	copiedDataDirs := make(map[string]bool)
+	for _, agent := range lab.Agents {
+		agentID, cloned := agents[agent.ID]
+		if !cloned {
+			agentID, err = c.cloneAgent(tx, agent, recordings, copiedDataDirs, opts)
+			if err != nil {
+				return Lab{}, err
+			}
+			agents[agent.ID] = agentID
+		}
+		err = tx.AddAgentToLab(clone.ID, agentID)
+		if err != nil {
+			return Lab{}, errors.Wrap(err, "error while adding agent "+agent.Name+" to lab")
+		}
+		if cloned {
+			continue
+		}
+
+		for _, engine := range agent.Engines {
+			engineID, cloned := engines[engine.ID]
+			if !cloned {
+				newEngine, err := tx.CreateEngine(engine.Name+opts.NameSuffix, engine.EngineID)
+				if err != nil {
+					return Lab{}, errors.Wrap(err, "error while creating engine "+engine.Name)
+				}
+				engineID = newEngine.ID
+				engines[engine.ID] = engineID
+			}
+			err = tx.AddEngineToAgent(agentID, engineID)
+			if err != nil {
+				return Lab{}, errors.Wrap(err, "error while adding engine "+engine.Name+" to agent")
+			}
+			if cloned {
+				continue
+			}
+
+			for _, endpoint := range engine.Endpoints {
+				endpointID, ok := endpoints[endpoint.ID]
+				if !ok {
+					address := endpoint.Address
+					if opts.Address != nil {
+						address, err = opts.Address(endpoint.Address)
+						if err != nil {
+							return Lab{}, errors.Wrap(err, "error while mapping address of endpoint "+endpoint.Name)
+						}
+					}
+					newEndpoint, err := tx.CreateEndpoint(endpoint.Name+opts.NameSuffix, address, endpoint.Protocol)
+					if err != nil {
+						return Lab{}, errors.Wrap(err, "error while creating endpoint "+endpoint.Name)
+					}
+					endpointID = newEndpoint.ID
+					endpoints[endpoint.ID] = endpointID
+				}
+				err = tx.AddEndpointToEngine(engineID, endpointID)
+				if err != nil {
+					return Lab{}, errors.Wrap(err, "error while adding endpoint "+endpoint.Name+" to engine")
+				}
+			}
+
+			for _, user := range engine.Users {
+				userID, ok := users[user.ID]
+				if opts.ShareUsers {
+					userID, ok = user.ID, true
+				}
+				if !ok {
+					newUser, err := tx.CreateUser(user.User+opts.NameSuffix, user.Name+opts.NameSuffix, user.AuthKey, user.AuthProto, user.PrivKey, user.PrivProto)
+					if err != nil {
+						return Lab{}, errors.Wrap(err, "error while creating user "+user.Name)
+					}
+					userID = newUser.ID
+					users[user.ID] = userID
+				}
+				err = tx.AddUserToEngine(engineID, userID)
+				if err != nil {
+					return Lab{}, errors.Wrap(err, "error while adding user "+user.Name+" to engine")
+				}
+			}
+		}
+	}
+	return clone, nil
+}
+
+// cloneAgent creates the copy of an agent and copies the record files inside of its data dir if necessary.
+// Data dirs which are shared by several agents are copied once, copiedDataDirs contains the data dirs which have been copied already.
+func (c *ManagementClient) cloneAgent(tx *Transaction, agent Agent, recordings Recordings, copiedDataDirs map[string]bool, opts CloneOptions) (int, error) {
+	//appending the suffix to the root data dir would result in a hidden dir like ".-copy" and every record file of the server would be copied
+	if opts.CopyRecordings && opts.DataDir == nil && strings.Trim(path.Clean("/"+agent.DataDir), "/") == "" {
+		return 0, errors.New("agent " + agent.Name + " uses the root data dir, a DataDir for the cloned agent is required to copy its record files")
+	}
+
+	dataDir := agent.DataDir
+	if opts.DataDir != nil {
+		dataDir = opts.DataDir(agent.DataDir)
+	} else if opts.CopyRecordings {
+		dataDir = strings.TrimSuffix(agent.DataDir, "/") + opts.NameSuffix
+	}
+
+	if opts.CopyRecordings && !copiedDataDirs[path.Clean(agent.DataDir)] {
+		if path.Clean(dataDir) == path.Clean(agent.DataDir) {
+			return 0, errors.New("cannot copy the record files of agent " + agent.Name + " into its own data dir")
+		}
+		copiedDataDirs[path.Clean(agent.DataDir)] = true
+		for _, recording := range recordings {
+			relative, ok := pathInDataDir(recording.Path, agent.DataDir)
+			if !ok {
+				continue
+			}
+			content, err := c.GetRecordFileCtx(tx.ctx, recording.Path)
+			if err != nil {
+				return 0, errors.Wrap(err, "error while getting record file "+recording.Path)
+			}
+			err = tx.UploadRecordFileString(&content, strings.TrimPrefix(path.Join(dataDir, relative), "/"))
+			if err != nil {
+				return 0, errors.Wrap(err, "error while copying record file "+recording.Path)
+			}
+		}
+	}
+
+	newAgent, err := tx.CreateAgent(agent.Name+opts.NameSuffix, dataDir)
+	if err != nil {
+		return 0, errors.Wrap(err, "error while creating agent "+agent.Name)
+	}
+	return newAgent.ID, nil
+}
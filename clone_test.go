@@ -0,0 +1,206 @@
+package snmpsimclient
+
+import (
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestPortOffset(t *testing.T) {
+	address, err := PortOffset(100)("127.0.0.1:1161")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "127.0.0.1:1261", address)
+	}
+	address, err = PortOffset(1)("[::1]:1161")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "[::1]:1162", address)
+	}
+	_, err = PortOffset(65535)("127.0.0.1:1161")
+	assert.Error(t, err, "no error for port out of range")
+	_, err = PortOffset(1)("127.0.0.1")
+	assert.Error(t, err, "no error for address without port")
+}
+
+func TestManagementClient_CloneLab(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab := setUpBundleTestLab(t, client)
+
+	for i := 1; i <= 2; i++ {
+		suffix := "-copy" + strconv.Itoa(i)
+		clone, err := client.CloneLab(lab.ID, CloneOptions{NameSuffix: suffix, Address: PortOffset(i), CopyRecordings: true})
+		if !assert.NoError(t, err, "error during CloneLab") {
+			return
+		}
+		assert.Equal(t, "test-LabSpec-lab1"+suffix, clone.Name)
+		assert.Equal(t, "off", clone.Power)
+		assert.Empty(t, clone.Tags, "tags were copied")
+		if !assert.Len(t, clone.Agents, 1) || !assert.Len(t, clone.Agents[0].Engines, 1) {
+			return
+		}
+		agent := clone.Agents[0]
+		assert.Equal(t, "test-LabSpec-agent1"+suffix, agent.Name)
+		assert.Equal(t, "test-LabSpec-agent1"+suffix, agent.DataDir)
+		engine := agent.Engines[0]
+		assert.Equal(t, "test-LabSpec-engine1"+suffix, engine.Name)
+		assert.Equal(t, "010203040507080C00000000", engine.EngineID)
+		if assert.Len(t, engine.Endpoints, 1) {
+			assert.Equal(t, "127.0.0.1:"+strconv.Itoa(1161+i), engine.Endpoints[0].Address)
+		}
+		if assert.Len(t, engine.Users, 1) {
+			assert.Equal(t, "test-LabSpec-user1"+suffix, engine.Users[0].Name)
+			assert.Equal(t, "test-LabSpec-user1"+suffix, engine.Users[0].User)
+			assert.Equal(t, "authkey1", engine.Users[0].AuthKey)
+		}
+		content, err := client.GetRecordFile("test-LabSpec-agent1" + suffix + "/public.snmprec")
+		if assert.NoError(t, err, "error during GetRecordFile") {
+			assert.Equal(t, "1.3.6.1.2.1.1.1.0|4|test\n", content)
+		}
+	}
+
+	shared, err := client.CloneLab(lab.ID, CloneOptions{NameSuffix: "-shared", Address: PortOffset(10), ShareUsers: true})
+	if assert.NoError(t, err, "error during CloneLab with shared users") && assert.Len(t, shared.Agents, 1) && assert.Len(t, shared.Agents[0].Engines, 1) {
+		assert.Equal(t, "test-LabSpec-agent1", shared.Agents[0].DataDir, "data dir was changed without copying record files")
+		if assert.Len(t, shared.Agents[0].Engines[0].Users, 1) {
+			assert.Equal(t, "test-LabSpec-user1", shared.Agents[0].Engines[0].Users[0].Name)
+		}
+	}
+
+	original, err := client.GetLab(lab.ID)
+	if assert.NoError(t, err, "error during GetLab") && assert.Len(t, original.Agents, 1) {
+		assert.Equal(t, "test-LabSpec-agent1", original.Agents[0].Name)
+	}
+
+	//the endpoint port is out of range, everything is deleted again
+	_, err = client.CloneLab(lab.ID, CloneOptions{NameSuffix: "-broken", Address: PortOffset(65535), CopyRecordings: true})
+	assert.Error(t, err, "no error for invalid address")
+	labs, err := client.GetLabs(nil)
+	if assert.NoError(t, err, "error during GetLabs") {
+		assert.Len(t, labs, 4, "lab of failed clone was not deleted")
+	}
+	agents, err := client.GetAgents(nil)
+	if assert.NoError(t, err, "error during GetAgents") {
+		assert.Len(t, agents, 4, "agent of failed clone was not deleted")
+	}
+	_, err = client.GetRecordFile("test-LabSpec-agent1-broken/public.snmprec")
+	assert.True(t, errors.Is(err, ErrNotFound), "record file of failed clone was not deleted")
+
+	_, err = client.CloneLab(lab.ID, CloneOptions{})
+	assert.Error(t, err, "no error for missing name suffix")
+	_, err = client.CloneLab(lab.ID, CloneOptions{NameSuffix: "-same", CopyRecordings: true, DataDir: func(dataDir string) string { return dataDir }})
+	assert.Error(t, err, "no error for copying record files into the same data dir")
+}
+
+func TestManagementClient_CloneLab_SharedDataDir(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	spec, err := ParseLabSpecYAML([]byte(`
+labs:
+  - name: lab1
+    agents: [agent1, agent2]
+agents:
+  - name: agent1
+    data_dir: shared
+    engines: [engine1]
+  - name: agent2
+    data_dir: shared/
+    engines: [engine2]
+engines:
+  - name: engine1
+    engine_id: "010203040507080900000000"
+    endpoints: [endpoint1]
+  - name: engine2
+    engine_id: "010203040507080A00000000"
+    endpoints: [endpoint2]
+endpoints:
+  - name: endpoint1
+    address: 127.0.0.1:1161
+  - name: endpoint2
+    address: 127.0.0.1:1162
+recordings:
+  - path: shared/public.snmprec
+    content: "1.3.6.1.2.1.1.1.0|4|test\n"
+`))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	if _, err := client.Apply(spec); !assert.NoError(t, err, "error during Apply") {
+		return
+	}
+	labs, err := client.GetLabs(LabFilter{Name: "lab1"}.Params())
+	if !assert.NoError(t, err, "error during GetLabs") || !assert.Len(t, labs, 1) {
+		return
+	}
+
+	clone, err := client.CloneLab(labs[0].ID, CloneOptions{NameSuffix: "-copy", Address: PortOffset(100), CopyRecordings: true})
+	if !assert.NoError(t, err, "error during CloneLab of agents with a shared data dir") {
+		return
+	}
+	if assert.Len(t, clone.Agents, 2) {
+		assert.Equal(t, "shared-copy", clone.Agents[0].DataDir)
+		assert.Equal(t, "shared-copy", clone.Agents[1].DataDir)
+	}
+	content, err := client.GetRecordFile("shared-copy/public.snmprec")
+	if assert.NoError(t, err, "error during GetRecordFile") {
+		assert.Equal(t, "1.3.6.1.2.1.1.1.0|4|test\n", content)
+	}
+}
+
+func TestManagementClient_CloneLab_RootDataDir(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	spec, err := ParseLabSpecYAML([]byte(`
+labs:
+  - name: lab1
+    agents: [agent1]
+agents:
+  - name: agent1
+    data_dir: .
+recordings:
+  - path: public.snmprec
+    content: "1.3.6.1.2.1.1.1.0|4|test\n"
+`))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	if _, err := client.Apply(spec); !assert.NoError(t, err, "error during Apply") {
+		return
+	}
+	labs, err := client.GetLabs(LabFilter{Name: "lab1"}.Params())
+	if !assert.NoError(t, err, "error during GetLabs") || !assert.Len(t, labs, 1) {
+		return
+	}
+
+	_, err = client.CloneLab(labs[0].ID, CloneOptions{NameSuffix: "-copy", CopyRecordings: true})
+	assert.Error(t, err, "no error when copying the record files of the root data dir without a DataDir")
+	recordings, err := client.GetRecordFiles()
+	if assert.NoError(t, err, "error during GetRecordFiles") {
+		assert.Len(t, recordings, 1, "record files were copied")
+	}
+
+	clone, err := client.CloneLab(labs[0].ID, CloneOptions{NameSuffix: "-copy", CopyRecordings: true, DataDir: func(string) string {
+		return "copy"
+	}})
+	if assert.NoError(t, err, "error during CloneLab with a DataDir") && assert.Len(t, clone.Agents, 1) {
+		assert.Equal(t, "copy", clone.Agents[0].DataDir)
+	}
+	_, err = client.GetRecordFile("copy/public.snmprec")
+	assert.NoError(t, err, "record file was not copied")
+}
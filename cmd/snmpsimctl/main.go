@@ -0,0 +1,23 @@
+/*
+Command snmpsimctl controls a snmpsim control plane from the command line.
+
+It exposes the operations of the management and the metrics api as subcommands, e.g.
+
+	snmpsimctl lab create myLab
+	snmpsimctl lab power 1 on
+	snmpsimctl recording upload public.snmprec agent/data/dir/public.snmprec
+	snmpsimctl metrics packets --filter local_address=127.0.0.1:1161 -o json
+
+The base urls and credentials are read from a snmpsimctl.yaml config file, environment variables or flags.
+*/
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	if err := newRootCommand(os.Stdout).Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func run(server *snmpsimtest.Server, args ...string) (string, error) {
+	var b strings.Builder
+	cmd := newRootCommand(&b)
+	cmd.SetErr(ioutil.Discard)
+	cmd.SetArgs(append([]string{"--management-url", server.URL, "--metrics-url", server.URL}, args...))
+	err := cmd.Execute()
+	return b.String(), err
+}
+
+func TestSnmpsimctl_Management(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	out, err := run(server, "lab", "create", "cliLab", "-o", "json")
+	if !assert.NoError(t, err, "error during lab create") {
+		return
+	}
+	var lab snmpsimclient.Lab
+	if !assert.NoError(t, json.Unmarshal([]byte(out), &lab), "lab create did not print json") {
+		return
+	}
+	assert.Equal(t, "cliLab", lab.Name)
+	labID := strconv.Itoa(lab.ID)
+
+	out, err = run(server, "agent", "create", "cliAgent", "/data", "-o", "json")
+	if !assert.NoError(t, err, "error during agent create") {
+		return
+	}
+	var agent snmpsimclient.Agent
+	if !assert.NoError(t, json.Unmarshal([]byte(out), &agent), "agent create did not print json") {
+		return
+	}
+
+	out, err = run(server, "lab", "add-agent", labID, strconv.Itoa(agent.ID))
+	if assert.NoError(t, err, "error during lab add-agent") {
+		assert.Equal(t, "added agent "+strconv.Itoa(agent.ID)+" to lab "+labID+"\n", out)
+	}
+	_, err = run(server, "lab", "power", labID, "on")
+	assert.NoError(t, err, "error during lab power")
+	_, err = run(server, "lab", "power", labID, "maybe")
+	assert.Error(t, err, "no error for invalid power state")
+
+	out, err = run(server, "agent", "update", strconv.Itoa(agent.ID), "--data-dir", "/moved", "-o", "json")
+	if assert.NoError(t, err, "error during agent update") {
+		var updated snmpsimclient.Agent
+		if assert.NoError(t, json.Unmarshal([]byte(out), &updated), "agent update did not print json") {
+			assert.Equal(t, "/moved", updated.DataDir)
+			assert.Equal(t, "cliAgent", updated.Name, "name without flag was updated")
+		}
+	}
+
+	out, err = run(server, "lab", "list", "--filter", "name=cliLab")
+	if assert.NoError(t, err, "error during lab list") {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if assert.Len(t, lines, 2, "table should contain a header and one lab") {
+			assert.Equal(t, []string{"ID", "NAME", "POWER", "AGENTS", "TAGS"}, strings.Fields(lines[0]))
+			assert.Equal(t, []string{labID, "cliLab", "on", strconv.Itoa(agent.ID)}, strings.Fields(lines[1]))
+		}
+	}
+
+	out, err = run(server, "lab", "get", labID, "-o", "yaml")
+	if assert.NoError(t, err, "error during lab get") {
+		assert.Contains(t, out, "name: cliLab\n")
+		assert.Contains(t, out, "power: \"on\"\n")
+	}
+
+	_, err = run(server, "lab", "delete", labID)
+	assert.NoError(t, err, "error during lab delete")
+	_, err = run(server, "lab", "get", labID)
+	assert.Error(t, err, "no error when getting a deleted lab")
+
+	_, err = run(server, "lab", "get", "abc")
+	assert.Error(t, err, "no error for invalid id")
+	_, err = run(server, "lab", "list", "-o", "xml")
+	assert.Error(t, err, "no error for invalid output format")
+
+	out, err = run(server, "engine", "generate-id", "192.168.0.1", "--format", "ipv4", "-o", "json")
+	if assert.NoError(t, err, "error during engine generate-id") {
+		assert.Equal(t, "\"80004fb801c0a80001\"\n", out)
+	}
+	_, err = run(server, "engine", "create", "cliEngine", "0102")
+	assert.Error(t, err, "no error for invalid engine id")
+}
+
+func TestSnmpsimctl_Recording(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "snmpsimctl")
+	if !assert.NoError(t, err, "error while creating temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	localPath := filepath.Join(dir, "walk.txt")
+	err = ioutil.WriteFile(localPath, []byte(".1.3.6.1.2.1.1.1.0 = STRING: \"test\"\n"), 0644)
+	if !assert.NoError(t, err, "error while writing snmpwalk output") {
+		return
+	}
+
+	_, err = run(server, "recording", "upload", localPath, "cli/test.snmprec", "--snmpwalk", "--validate")
+	if !assert.NoError(t, err, "error during recording upload") {
+		return
+	}
+	out, err := run(server, "recording", "get", "cli/test.snmprec")
+	if assert.NoError(t, err, "error during recording get") {
+		assert.Equal(t, "1.3.6.1.2.1.1.1.0|4|test\n", out)
+	}
+	out, err = run(server, "recording", "list")
+	if assert.NoError(t, err, "error during recording list") {
+		assert.Contains(t, out, "cli/test.snmprec")
+	}
+	_, err = run(server, "recording", "delete", "cli/test.snmprec")
+	assert.NoError(t, err, "error during recording delete")
+}
+
+func TestSnmpsimctl_Bundle(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "snmpsimctl")
+	if !assert.NoError(t, err, "error while creating temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "lab.tar.gz")
+
+	_, err = run(server, "lab", "create", "cliLab")
+	if !assert.NoError(t, err, "error during lab create") {
+		return
+	}
+	out, err := run(server, "lab", "export", "1", bundle)
+	if !assert.NoError(t, err, "error during lab export") {
+		return
+	}
+	assert.Contains(t, out, "exported lab 1")
+
+	_, err = run(server, "lab", "import", bundle)
+	assert.Error(t, err, "no error for existing lab")
+	out, err = run(server, "lab", "import", bundle, "--prefix", "copy-", "-o", "json")
+	if assert.NoError(t, err, "error during lab import") {
+		var lab snmpsimclient.Lab
+		if assert.NoError(t, json.Unmarshal([]byte(out), &lab), "lab import did not print json") {
+			assert.Equal(t, "copy-cliLab", lab.Name)
+		}
+	}
+}
+
+func TestSnmpsimctl_Access(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := snmpsimclient.NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	spec, err := snmpsimclient.ParseLabSpecYAML([]byte(`
+labs:
+  - name: cliLab
+    agents: [cliAgent]
+agents:
+  - name: cliAgent
+    data_dir: cli
+    engines: [cliEngine]
+engines:
+  - name: cliEngine
+    engine_id: "010203040507080900000000"
+    endpoints: [cliEndpoint]
+endpoints:
+  - name: cliEndpoint
+    address: 127.0.0.1:1161
+recordings:
+  - path: cli/public.snmprec
+    content: "1.3.6.1.2.1.1.1.0|4|test\n"
+`))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	if _, err := client.Apply(spec); !assert.NoError(t, err, "error during Apply") {
+		return
+	}
+	labs, err := client.GetLabs(nil)
+	if !assert.NoError(t, err, "error during GetLabs") || !assert.Len(t, labs, 1) {
+		return
+	}
+	labID := strconv.Itoa(labs[0].ID)
+
+	out, err := run(server, "lab", "access", labID)
+	if assert.NoError(t, err, "error during lab access") {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if assert.Len(t, lines, 2, "table should contain a header and one target") {
+			assert.Equal(t, []string{"cliEndpoint", "127.0.0.1:1161", "udpv4", "2c", "public", "-", "-"}, strings.Fields(lines[1]))
+		}
+	}
+	out, err = run(server, "lab", "access", labID, "--net-snmp", "snmpwalk")
+	if assert.NoError(t, err, "error during lab access") {
+		assert.Equal(t, "snmpwalk -v 2c -c public udp:127.0.0.1:1161\n", out)
+	}
+}
+
+func TestSnmpsimctl_Metrics(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	server.AddPacketActivity(snmpsimtest.PacketActivity{TransportProtocol: "udpv4", LocalAddress: "127.0.0.1:1161", PeerAddress: "127.0.0.1:50000", Total: 10})
+	server.AddPacketActivity(snmpsimtest.PacketActivity{TransportProtocol: "udpv4", LocalAddress: "127.0.0.1:1162", PeerAddress: "127.0.0.1:50000", Total: 5})
+
+	out, err := run(server, "metrics", "packets", "--filter", "local_address=127.0.0.1:1161", "-o", "json")
+	if assert.NoError(t, err, "error during metrics packets") {
+		var packets snmpsimclient.PacketMetrics
+		if assert.NoError(t, json.Unmarshal([]byte(out), &packets), "metrics packets did not print json") && assert.NotNil(t, packets.Total) {
+			assert.Equal(t, int64(10), *packets.Total)
+		}
+	}
+
+	out, err = run(server, "metrics", "packet-filter-values", "local_address")
+	if assert.NoError(t, err, "error during metrics packet-filter-values") {
+		assert.Equal(t, []string{"VALUE", "127.0.0.1:1161", "127.0.0.1:1162"}, strings.Fields(out))
+	}
+
+	_, err = run(server, "metrics", "packets", "--filter", "local_address")
+	assert.Error(t, err, "no error for invalid filter")
+}
@@ -0,0 +1,754 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+)
+
+type managementClient = snmpsimclient.ManagementClient
+
+// newListCommand creates a list command with a --filter flag, list returns the result and its table.
+func (a *app) newListCommand(kind string, list func(client *managementClient, filters map[string]string) (interface{}, table, error)) *cobra.Command {
+	var filterFlags []string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all " + kind + "s",
+		Args:  cobra.NoArgs,
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			filters, err := parseFilters(filterFlags)
+			if err != nil {
+				return err
+			}
+			result, t, err := list(client, filters)
+			if err != nil {
+				return err
+			}
+			return a.print(result, func() table { return t })
+		}),
+	}
+	cmd.Flags().StringArrayVar(&filterFlags, "filter", nil, "filter as key=value, can be repeated")
+	return cmd
+}
+
+// newGetCommand creates a get command for an object id, get returns the result and its table.
+func (a *app) newGetCommand(kind string, get func(client *managementClient, id int) (interface{}, table, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get ID",
+		Short: "Get a " + kind,
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			result, t, err := get(client, ids[0])
+			if err != nil {
+				return err
+			}
+			return a.print(result, func() table { return t })
+		}),
+	}
+}
+
+func (a *app) newDeleteCommand(kind string, del func(client *managementClient, id int) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete ID",
+		Short: "Delete a " + kind,
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			if err := del(client, ids[0]); err != nil {
+				return err
+			}
+			a.done("deleted %s %d", kind, ids[0])
+			return nil
+		}),
+	}
+}
+
+// newLinkCommand creates a command which links or unlinks two objects, message is formatted with both ids.
+func (a *app) newLinkCommand(use, short, message string, link func(client *managementClient, parentID, childID int) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			if err := link(client, ids[0], ids[1]); err != nil {
+				return err
+			}
+			a.done(message, ids[0], ids[1])
+			return nil
+		}),
+	}
+}
+
+// newUpdateCommand creates an update command with a flag for every field, only the fields whose flags are set are passed to update.
+func (a *app) newUpdateCommand(kind string, fields []string, update func(client *managementClient, id int, changed map[string]*string) (interface{}, table, error)) *cobra.Command {
+	values := make(map[string]*string)
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Update the fields of a " + kind,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.RunE = a.managementRunE(func(client *managementClient, args []string) error {
+		ids, err := parseIDs(args)
+		if err != nil {
+			return err
+		}
+		changed := make(map[string]*string)
+		for _, field := range fields {
+			if cmd.Flags().Changed(field) {
+				changed[field] = values[field]
+			}
+		}
+		result, t, err := update(client, ids[0], changed)
+		if err != nil {
+			return err
+		}
+		return a.print(result, func() table { return t })
+	})
+	for _, field := range fields {
+		values[field] = cmd.Flags().String(field, "", "new "+strings.Replace(field, "-", " ", -1)+" of the "+kind)
+	}
+	return cmd
+}
+
+func (a *app) newTagCommands(kind string, add, remove func(client *managementClient, id, tagID int) error) []*cobra.Command {
+	return []*cobra.Command{
+		a.newLinkCommand("add-tag ID TAG_ID", "Tag a "+kind, "added tag %[2]d to "+kind+" %[1]d", add),
+		a.newLinkCommand("remove-tag ID TAG_ID", "Remove a tag from a "+kind, "removed tag %[2]d from "+kind+" %[1]d", remove),
+	}
+}
+
+func (a *app) newLabCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "lab", Short: "Manage labs"}
+
+	var tagID int
+	create := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a lab",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			var lab snmpsimclient.Lab
+			var err error
+			if tagID != 0 {
+				lab, err = client.CreateLabWithTag(args[0], tagID)
+			} else {
+				lab, err = client.CreateLab(args[0])
+			}
+			if err != nil {
+				return err
+			}
+			return a.print(lab, func() table { return labsTable(lab) })
+		}),
+	}
+	create.Flags().IntVar(&tagID, "tag", 0, "id of a tag for the new lab")
+
+	power := &cobra.Command{
+		Use:   "power ID on|off",
+		Short: "Power a lab on or off",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			if args[1] != "on" && args[1] != "off" {
+				return errors.New("invalid power state " + args[1] + ", expected on or off")
+			}
+			if err := client.SetLabPower(ids[0], args[1] == "on"); err != nil {
+				return err
+			}
+			a.done("powered lab %d %s", ids[0], args[1])
+			return nil
+		}),
+	}
+
+	export := &cobra.Command{
+		Use:   "export ID FILE",
+		Short: "Export a lab with all of its objects and record files to a tar.gz bundle",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			var bundle bytes.Buffer
+			if err := client.ExportLab(ids[0], &bundle); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(args[1], bundle.Bytes(), 0600); err != nil {
+				return errors.Wrap(err, "failed to write bundle")
+			}
+			a.done("exported lab %d to %s", ids[0], args[1])
+			return nil
+		}),
+	}
+
+	var importOptions snmpsimclient.ImportLabOptions
+	var addresses []string
+	var onConflict string
+	importCommand := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import a lab bundle created with lab export",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			addressMap, err := parseKeyValues("address", addresses)
+			if err != nil {
+				return err
+			}
+			importOptions.AddressMap = addressMap
+			importOptions.OnConflict = snmpsimclient.ConflictPolicy(onConflict)
+			bundle, err := os.Open(args[0])
+			if err != nil {
+				return errors.Wrap(err, "failed to open bundle")
+			}
+			defer bundle.Close()
+			lab, err := client.ImportLab(bundle, importOptions)
+			if err != nil {
+				return err
+			}
+			return a.print(lab, func() table { return labsTable(lab) })
+		}),
+	}
+	importCommand.Flags().StringVar(&importOptions.NamePrefix, "prefix", "", "prefix for the names of all imported objects")
+	importCommand.Flags().StringArrayVar(&addresses, "address", nil, "replace an endpoint address, e.g. 127.0.0.1:1161=10.0.0.5:1161")
+	importCommand.Flags().StringVar(&onConflict, "on-conflict", "fail", "handling of existing objects: fail, skip or replace")
+
+	var cloneOptions snmpsimclient.CloneOptions
+	var portOffset int
+	clone := &cobra.Command{
+		Use:   "clone ID SUFFIX",
+		Short: "Clone a lab with all of its objects, the suffix is appended to all names",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args[:1])
+			if err != nil {
+				return err
+			}
+			cloneOptions.NameSuffix = args[1]
+			if portOffset != 0 {
+				cloneOptions.Address = snmpsimclient.PortOffset(portOffset)
+			}
+			lab, err := client.CloneLab(ids[0], cloneOptions)
+			if err != nil {
+				return err
+			}
+			return a.print(lab, func() table { return labsTable(lab) })
+		}),
+	}
+	clone.Flags().IntVar(&portOffset, "port-offset", 0, "offset which is added to the ports of the cloned endpoints")
+	clone.Flags().BoolVar(&cloneOptions.CopyRecordings, "copy-recordings", false, "copy the record files of the agents to new data dirs")
+	clone.Flags().BoolVar(&cloneOptions.ShareUsers, "share-users", false, "link the existing users instead of cloning them")
+
+	var netSNMPCommand string
+	access := &cobra.Command{
+		Use:   "access ID",
+		Short: "Describe how the endpoints of a lab can be queried",
+		Long:  "Describe how the endpoints of a lab can be queried, with --net-snmp the net-snmp command lines for all endpoints are printed instead.",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			access, err := client.DescribeLabAccess(ids[0])
+			if err != nil {
+				return err
+			}
+			if netSNMPCommand == "" {
+				return a.print(access, func() table { return accessTable(access) })
+			}
+			for _, target := range access.Targets {
+				line, err := target.NetSNMPCommand(netSNMPCommand)
+				if err != nil {
+					return err
+				}
+				if _, err := fmt.Fprintln(a.out, line); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	}
+	access.Flags().StringVar(&netSNMPCommand, "net-snmp", "", "net-snmp command, e.g. snmpwalk, to print the command lines for")
+
+	cmd.AddCommand(
+		a.newListCommand("lab", func(client *managementClient, filters map[string]string) (interface{}, table, error) {
+			labs, err := client.GetLabs(filters)
+			return labs, labsTable(labs...), err
+		}),
+		a.newGetCommand("lab", func(client *managementClient, id int) (interface{}, table, error) {
+			lab, err := client.GetLab(id)
+			return lab, labsTable(lab), err
+		}),
+		create,
+		a.newUpdateCommand("lab", []string{"name"}, func(client *managementClient, id int, changed map[string]*string) (interface{}, table, error) {
+			lab, err := client.UpdateLab(id, snmpsimclient.LabUpdate{Name: changed["name"]})
+			return lab, labsTable(lab), err
+		}),
+		a.newDeleteCommand("lab", (*managementClient).DeleteLab),
+		power,
+		export,
+		importCommand,
+		clone,
+		access,
+		a.newLinkCommand("add-agent LAB_ID AGENT_ID", "Add an agent to a lab", "added agent %[2]d to lab %[1]d", (*managementClient).AddAgentToLab),
+		a.newLinkCommand("remove-agent LAB_ID AGENT_ID", "Remove an agent from a lab", "removed agent %[2]d from lab %[1]d", (*managementClient).RemoveAgentFromLab),
+	)
+	cmd.AddCommand(a.newTagCommands("lab", (*managementClient).AddTagToLab, (*managementClient).RemoveTagFromLab)...)
+	return cmd
+}
+
+func (a *app) newAgentCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "agent", Short: "Manage agents"}
+
+	var tagID int
+	create := &cobra.Command{
+		Use:   "create NAME DATA_DIR",
+		Short: "Create an agent",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			var agent snmpsimclient.Agent
+			var err error
+			if tagID != 0 {
+				agent, err = client.CreateAgentWithTag(args[0], args[1], tagID)
+			} else {
+				agent, err = client.CreateAgent(args[0], args[1])
+			}
+			if err != nil {
+				return err
+			}
+			return a.print(agent, func() table { return agentsTable(agent) })
+		}),
+	}
+	create.Flags().IntVar(&tagID, "tag", 0, "id of a tag for the new agent")
+
+	cmd.AddCommand(
+		a.newListCommand("agent", func(client *managementClient, filters map[string]string) (interface{}, table, error) {
+			agents, err := client.GetAgents(filters)
+			return agents, agentsTable(agents...), err
+		}),
+		a.newGetCommand("agent", func(client *managementClient, id int) (interface{}, table, error) {
+			agent, err := client.GetAgent(id)
+			return agent, agentsTable(agent), err
+		}),
+		create,
+		a.newUpdateCommand("agent", []string{"name", "data-dir"}, func(client *managementClient, id int, changed map[string]*string) (interface{}, table, error) {
+			agent, err := client.UpdateAgent(id, snmpsimclient.AgentUpdate{Name: changed["name"], DataDir: changed["data-dir"]})
+			return agent, agentsTable(agent), err
+		}),
+		a.newDeleteCommand("agent", (*managementClient).DeleteAgent),
+		a.newLinkCommand("add-engine AGENT_ID ENGINE_ID", "Add an engine to an agent", "added engine %[2]d to agent %[1]d", (*managementClient).AddEngineToAgent),
+		a.newLinkCommand("remove-engine AGENT_ID ENGINE_ID", "Remove an engine from an agent", "removed engine %[2]d from agent %[1]d", (*managementClient).RemoveEngineFromAgent),
+	)
+	cmd.AddCommand(a.newTagCommands("agent", (*managementClient).AddTagToAgent, (*managementClient).RemoveTagFromAgent)...)
+	return cmd
+}
+
+func (a *app) newEngineCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "engine", Short: "Manage engines"}
+
+	var tagID int
+	create := &cobra.Command{
+		Use:   "create NAME ENGINE_ID",
+		Short: "Create an engine",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			var engine snmpsimclient.Engine
+			var err error
+			if tagID != 0 {
+				engine, err = client.CreateEngineWithTag(args[0], args[1], tagID)
+			} else {
+				engine, err = client.CreateEngine(args[0], args[1])
+			}
+			if err != nil {
+				return err
+			}
+			return a.print(engine, func() table { return enginesTable(engine) })
+		}),
+	}
+	create.Flags().IntVar(&tagID, "tag", 0, "id of a tag for the new engine")
+
+	var format string
+	var enterprise uint32
+	generateID := &cobra.Command{
+		Use:   "generate-id [VALUE]",
+		Short: "Generate an engine id",
+		Long:  "Generate an RFC 3411 engine id from an ipv4, ipv6 or mac address, a text, hex encoded octets or random octets.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "random" && len(args) == 0 {
+				return errors.New("missing value for format " + format)
+			}
+			var id snmpsimclient.EngineID
+			var err error
+			switch format {
+			case "random":
+				id, err = snmpsimclient.RandomEngineID(enterprise)
+			case "ipv4":
+				id, err = snmpsimclient.NewIPv4EngineID(enterprise, net.ParseIP(args[0]))
+			case "ipv6":
+				id, err = snmpsimclient.NewIPv6EngineID(enterprise, net.ParseIP(args[0]))
+			case "mac":
+				var mac net.HardwareAddr
+				mac, err = net.ParseMAC(args[0])
+				if err == nil {
+					id, err = snmpsimclient.NewMACEngineID(enterprise, mac)
+				}
+			case "text":
+				id, err = snmpsimclient.NewTextEngineID(enterprise, args[0])
+			case "octets":
+				var octets []byte
+				octets, err = hex.DecodeString(args[0])
+				if err == nil {
+					id, err = snmpsimclient.NewOctetsEngineID(enterprise, octets)
+				}
+			default:
+				return errors.New("invalid format " + format)
+			}
+			if err != nil {
+				return err
+			}
+			return a.print(id.String(), func() table {
+				return table{header: []string{"ENGINE ID"}, rows: [][]string{{id.String()}}}
+			})
+		},
+	}
+	generateID.Flags().StringVar(&format, "format", "random", "format of the engine id: random, ipv4, ipv6, mac, text or octets")
+	generateID.Flags().Uint32Var(&enterprise, "enterprise", snmpsimclient.EnterpriseSNMPLabs, "private enterprise number of the engine id")
+
+	cmd.AddCommand(
+		a.newListCommand("engine", func(client *managementClient, filters map[string]string) (interface{}, table, error) {
+			engines, err := client.GetEngines(filters)
+			return engines, enginesTable(engines...), err
+		}),
+		a.newGetCommand("engine", func(client *managementClient, id int) (interface{}, table, error) {
+			engine, err := client.GetEngine(id)
+			return engine, enginesTable(engine), err
+		}),
+		create,
+		generateID,
+		a.newUpdateCommand("engine", []string{"name", "engine-id"}, func(client *managementClient, id int, changed map[string]*string) (interface{}, table, error) {
+			engine, err := client.UpdateEngine(id, snmpsimclient.EngineUpdate{Name: changed["name"], EngineID: changed["engine-id"]})
+			return engine, enginesTable(engine), err
+		}),
+		a.newDeleteCommand("engine", (*managementClient).DeleteEngine),
+		a.newLinkCommand("add-user ENGINE_ID USER_ID", "Add a user to an engine", "added user %[2]d to engine %[1]d", (*managementClient).AddUserToEngine),
+		a.newLinkCommand("remove-user ENGINE_ID USER_ID", "Remove a user from an engine", "removed user %[2]d from engine %[1]d", (*managementClient).RemoveUserFromEngine),
+		a.newLinkCommand("add-endpoint ENGINE_ID ENDPOINT_ID", "Add an endpoint to an engine", "added endpoint %[2]d to engine %[1]d", (*managementClient).AddEndpointToEngine),
+		a.newLinkCommand("remove-endpoint ENGINE_ID ENDPOINT_ID", "Remove an endpoint from an engine", "removed endpoint %[2]d from engine %[1]d", (*managementClient).RemoveEndpointFromEngine),
+	)
+	cmd.AddCommand(a.newTagCommands("engine", (*managementClient).AddTagToEngine, (*managementClient).RemoveTagFromEngine)...)
+	return cmd
+}
+
+func (a *app) newEndpointCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "endpoint", Short: "Manage endpoints"}
+
+	var tagID int
+	var protocol string
+	create := &cobra.Command{
+		Use:   "create NAME ADDRESS",
+		Short: "Create an endpoint",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			var endpoint snmpsimclient.Endpoint
+			var err error
+			if tagID != 0 {
+				endpoint, err = client.CreateEndpointWithTag(args[0], args[1], protocol, tagID)
+			} else {
+				endpoint, err = client.CreateEndpoint(args[0], args[1], protocol)
+			}
+			if err != nil {
+				return err
+			}
+			return a.print(endpoint, func() table { return endpointsTable(endpoint) })
+		}),
+	}
+	create.Flags().IntVar(&tagID, "tag", 0, "id of a tag for the new endpoint")
+	create.Flags().StringVar(&protocol, "protocol", "udpv4", "transport protocol, udpv4 or udpv6")
+
+	var autoProtocol string
+	var minPort, maxPort int
+	createAuto := &cobra.Command{
+		Use:   "create-auto NAME IP",
+		Short: "Create an endpoint on a free port",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			allocator, err := snmpsimclient.NewPortAllocator(minPort, maxPort)
+			if err != nil {
+				return err
+			}
+			if err := client.SetPortAllocator(allocator); err != nil {
+				return err
+			}
+			endpoint, err := client.CreateEndpointAuto(args[0], args[1], autoProtocol)
+			if err != nil {
+				return err
+			}
+			return a.print(endpoint, func() table { return endpointsTable(endpoint) })
+		}),
+	}
+	createAuto.Flags().StringVar(&autoProtocol, "protocol", "udpv4", "transport protocol, udpv4 or udpv6")
+	createAuto.Flags().IntVar(&minPort, "min-port", 20000, "lowest port which may be allocated")
+	createAuto.Flags().IntVar(&maxPort, "max-port", 29999, "highest port which may be allocated")
+
+	cmd.AddCommand(
+		a.newListCommand("endpoint", func(client *managementClient, filters map[string]string) (interface{}, table, error) {
+			endpoints, err := client.GetEndpoints(filters)
+			return endpoints, endpointsTable(endpoints...), err
+		}),
+		a.newGetCommand("endpoint", func(client *managementClient, id int) (interface{}, table, error) {
+			endpoint, err := client.GetEndpoint(id)
+			return endpoint, endpointsTable(endpoint), err
+		}),
+		create,
+		createAuto,
+		a.newUpdateCommand("endpoint", []string{"name", "address", "protocol"}, func(client *managementClient, id int, changed map[string]*string) (interface{}, table, error) {
+			endpoint, err := client.UpdateEndpoint(id, snmpsimclient.EndpointUpdate{Name: changed["name"], Address: changed["address"], Protocol: changed["protocol"]})
+			return endpoint, endpointsTable(endpoint), err
+		}),
+		a.newDeleteCommand("endpoint", (*managementClient).DeleteEndpoint),
+	)
+	cmd.AddCommand(a.newTagCommands("endpoint", (*managementClient).AddTagToEndpoint, (*managementClient).RemoveTagFromEndpoint)...)
+	return cmd
+}
+
+func (a *app) newUserCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "user", Short: "Manage users"}
+
+	var tagID int
+	var authKey, authProto, privKey, privProto string
+	create := &cobra.Command{
+		Use:   "create USER NAME",
+		Short: "Create a user",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			var user snmpsimclient.User
+			var err error
+			if tagID != 0 {
+				user, err = client.CreateUserWithTag(args[0], args[1], authKey, authProto, privKey, privProto, tagID)
+			} else {
+				user, err = client.CreateUser(args[0], args[1], authKey, authProto, privKey, privProto)
+			}
+			if err != nil {
+				return err
+			}
+			return a.print(user, func() table { return usersTable(user) })
+		}),
+	}
+	create.Flags().IntVar(&tagID, "tag", 0, "id of a tag for the new user")
+	create.Flags().StringVar(&authKey, "auth-key", "", "authentication key")
+	create.Flags().StringVar(&authProto, "auth-proto", "", "authentication protocol: none, md5, sha, sha224, sha256, sha384 or sha512")
+	create.Flags().StringVar(&privKey, "priv-key", "", "privacy key")
+	create.Flags().StringVar(&privProto, "priv-proto", "", "privacy protocol: none, des, 3des, aes, aes128, aes192 or aes256")
+
+	cmd.AddCommand(
+		a.newListCommand("user", func(client *managementClient, filters map[string]string) (interface{}, table, error) {
+			users, err := client.GetUsers(filters)
+			return users, usersTable(users...), err
+		}),
+		a.newGetCommand("user", func(client *managementClient, id int) (interface{}, table, error) {
+			user, err := client.GetUser(id)
+			return user, usersTable(user), err
+		}),
+		create,
+		a.newUpdateCommand("user", []string{"user", "name", "auth-key", "auth-proto", "priv-key", "priv-proto"}, func(client *managementClient, id int, changed map[string]*string) (interface{}, table, error) {
+			user, err := client.UpdateUser(id, snmpsimclient.UserUpdate{User: changed["user"], Name: changed["name"], AuthKey: changed["auth-key"],
+				AuthProto: changed["auth-proto"], PrivKey: changed["priv-key"], PrivProto: changed["priv-proto"]})
+			return user, usersTable(user), err
+		}),
+		a.newDeleteCommand("user", (*managementClient).DeleteUser),
+	)
+	cmd.AddCommand(a.newTagCommands("user", (*managementClient).AddTagToUser, (*managementClient).RemoveTagFromUser)...)
+	return cmd
+}
+
+func (a *app) newTagCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "tag", Short: "Manage tags"}
+
+	var description string
+	create := &cobra.Command{
+		Use:   "create NAME",
+		Short: "Create a tag",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			tag, err := client.CreateTag(args[0], description)
+			if err != nil {
+				return err
+			}
+			return a.print(tag, func() table { return tagsTable(tag) })
+		}),
+	}
+	create.Flags().StringVar(&description, "description", "", "description of the tag")
+
+	purge := &cobra.Command{
+		Use:   "purge ID",
+		Short: "Delete all objects with a tag",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			tag, err := client.DeleteAllObjectsWithTag(ids[0])
+			if err != nil {
+				return err
+			}
+			return a.print(tag, func() table { return tagsTable(tag) })
+		}),
+	}
+
+	cmd.AddCommand(
+		a.newListCommand("tag", func(client *managementClient, filters map[string]string) (interface{}, table, error) {
+			tags, err := client.GetTags(filters)
+			return tags, tagsTable(tags...), err
+		}),
+		a.newGetCommand("tag", func(client *managementClient, id int) (interface{}, table, error) {
+			tag, err := client.GetTag(id)
+			return tag, tagsTable(tag), err
+		}),
+		create,
+		a.newUpdateCommand("tag", []string{"name", "description"}, func(client *managementClient, id int, changed map[string]*string) (interface{}, table, error) {
+			tag, err := client.UpdateTag(id, snmpsimclient.TagUpdate{Name: changed["name"], Description: changed["description"]})
+			return tag, tagsTable(tag), err
+		}),
+		a.newDeleteCommand("tag", (*managementClient).DeleteTag),
+		purge,
+	)
+	return cmd
+}
+
+func (a *app) newRecordingCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "recording", Short: "Manage record files"}
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List all record files",
+		Args:  cobra.NoArgs,
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			recordings, err := client.GetRecordFiles()
+			if err != nil {
+				return err
+			}
+			return a.print(recordings, func() table { return recordingsTable(recordings) })
+		}),
+	}
+
+	get := &cobra.Command{
+		Use:   "get REMOTE_PATH",
+		Short: "Print the contents of a record file",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			contents, err := client.GetRecordFile(args[0])
+			if err != nil {
+				return err
+			}
+			_, err = a.out.Write([]byte(contents))
+			return err
+		}),
+	}
+
+	var validate, snmpwalk bool
+	upload := &cobra.Command{
+		Use:   "upload LOCAL_PATH REMOTE_PATH",
+		Short: "Upload a record file",
+		Args:  cobra.ExactArgs(2),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			if err := client.SetRecordFileValidation(validate); err != nil {
+				return err
+			}
+			if snmpwalk {
+				output, err := ioutil.ReadFile(args[0])
+				if err != nil {
+					return errors.Wrap(err, "failed to read snmpwalk output")
+				}
+				if err := client.UploadSnmpwalk(string(output), args[1]); err != nil {
+					return err
+				}
+			} else if err := client.UploadRecordFile(args[0], args[1]); err != nil {
+				return err
+			}
+			a.done("uploaded %s", args[1])
+			return nil
+		}),
+	}
+	upload.Flags().BoolVar(&validate, "validate", false, "validate the record file before uploading it")
+	upload.Flags().BoolVar(&snmpwalk, "snmpwalk", false, "convert the output of snmpwalk -On into a record file")
+
+	del := &cobra.Command{
+		Use:   "delete REMOTE_PATH",
+		Short: "Delete a record file",
+		Args:  cobra.ExactArgs(1),
+		RunE: a.managementRunE(func(client *managementClient, args []string) error {
+			if err := client.DeleteRecordFile(args[0]); err != nil {
+				return err
+			}
+			a.done("deleted %s", args[0])
+			return nil
+		}),
+	}
+
+	cmd.AddCommand(list, get, upload, del)
+	return cmd
+}
+
+func (a *app) newSpecCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "spec", Short: "Manage labs with lab spec files"}
+
+	newCommand := func(use, short string, run func(client *managementClient, spec snmpsimclient.LabSpec, path string) error) *cobra.Command {
+		return &cobra.Command{
+			Use:   use + " FILE",
+			Short: short,
+			Args:  cobra.ExactArgs(1),
+			RunE: a.managementRunE(func(client *managementClient, args []string) error {
+				spec, err := snmpsimclient.LoadLabSpec(args[0])
+				if err != nil {
+					return err
+				}
+				return run(client, spec, args[0])
+			}),
+		}
+	}
+
+	cmd.AddCommand(
+		newCommand("plan", "Show the changes which are necessary to apply a lab spec", func(client *managementClient, spec snmpsimclient.LabSpec, path string) error {
+			plan, err := client.Plan(spec)
+			if err != nil {
+				return err
+			}
+			return a.print(plan, func() table { return planTable(plan) })
+		}),
+		newCommand("apply", "Apply a lab spec", func(client *managementClient, spec snmpsimclient.LabSpec, path string) error {
+			plan, err := client.Apply(spec)
+			if err != nil {
+				return err
+			}
+			return a.print(plan, func() table { return planTable(plan) })
+		}),
+		newCommand("destroy", "Delete all objects of a lab spec", func(client *managementClient, spec snmpsimclient.LabSpec, path string) error {
+			if err := client.Destroy(spec); err != nil {
+				return err
+			}
+			a.done("destroyed %s", path)
+			return nil
+		}),
+	)
+	return cmd
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type metricsClient = snmpsimclient.MetricsClient
+
+func (a *app) newMetricsCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "metrics", Short: "Query the metrics api"}
+
+	var packetFilterFlags []string
+	packets := &cobra.Command{
+		Use:   "packets",
+		Short: "Show packet metrics",
+		Args:  cobra.NoArgs,
+		RunE: a.metricsRunE(func(client *metricsClient, args []string) error {
+			filters, err := parseFilters(packetFilterFlags)
+			if err != nil {
+				return err
+			}
+			packets, err := client.GetPackets(filters)
+			if err != nil {
+				return err
+			}
+			return a.print(packets, func() table { return packetsTable(packets) })
+		}),
+	}
+	packets.Flags().StringArrayVar(&packetFilterFlags, "filter", nil, "filter as key=value, can be repeated")
+
+	var messageFilterFlags []string
+	messages := &cobra.Command{
+		Use:   "messages",
+		Short: "Show message metrics",
+		Args:  cobra.NoArgs,
+		RunE: a.metricsRunE(func(client *metricsClient, args []string) error {
+			filters, err := parseFilters(messageFilterFlags)
+			if err != nil {
+				return err
+			}
+			messages, err := client.GetMessages(filters)
+			if err != nil {
+				return err
+			}
+			return a.print(messages, func() table { return messagesTable(messages) })
+		}),
+	}
+	messages.Flags().StringArrayVar(&messageFilterFlags, "filter", nil, "filter as key=value, can be repeated")
+
+	var processFilterFlags []string
+	processes := &cobra.Command{
+		Use:   "processes",
+		Short: "List all processes",
+		Args:  cobra.NoArgs,
+		RunE: a.metricsRunE(func(client *metricsClient, args []string) error {
+			filters, err := parseFilters(processFilterFlags)
+			if err != nil {
+				return err
+			}
+			processes, err := client.GetProcesses(filters)
+			if err != nil {
+				return err
+			}
+			return a.print(processes, func() table { return processesTable(processes...) })
+		}),
+	}
+	processes.Flags().StringArrayVar(&processFilterFlags, "filter", nil, "filter as key=value, can be repeated")
+
+	cmd.AddCommand(
+		packets,
+		a.newValuesCommand("packet-filters", "List the packet filters", "FILTER", 0, func(client *metricsClient, args []string) ([]string, error) {
+			return client.GetPacketFilters()
+		}),
+		a.newValuesCommand("packet-filter-values FILTER", "List the possible values of a packet filter", "VALUE", 1, func(client *metricsClient, args []string) ([]string, error) {
+			return client.GetPossibleValuesForPacketFilter(args[0])
+		}),
+		messages,
+		a.newValuesCommand("message-filters", "List the message filters", "FILTER", 0, func(client *metricsClient, args []string) ([]string, error) {
+			return client.GetMessageFilters()
+		}),
+		a.newValuesCommand("message-filter-values FILTER", "List the possible values of a message filter", "VALUE", 1, func(client *metricsClient, args []string) ([]string, error) {
+			return client.GetPossibleValuesForMessageFilter(args[0])
+		}),
+		processes,
+		a.newProcessCommand("process PROCESS_ID", "Show a process", func(client *metricsClient, ids []int) (interface{}, table, error) {
+			process, err := client.GetProcess(ids[0])
+			return process, processesTable(process), err
+		}),
+		a.newProcessCommand("endpoints PROCESS_ID", "List the endpoints of a process", func(client *metricsClient, ids []int) (interface{}, table, error) {
+			endpoints, err := client.GetProcessEndpoints(ids[0])
+			return endpoints, processEndpointsTable(endpoints...), err
+		}),
+		a.newProcessCommand("endpoint PROCESS_ID ENDPOINT_ID", "Show an endpoint of a process", func(client *metricsClient, ids []int) (interface{}, table, error) {
+			endpoint, err := client.GetProcessEndpoint(ids[0], ids[1])
+			return endpoint, processEndpointsTable(endpoint), err
+		}),
+		a.newProcessCommand("console PROCESS_ID", "List the console pages of a process", func(client *metricsClient, ids []int) (interface{}, table, error) {
+			consoles, err := client.GetProcessConsolePages(ids[0])
+			return consoles, consolesTable(consoles...), err
+		}),
+		a.newProcessCommand("console-page PROCESS_ID PAGE_ID", "Show a console page of a process", func(client *metricsClient, ids []int) (interface{}, table, error) {
+			console, err := client.GetProcessConsolePage(ids[0], ids[1])
+			return console, consolesTable(console), err
+		}),
+	)
+	return cmd
+}
+
+// newValuesCommand creates a command which prints a list of strings, e.g. filters.
+func (a *app) newValuesCommand(use, short, header string, args int, values func(client *metricsClient, args []string) ([]string, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(args),
+		RunE: a.metricsRunE(func(client *metricsClient, args []string) error {
+			result, err := values(client, args)
+			if err != nil {
+				return err
+			}
+			return a.print(result, func() table { return valuesTable(header, result) })
+		}),
+	}
+}
+
+// newProcessCommand creates a command whose arguments are ids, the number of ids is derived from use.
+func (a *app) newProcessCommand(use, short string, get func(client *metricsClient, ids []int) (interface{}, table, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(len(strings.Fields(use)) - 1),
+		RunE: a.metricsRunE(func(client *metricsClient, args []string) error {
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+			result, t, err := get(client, ids)
+			if err != nil {
+				return err
+			}
+			return a.print(result, func() table { return t })
+		}),
+	}
+}
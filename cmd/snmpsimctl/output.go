@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// table is the table output of a result.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(row ...string) {
+	t.rows = append(t.rows, row)
+}
+
+// print writes the result in the configured output format, toTable is used for the table output.
+func (a *app) print(result interface{}, toTable func() table) error {
+	switch a.output {
+	case "json":
+		b, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal result")
+		}
+		_, err = fmt.Fprintln(a.out, string(b))
+		return err
+	case "yaml":
+		// marshal to json first so that the yaml output uses the same keys as the api
+		b, err := json.Marshal(result)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal result")
+		}
+		var generic interface{}
+		if err := yaml.Unmarshal(b, &generic); err != nil {
+			return errors.Wrap(err, "failed to convert result")
+		}
+		b, err = yaml.Marshal(generic)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal result")
+		}
+		_, err = a.out.Write(b)
+		return err
+	}
+
+	t := toTable()
+	w := tabwriter.NewWriter(a.out, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func labsTable(labs ...snmpsimclient.Lab) table {
+	t := table{header: []string{"ID", "NAME", "POWER", "AGENTS", "TAGS"}}
+	for _, lab := range labs {
+		var agents []int
+		for _, agent := range lab.Agents {
+			agents = append(agents, agent.ID)
+		}
+		t.add(strconv.Itoa(lab.ID), lab.Name, lab.Power, joinIDs(agents), tagNames(lab.Tags))
+	}
+	return t
+}
+
+func agentsTable(agents ...snmpsimclient.Agent) table {
+	t := table{header: []string{"ID", "NAME", "DATA DIR", "ENGINES", "TAGS"}}
+	for _, agent := range agents {
+		var engines []int
+		for _, engine := range agent.Engines {
+			engines = append(engines, engine.ID)
+		}
+		t.add(strconv.Itoa(agent.ID), agent.Name, agent.DataDir, joinIDs(engines), tagNames(agent.Tags))
+	}
+	return t
+}
+
+func enginesTable(engines ...snmpsimclient.Engine) table {
+	t := table{header: []string{"ID", "NAME", "ENGINE ID", "ENDPOINTS", "USERS", "TAGS"}}
+	for _, engine := range engines {
+		var endpoints, users []int
+		for _, endpoint := range engine.Endpoints {
+			endpoints = append(endpoints, endpoint.ID)
+		}
+		for _, user := range engine.Users {
+			users = append(users, user.ID)
+		}
+		t.add(strconv.Itoa(engine.ID), engine.Name, engine.EngineID, joinIDs(endpoints), joinIDs(users), tagNames(engine.Tags))
+	}
+	return t
+}
+
+func endpointsTable(endpoints ...snmpsimclient.Endpoint) table {
+	t := table{header: []string{"ID", "NAME", "PROTOCOL", "ADDRESS", "TAGS"}}
+	for _, endpoint := range endpoints {
+		t.add(strconv.Itoa(endpoint.ID), endpoint.Name, endpoint.Protocol, endpoint.Address, tagNames(endpoint.Tags))
+	}
+	return t
+}
+
+func usersTable(users ...snmpsimclient.User) table {
+	t := table{header: []string{"ID", "NAME", "USER", "AUTH PROTO", "PRIV PROTO", "TAGS"}}
+	for _, user := range users {
+		t.add(strconv.Itoa(user.ID), user.Name, user.User, user.AuthProto, user.PrivProto, tagNames(user.Tags))
+	}
+	return t
+}
+
+func tagsTable(tags ...snmpsimclient.Tag) table {
+	t := table{header: []string{"ID", "NAME", "DESCRIPTION", "OBJECTS"}}
+	for _, tag := range tags {
+		objects := len(tag.Labs) + len(tag.Agents) + len(tag.Engines) + len(tag.Endpoints) + len(tag.Users) + len(tag.Selectors)
+		t.add(strconv.Itoa(tag.ID), tag.Name, tag.Description, strconv.Itoa(objects))
+	}
+	return t
+}
+
+func recordingsTable(recordings snmpsimclient.Recordings) table {
+	t := table{header: []string{"PATH"}}
+	for _, recording := range recordings {
+		t.add(recording.Path)
+	}
+	return t
+}
+
+func planTable(plan snmpsimclient.LabPlan) table {
+	t := table{header: []string{"ACTION"}}
+	for _, action := range plan {
+		t.add(action.String())
+	}
+	return t
+}
+
+func accessTable(access snmpsimclient.LabAccess) table {
+	t := table{header: []string{"ENDPOINT", "ADDRESS", "PROTOCOL", "VERSION", "COMMUNITY", "USER", "SECURITY LEVEL"}}
+	for _, target := range access.Targets {
+		if target.User != nil {
+			t.add(target.Endpoint, target.Address, target.Protocol, target.Version, target.ContextName, target.User.User, target.User.SecurityLevel())
+		} else {
+			t.add(target.Endpoint, target.Address, target.Protocol, target.Version, target.Community, "-", "-")
+		}
+	}
+	return t
+}
+
+func processesTable(processes ...snmpsimclient.ProcessMetrics) table {
+	t := table{header: []string{"ID", "PATH", "RUNTIME", "CPU", "MEMORY", "FILES", "EXITS", "CHANGES"}}
+	for _, p := range processes {
+		t.add(strconv.Itoa(p.ID), p.Path, strconv.Itoa(p.Runtime), strconv.Itoa(p.CPU), strconv.Itoa(p.Memory),
+			strconv.Itoa(p.Files), strconv.Itoa(p.Exits), strconv.Itoa(p.Changes))
+	}
+	return t
+}
+
+func processEndpointsTable(endpoints ...snmpsimclient.ProcessEndpoint) table {
+	t := table{header: []string{"ID", "PROTOCOL", "ADDRESS"}}
+	for _, endpoint := range endpoints {
+		t.add(strconv.Itoa(endpoint.ID), endpoint.Protocol, endpoint.Address)
+	}
+	return t
+}
+
+func consolesTable(consoles ...snmpsimclient.Console) table {
+	t := table{header: []string{"ID", "TIMESTAMP", "TEXT"}}
+	for _, console := range consoles {
+		t.add(strconv.Itoa(console.ID), console.Timestamp, strings.Replace(console.Text, "\n", " ", -1))
+	}
+	return t
+}
+
+func packetsTable(packets snmpsimclient.PacketMetrics) table {
+	t := table{header: []string{"METRIC", "VALUE"}}
+	t.add("total", formatInt64(packets.Total))
+	t.add("parse_failures", formatInt64(packets.ParseFailures))
+	t.add("auth_failures", formatInt64(packets.AuthFailures))
+	t.add("context_failures", formatInt64(packets.ContextFailures))
+	return t
+}
+
+func messagesTable(messages snmpsimclient.MessageMetrics) table {
+	t := table{header: []string{"METRIC", "VALUE"}}
+	t.add("pdus", formatInt64(messages.Pdus))
+	t.add("var_binds", formatInt64(messages.VarBinds))
+	t.add("failures", formatInt64(messages.Failures))
+	for _, variation := range messages.Variations {
+		if variation.Name == nil {
+			continue
+		}
+		t.add("variation "+*variation.Name+" total", formatInt64(variation.Total))
+		t.add("variation "+*variation.Name+" failures", formatInt64(variation.Failures))
+	}
+	return t
+}
+
+func valuesTable(header string, values []string) table {
+	t := table{header: []string{header}}
+	for _, value := range values {
+		t.add(value)
+	}
+	return t
+}
+
+func joinIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, ",")
+}
+
+func tagNames(tags snmpsimclient.Tags) string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func formatInt64(i *int64) string {
+	if i == nil {
+		return "-"
+	}
+	return strconv.FormatInt(*i, 10)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type httpConfig struct {
+	BaseURL            string `mapstructure:"baseUrl"`
+	AuthUsername       string `mapstructure:"authUsername"`
+	AuthPassword       string `mapstructure:"authPassword"`
+	AuthToken          string `mapstructure:"authToken"`
+	CACertFile         string `mapstructure:"caCertFile"`
+	ClientCertFile     string `mapstructure:"clientCertFile"`
+	ClientKeyFile      string `mapstructure:"clientKeyFile"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+	Proxy              string `mapstructure:"proxy"`
+}
+
+// authenticator returns the authenticator for the configured credentials, a token takes precedence over username and password.
+func (h httpConfig) authenticator() snmpsimclient.Authenticator {
+	if h.AuthToken != "" {
+		return snmpsimclient.BearerToken(h.AuthToken)
+	}
+	if h.AuthUsername != "" && h.AuthPassword != "" {
+		return snmpsimclient.BasicAuth(h.AuthUsername, h.AuthPassword)
+	}
+	return nil
+}
+
+// options returns the client options for the tls and proxy settings.
+func (h httpConfig) options() []snmpsimclient.ClientOption {
+	var opts []snmpsimclient.ClientOption
+	if h.InsecureSkipVerify {
+		opts = append(opts, snmpsimclient.WithTLSConfig(&tls.Config{InsecureSkipVerify: true}))
+	}
+	if h.CACertFile != "" {
+		opts = append(opts, snmpsimclient.WithCACertFile(h.CACertFile))
+	}
+	if h.ClientCertFile != "" || h.ClientKeyFile != "" {
+		opts = append(opts, snmpsimclient.WithClientCert(h.ClientCertFile, h.ClientKeyFile))
+	}
+	if h.Proxy != "" {
+		opts = append(opts, snmpsimclient.WithProxy(h.Proxy))
+	}
+	return opts
+}
+
+type config struct {
+	Management httpConfig    `mapstructure:"management"`
+	Metrics    httpConfig    `mapstructure:"metrics"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+// app contains the state shared by all commands.
+type app struct {
+	out        io.Writer
+	configFile string
+	output     string
+	debug      bool
+	viper      *viper.Viper
+	config     config
+}
+
+func newRootCommand(out io.Writer) *cobra.Command {
+	a := &app{out: out, viper: viper.New()}
+
+	cmd := &cobra.Command{
+		Use:          "snmpsimctl",
+		Short:        "snmpsimctl controls a snmpsim control plane",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.loadConfig()
+		},
+	}
+	cmd.SetOut(out)
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&a.configFile, "config", "", "config file (default is ./snmpsimctl.yaml or $HOME/.snmpsimctl/snmpsimctl.yaml)")
+	flags.StringVarP(&a.output, "output", "o", "table", "output format: table, json or yaml")
+	flags.String("management-url", "", "base url of the management api")
+	flags.String("metrics-url", "", "base url of the metrics api")
+	flags.Duration("timeout", 0, "timeout for each request")
+	flags.BoolVar(&a.debug, "debug", false, "log every request with redacted headers and bodies to stderr")
+	_ = a.viper.BindPFlag("management.baseUrl", flags.Lookup("management-url"))
+	_ = a.viper.BindPFlag("metrics.baseUrl", flags.Lookup("metrics-url"))
+	_ = a.viper.BindPFlag("timeout", flags.Lookup("timeout"))
+
+	cmd.AddCommand(
+		a.newLabCommand(),
+		a.newAgentCommand(),
+		a.newEngineCommand(),
+		a.newEndpointCommand(),
+		a.newUserCommand(),
+		a.newTagCommand(),
+		a.newRecordingCommand(),
+		a.newSpecCommand(),
+		a.newMetricsCommand(),
+	)
+	return cmd
+}
+
+// loadConfig reads the config file and the environment the same way the tests of the client do.
+func (a *app) loadConfig() error {
+	switch a.output {
+	case "table", "json", "yaml":
+	default:
+		return errors.New("invalid output format " + a.output)
+	}
+
+	for _, api := range []string{"management", "metrics"} {
+		for _, key := range []string{"baseUrl", "authUsername", "authPassword", "authToken", "caCertFile", "clientCertFile", "clientKeyFile", "proxy"} {
+			a.viper.SetDefault(api+"."+key, "")
+		}
+		a.viper.SetDefault(api+".insecureSkipVerify", false)
+	}
+	a.viper.SetEnvPrefix("snmpsimctl")
+	a.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	a.viper.AutomaticEnv()
+
+	if a.configFile != "" {
+		a.viper.SetConfigFile(a.configFile)
+	} else {
+		a.viper.SetConfigName("snmpsimctl")
+		a.viper.AddConfigPath(".")
+		a.viper.AddConfigPath("$HOME/.snmpsimctl")
+	}
+	if err := a.viper.ReadInConfig(); err != nil {
+		if _, notFound := err.(viper.ConfigFileNotFoundError); !notFound || a.configFile != "" {
+			return errors.Wrap(err, "failed to read config file")
+		}
+	}
+	if err := a.viper.Unmarshal(&a.config); err != nil {
+		return errors.Wrap(err, "failed to unmarshal config")
+	}
+	return nil
+}
+
+func (a *app) managementClient() (*snmpsimclient.ManagementClient, error) {
+	if a.config.Management.BaseURL == "" {
+		return nil, errors.New("no base url for the management api configured")
+	}
+	client, err := snmpsimclient.NewManagementClient(a.config.Management.BaseURL, a.config.Management.options()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create management client")
+	}
+	if err := client.SetAuthenticator(a.config.Management.authenticator()); err != nil {
+		return nil, errors.Wrap(err, "failed to set authenticator")
+	}
+	if a.debug {
+		_ = client.SetLogger(snmpsimclient.NewWriterLogger(os.Stderr))
+		_ = client.SetBodyLogging(true)
+	}
+	if a.config.Timeout > 0 {
+		client.SetTimeout(a.config.Timeout)
+	}
+	return client, nil
+}
+
+func (a *app) metricsClient() (*snmpsimclient.MetricsClient, error) {
+	if a.config.Metrics.BaseURL == "" {
+		return nil, errors.New("no base url for the metrics api configured")
+	}
+	client, err := snmpsimclient.NewMetricsClient(a.config.Metrics.BaseURL, a.config.Metrics.options()...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create metrics client")
+	}
+	if err := client.SetAuthenticator(a.config.Metrics.authenticator()); err != nil {
+		return nil, errors.Wrap(err, "failed to set authenticator")
+	}
+	if a.debug {
+		_ = client.SetLogger(snmpsimclient.NewWriterLogger(os.Stderr))
+		_ = client.SetBodyLogging(true)
+	}
+	if a.config.Timeout > 0 {
+		client.SetTimeout(a.config.Timeout)
+	}
+	return client, nil
+}
+
+// done reports the success of a command without result, it is only printed in table output.
+func (a *app) done(format string, args ...interface{}) {
+	if a.output == "table" {
+		_, _ = fmt.Fprintf(a.out, format+"\n", args...)
+	}
+}
+
+// managementRunE wraps the run func of a command which needs a management client.
+func (a *app) managementRunE(run func(client *snmpsimclient.ManagementClient, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		client, err := a.managementClient()
+		if err != nil {
+			return err
+		}
+		return run(client, args)
+	}
+}
+
+// metricsRunE wraps the run func of a command which needs a metrics client.
+func (a *app) metricsRunE(run func(client *snmpsimclient.MetricsClient, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		client, err := a.metricsClient()
+		if err != nil {
+			return err
+		}
+		return run(client, args)
+	}
+}
+
+func parseIDs(args []string) ([]int, error) {
+	ids := make([]int, len(args))
+	for i, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, errors.New("invalid id " + arg)
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func parseFilters(filters []string) (map[string]string, error) {
+	return parseKeyValues("filter", filters)
+}
+
+// parseKeyValues parses a list of key=value pairs, kind is used in error messages.
+func parseKeyValues(kind string, pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]string)
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i < 1 {
+			return nil, errors.New("invalid " + kind + " " + pair + ", expected key=value")
+		}
+		result[pair[:i]] = pair[i+1:]
+	}
+	return result, nil
+}
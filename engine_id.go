@@ -0,0 +1,234 @@
+package snmpsimclient
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnterpriseSNMPLabs is the private enterprise number of SNMP Laboratories, the authors of snmpsim.
+	EnterpriseSNMPLabs uint32 = 20408
+
+	// minEngineIDLength and maxEngineIDLength are the limits for the length of an snmpEngineID in octets, see RFC 3411.
+	minEngineIDLength = 5
+	maxEngineIDLength = 32
+	// maxEngineIDDataLength is the maximum length of text and octets engine ids after the format octet.
+	maxEngineIDDataLength = 27
+	// legacyEngineIDDataLength is the length of engine ids in the SNMPv1 format after the enterprise number.
+	legacyEngineIDDataLength = 8
+	// randomEngineIDLength is the number of random octets of engine ids created by RandomEngineID.
+	randomEngineIDLength = 8
+)
+
+/*
+EngineIDFormat is the format of the data of an snmpEngineID as defined in RFC 3411.
+*/
+type EngineIDFormat byte
+
+const (
+	// EngineIDFormatLegacy is the format of engine ids in the SNMPv1 format, see EngineID.Legacy. It is reserved for all other engine ids.
+	EngineIDFormatLegacy EngineIDFormat = 0
+	// EngineIDFormatIPv4 is followed by an ipv4 address.
+	EngineIDFormatIPv4 EngineIDFormat = 1
+	// EngineIDFormatIPv6 is followed by an ipv6 address.
+	EngineIDFormatIPv6 EngineIDFormat = 2
+	// EngineIDFormatMAC is followed by a mac address.
+	EngineIDFormatMAC EngineIDFormat = 3
+	// EngineIDFormatText is followed by up to 27 octets of administratively assigned text.
+	EngineIDFormatText EngineIDFormat = 4
+	// EngineIDFormatOctets is followed by up to 27 octets of administratively assigned data.
+	EngineIDFormatOctets EngineIDFormat = 5
+)
+
+/*
+EngineID is a parsed snmpEngineID.
+*/
+type EngineID struct {
+	// Enterprise is the private enterprise number of the vendor of the engine.
+	Enterprise uint32
+	// Legacy is set for engine ids in the SNMPv1 format, whose first bit is not set. They have no format octet and 8 octets of Data.
+	Legacy bool
+	// Format is the format of Data, it is EngineIDFormatLegacy for legacy engine ids. Values from 128 on are enterprise specific.
+	Format EngineIDFormat
+	// Data contains the octets after the format octet, or after the enterprise number for the SNMPv1 format.
+	Data []byte
+}
+
+/*
+Bytes returns the octets of the engine id.
+*/
+func (e EngineID) Bytes() []byte {
+	b := make([]byte, 4, 5+len(e.Data))
+	if e.Legacy {
+		binary.BigEndian.PutUint32(b, e.Enterprise)
+	} else {
+		binary.BigEndian.PutUint32(b, e.Enterprise|0x80000000)
+		b = append(b, byte(e.Format))
+	}
+	return append(b, e.Data...)
+}
+
+/*
+String returns the engine id as hex string, as expected by CreateEngine.
+*/
+func (e EngineID) String() string {
+	return hex.EncodeToString(e.Bytes())
+}
+
+/*
+Validate checks that the engine id complies with RFC 3411.
+*/
+func (e EngineID) Validate() error {
+	if e.Enterprise&0x80000000 != 0 {
+		return errors.New("invalid enterprise number " + strconv.FormatUint(uint64(e.Enterprise), 10))
+	}
+	b := e.Bytes()
+	if len(b) < minEngineIDLength || len(b) > maxEngineIDLength {
+		return errors.New("invalid engine id length of " + strconv.Itoa(len(b)) + " octets, must be between 5 and 32")
+	}
+	if bytes.Equal(b, bytes.Repeat([]byte{0x00}, len(b))) || bytes.Equal(b, bytes.Repeat([]byte{0xff}, len(b))) {
+		return errors.New("engine id must not consist of zeros or 'ff'H only")
+	}
+
+	invalidLength := func(format string) error {
+		return errors.New("invalid length of " + strconv.Itoa(len(e.Data)) + " octets for the " + format + " of the engine id")
+	}
+	switch {
+	case e.Legacy:
+		if e.Format != EngineIDFormatLegacy {
+			return errors.New("engine id in the SNMPv1 format must not have a format")
+		}
+		if len(e.Data) != legacyEngineIDDataLength {
+			return errors.New("invalid engine id length of " + strconv.Itoa(len(e.Bytes())) + " octets, must be 12 for the SNMPv1 format")
+		}
+	case e.Format == EngineIDFormatIPv4:
+		if len(e.Data) != net.IPv4len {
+			return invalidLength("ipv4 address")
+		}
+	case e.Format == EngineIDFormatIPv6:
+		if len(e.Data) != net.IPv6len {
+			return invalidLength("ipv6 address")
+		}
+	case e.Format == EngineIDFormatMAC:
+		if len(e.Data) != 6 {
+			return invalidLength("mac address")
+		}
+	case e.Format == EngineIDFormatText, e.Format == EngineIDFormatOctets:
+		if len(e.Data) == 0 || len(e.Data) > maxEngineIDDataLength {
+			return invalidLength("data")
+		}
+	case e.Format < 128:
+		return errors.New("reserved engine id format " + strconv.Itoa(int(e.Format)))
+	}
+	return nil
+}
+
+/*
+ParseEngineID parses an engine id given as hex string, optionally prefixed with 0x, and validates it.
+Engine ids in the SNMPv1 format, whose first bit is not set, are only checked for their length of 12 octets.
+*/
+func ParseEngineID(engineID string) (EngineID, error) {
+	s := strings.TrimPrefix(strings.TrimPrefix(engineID, "0x"), "0X")
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return EngineID{}, errors.New("engine id " + engineID + " is not a hex string")
+	}
+	if len(b) < minEngineIDLength || len(b) > maxEngineIDLength {
+		return EngineID{}, errors.New("invalid engine id length of " + strconv.Itoa(len(b)) + " octets, must be between 5 and 32")
+	}
+
+	enterprise := binary.BigEndian.Uint32(b)
+	id := EngineID{Enterprise: enterprise &^ 0x80000000, Legacy: true, Data: b[4:]}
+	if enterprise&0x80000000 != 0 {
+		id.Legacy = false
+		id.Format = EngineIDFormat(b[4])
+		id.Data = b[5:]
+	}
+	err = id.Validate()
+	if err != nil {
+		return EngineID{}, errors.Wrap(err, "invalid engine id "+engineID)
+	}
+	return id, nil
+}
+
+/*
+ValidateEngineID checks an engine id given as hex string, see ParseEngineID.
+"auto" and an empty string are valid as they let the api generate the engine id.
+*/
+func ValidateEngineID(engineID string) error {
+	if engineID == "" || engineID == "auto" {
+		return nil
+	}
+	_, err := ParseEngineID(engineID)
+	return err
+}
+
+/*
+NewIPv4EngineID creates an engine id which contains an ipv4 address.
+*/
+func NewIPv4EngineID(enterprise uint32, ip net.IP) (EngineID, error) {
+	ipv4 := ip.To4()
+	if ipv4 == nil {
+		return EngineID{}, errors.New("invalid ipv4 address " + ip.String())
+	}
+	return newEngineID(enterprise, EngineIDFormatIPv4, ipv4)
+}
+
+/*
+NewIPv6EngineID creates an engine id which contains an ipv6 address.
+*/
+func NewIPv6EngineID(enterprise uint32, ip net.IP) (EngineID, error) {
+	ipv6 := ip.To16()
+	if ipv6 == nil || ip.To4() != nil {
+		return EngineID{}, errors.New("invalid ipv6 address " + ip.String())
+	}
+	return newEngineID(enterprise, EngineIDFormatIPv6, ipv6)
+}
+
+/*
+NewMACEngineID creates an engine id which contains a mac address.
+*/
+func NewMACEngineID(enterprise uint32, mac net.HardwareAddr) (EngineID, error) {
+	return newEngineID(enterprise, EngineIDFormatMAC, mac)
+}
+
+/*
+NewTextEngineID creates an engine id which contains up to 27 characters of text.
+*/
+func NewTextEngineID(enterprise uint32, text string) (EngineID, error) {
+	return newEngineID(enterprise, EngineIDFormatText, []byte(text))
+}
+
+/*
+NewOctetsEngineID creates an engine id which contains up to 27 arbitrary octets.
+*/
+func NewOctetsEngineID(enterprise uint32, octets []byte) (EngineID, error) {
+	return newEngineID(enterprise, EngineIDFormatOctets, octets)
+}
+
+/*
+RandomEngineID creates an engine id which contains 8 random octets.
+*/
+func RandomEngineID(enterprise uint32) (EngineID, error) {
+	octets := make([]byte, randomEngineIDLength)
+	_, err := rand.Read(octets)
+	if err != nil {
+		return EngineID{}, errors.Wrap(err, "error while generating random octets")
+	}
+	return newEngineID(enterprise, EngineIDFormatOctets, octets)
+}
+
+func newEngineID(enterprise uint32, format EngineIDFormat, data []byte) (EngineID, error) {
+	id := EngineID{Enterprise: enterprise, Format: format, Data: append([]byte{}, data...)}
+	err := id.Validate()
+	if err != nil {
+		return EngineID{}, err
+	}
+	return id, nil
+}
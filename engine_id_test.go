@@ -0,0 +1,137 @@
+package snmpsimclient
+
+import (
+	"encoding/hex"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestEngineID_Formats(t *testing.T) {
+	id, err := NewIPv4EngineID(EnterpriseSNMPLabs, net.ParseIP("192.168.0.1"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "80004fb801c0a80001", id.String())
+	}
+	id, err = NewIPv6EngineID(EnterpriseSNMPLabs, net.ParseIP("::1"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "80004fb80200000000000000000000000000000001", id.String())
+	}
+	mac, _ := net.ParseMAC("00:11:22:33:44:55")
+	id, err = NewMACEngineID(EnterpriseSNMPLabs, mac)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "80004fb803001122334455", id.String())
+	}
+	id, err = NewTextEngineID(EnterpriseSNMPLabs, "lab")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "80004fb8046c6162", id.String())
+	}
+	id, err = NewOctetsEngineID(8072, []byte{1, 2, 3})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "80001f8805010203", id.String())
+	}
+	id, err = RandomEngineID(EnterpriseSNMPLabs)
+	if assert.NoError(t, err) {
+		assert.Equal(t, EngineIDFormatOctets, id.Format)
+		assert.Len(t, id.Data, 8)
+		assert.True(t, strings.HasPrefix(id.String(), "80004fb805"))
+		assert.NoError(t, ValidateEngineID(id.String()))
+	}
+
+	_, err = NewIPv4EngineID(EnterpriseSNMPLabs, net.ParseIP("::1"))
+	assert.Error(t, err, "no error for ipv6 address in ipv4 engine id")
+	_, err = NewIPv6EngineID(EnterpriseSNMPLabs, net.ParseIP("127.0.0.1"))
+	assert.Error(t, err, "no error for ipv4 address in ipv6 engine id")
+	_, err = NewMACEngineID(EnterpriseSNMPLabs, net.HardwareAddr{1, 2})
+	assert.Error(t, err, "no error for invalid mac address")
+	_, err = NewTextEngineID(EnterpriseSNMPLabs, "")
+	assert.Error(t, err, "no error for empty text")
+	_, err = NewTextEngineID(EnterpriseSNMPLabs, strings.Repeat("a", 28))
+	assert.Error(t, err, "no error for too long text")
+	_, err = NewOctetsEngineID(0x80000000, []byte{1})
+	assert.Error(t, err, "no error for invalid enterprise number")
+}
+
+func TestParseEngineID(t *testing.T) {
+	id, err := ParseEngineID("0x80004FB801C0A80001")
+	if assert.NoError(t, err) {
+		assert.Equal(t, EngineID{Enterprise: EnterpriseSNMPLabs, Format: EngineIDFormatIPv4, Data: []byte{192, 168, 0, 1}}, id)
+	}
+	id, err = ParseEngineID("010203040507080900000000")
+	if assert.NoError(t, err) {
+		assert.True(t, id.Legacy)
+		assert.Equal(t, EngineIDFormatLegacy, id.Format)
+		assert.Equal(t, uint32(0x01020304), id.Enterprise)
+		assert.Equal(t, "010203040507080900000000", id.String())
+	}
+	id, err = ParseEngineID("80004fb8801234")
+	if assert.NoError(t, err, "error for enterprise specific format") {
+		assert.Equal(t, EngineIDFormat(0x80), id.Format)
+	}
+
+	for _, invalid := range []string{
+		"this is not a valid engine id",
+		"01020304",
+		strings.Repeat("01", 33),
+		"0000000000",
+		"ffffffffff",
+		"80004fb801c0a800",
+		"80004fb8030011223344",
+		"80004fb806010203",
+		"80004fb804",
+		"80004fb800c0a80001",
+		"0102030405070809",
+		"01020304050708090a0b0c0d0e",
+	} {
+		_, err = ParseEngineID(invalid)
+		assert.Error(t, err, "no error for invalid engine id "+invalid)
+	}
+	assert.NoError(t, ValidateEngineID("auto"))
+	assert.NoError(t, ValidateEngineID(""))
+}
+
+func TestParseEngineID_RoundTrip(t *testing.T) {
+	for _, engineID := range []string{
+		"80004fb801c0a80001",
+		"80004fb80200000000000000000000000000000001",
+		"80004fb803001122334455",
+		"80004fb8046c6162",
+		"80001f8805010203",
+		"80004fb8801234",
+		"010203040507080900000000",
+	} {
+		id, err := ParseEngineID(engineID)
+		if assert.NoError(t, err, "error for engine id "+engineID) {
+			assert.Equal(t, engineID, hex.EncodeToString(id.Bytes()), "engine id changed during round trip")
+		}
+	}
+	_, err := NewOctetsEngineID(EnterpriseSNMPLabs, nil)
+	assert.Error(t, err, "no error for empty octets")
+	assert.Error(t, EngineID{Enterprise: EnterpriseSNMPLabs, Legacy: true, Format: EngineIDFormatIPv4, Data: make([]byte, 8)}.Validate(), "no error for legacy engine id with format")
+}
+
+func TestManagementClient_CreateEngine_InvalidEngineID(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	_, err = client.CreateEngine("engine", "0102")
+	assert.Error(t, err, "no error for too short engine id")
+	engines, err := client.GetEngines(nil)
+	if assert.NoError(t, err, "error during GetEngines") {
+		assert.Empty(t, engines, "engine with invalid engine id was created")
+	}
+
+	engine, err := client.CreateEngine("engine", "")
+	if !assert.NoError(t, err, "error during CreateEngine") {
+		return
+	}
+	assert.NoError(t, ValidateEngineID(engine.EngineID), "generated engine id is invalid")
+	invalid := "80004fb806"
+	_, err = client.UpdateEngine(engine.ID, EngineUpdate{EngineID: &invalid})
+	assert.Error(t, err, "no error for update with invalid engine id")
+}
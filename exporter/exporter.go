@@ -0,0 +1,372 @@
+/*
+Package exporter exports the metrics of the snmpsim metrics api in the Prometheus text exposition format.
+
+The Exporter is an http.Handler which can be scraped by Prometheus directly:
+
+	client, err := snmpsimclient.NewMetricsClient("http://127.0.0.1:8001")
+	exp, err := exporter.New(client)
+	http.Handle("/metrics", exp)
+
+Each scrape queries the metrics api. Packet and message metrics are exported for every combination of the
+values of the label dimensions, which are the filters of the metrics api (e.g. local_address). Dimensions without
+values are left out, and filter names which are no valid Prometheus label names are sanitized.
+*/
+package exporter
+
+import (
+	"bufio"
+	"context"
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// contentType is the content type of the Prometheus text exposition format.
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+	// defaultMaxSeries is the default limit for the number of label combinations per scrape.
+	defaultMaxSeries = 1000
+	// defaultConcurrency is the default number of concurrent requests per scrape.
+	defaultConcurrency = 8
+	// bytesPerMB converts the memory usage reported by the api in MB to bytes.
+	bytesPerMB = 1024 * 1024
+)
+
+/*
+Exporter exports the packet, message and process metrics of a MetricsClient.
+*/
+type Exporter struct {
+	client *snmpsimclient.MetricsClient
+
+	// PacketLabels are the packet filters which are used as labels of the packet metrics.
+	// If nil, all filters returned by GetPacketFilters are used.
+	PacketLabels []string
+	// MessageLabels are the message filters which are used as labels of the message metrics.
+	// If nil, all filters returned by GetMessageFilters are used.
+	MessageLabels []string
+	// MaxSeries limits the number of label combinations which are queried per scrape, a scrape with more combinations fails.
+	MaxSeries int
+	// Concurrency is the number of label combinations which are queried at the same time.
+	Concurrency int
+}
+
+/*
+New creates a new exporter for the given metrics client.
+*/
+func New(client *snmpsimclient.MetricsClient) (*Exporter, error) {
+	if client == nil {
+		return nil, errors.New("invalid metrics client")
+	}
+	return &Exporter{client: client, MaxSeries: defaultMaxSeries, Concurrency: defaultConcurrency}, nil
+}
+
+/*
+ServeHTTP queries the metrics api and writes all metrics in the Prometheus text exposition format.
+*/
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	families, err := e.collect(r.Context())
+	if err != nil {
+		http.Error(w, "error while collecting snmpsim metrics: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_ = writeFamilies(w, families)
+}
+
+/*
+WriteMetrics queries the metrics api and writes all metrics in the Prometheus text exposition format to w.
+*/
+func (e *Exporter) WriteMetrics(ctx context.Context, w io.Writer) error {
+	families, err := e.collect(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error while collecting snmpsim metrics")
+	}
+	return writeFamilies(w, families)
+}
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     string
+	samples []sample
+}
+
+func (f *family) add(value float64, labels ...label) {
+	f.samples = append(f.samples, sample{labels: labels, value: value})
+}
+
+func (f *family) addInt64(value *int64, labels ...label) {
+	if value != nil {
+		f.add(float64(*value), labels...)
+	}
+}
+
+func (e *Exporter) collect(ctx context.Context) ([]*family, error) {
+	var families []*family
+
+	packetFamilies, err := e.collectPackets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	families = append(families, packetFamilies...)
+
+	messageFamilies, err := e.collectMessages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	families = append(families, messageFamilies...)
+
+	processFamilies, err := e.collectProcesses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	families = append(families, processFamilies...)
+
+	return families, nil
+}
+
+func (e *Exporter) collectPackets(ctx context.Context) ([]*family, error) {
+	dimensions := e.PacketLabels
+	if dimensions == nil {
+		filters, err := e.client.GetPacketFiltersCtx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while getting packet filters")
+		}
+		dimensions = filters
+	}
+	combinations, err := e.combinations(dimensions, func(dimension string) ([]string, error) {
+		return e.client.GetPossibleValuesForPacketFilterCtx(ctx, dimension)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error while getting packet filter values")
+	}
+
+	total := &family{name: "snmpsim_packets_total", help: "Number of received SNMP packets.", typ: "counter"}
+	parseFailures := &family{name: "snmpsim_packet_parse_failures_total", help: "Number of SNMP packets which could not be parsed.", typ: "counter"}
+	authFailures := &family{name: "snmpsim_packet_auth_failures_total", help: "Number of SNMP packets which failed authentication.", typ: "counter"}
+	contextFailures := &family{name: "snmpsim_packet_context_failures_total", help: "Number of SNMP packets with an unknown context.", typ: "counter"}
+
+	results := make([]snmpsimclient.PacketMetrics, len(combinations))
+	err = e.query(len(combinations), func(i int) error {
+		packets, err := e.client.GetPacketsCtx(ctx, combinations[i].filters)
+		results[i] = packets
+		return errors.Wrap(err, "error while getting packet metrics")
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, packets := range results {
+		labels := combinations[i].labels
+		total.addInt64(packets.Total, labels...)
+		parseFailures.addInt64(packets.ParseFailures, labels...)
+		authFailures.addInt64(packets.AuthFailures, labels...)
+		contextFailures.addInt64(packets.ContextFailures, labels...)
+	}
+	return []*family{total, parseFailures, authFailures, contextFailures}, nil
+}
+
+func (e *Exporter) collectMessages(ctx context.Context) ([]*family, error) {
+	dimensions := e.MessageLabels
+	if dimensions == nil {
+		filters, err := e.client.GetMessageFiltersCtx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while getting message filters")
+		}
+		dimensions = filters
+	}
+	combinations, err := e.combinations(dimensions, func(dimension string) ([]string, error) {
+		return e.client.GetPossibleValuesForMessageFilterCtx(ctx, dimension)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error while getting message filter values")
+	}
+
+	pdus := &family{name: "snmpsim_message_pdus_total", help: "Number of processed SNMP PDUs.", typ: "counter"}
+	varBinds := &family{name: "snmpsim_message_var_binds_total", help: "Number of processed SNMP variable bindings.", typ: "counter"}
+	failures := &family{name: "snmpsim_message_failures_total", help: "Number of SNMP messages which could not be processed.", typ: "counter"}
+	variationTotal := &family{name: "snmpsim_variation_calls_total", help: "Number of calls of a variation module.", typ: "counter"}
+	variationFailures := &family{name: "snmpsim_variation_failures_total", help: "Number of failed calls of a variation module.", typ: "counter"}
+
+	results := make([]snmpsimclient.MessageMetrics, len(combinations))
+	err = e.query(len(combinations), func(i int) error {
+		messages, err := e.client.GetMessagesCtx(ctx, combinations[i].filters)
+		results[i] = messages
+		return errors.Wrap(err, "error while getting message metrics")
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, messages := range results {
+		labels := combinations[i].labels
+		pdus.addInt64(messages.Pdus, labels...)
+		varBinds.addInt64(messages.VarBinds, labels...)
+		failures.addInt64(messages.Failures, labels...)
+		for _, variation := range messages.Variations {
+			if variation.Name == nil {
+				continue
+			}
+			variationLabels := append(append([]label{}, labels...), label{name: "variation", value: *variation.Name})
+			variationTotal.addInt64(variation.Total, variationLabels...)
+			variationFailures.addInt64(variation.Failures, variationLabels...)
+		}
+	}
+	return []*family{pdus, varBinds, failures, variationTotal, variationFailures}, nil
+}
+
+func (e *Exporter) collectProcesses(ctx context.Context) ([]*family, error) {
+	processes, err := e.client.GetProcessesCtx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while getting process metrics")
+	}
+
+	cpu := &family{name: "snmpsim_process_cpu", help: "CPU usage of the process.", typ: "gauge"}
+	memory := &family{name: "snmpsim_process_memory_bytes", help: "Memory usage of the process in bytes, the api reports it in MB.", typ: "gauge"}
+	files := &family{name: "snmpsim_process_files", help: "Number of files opened by the process.", typ: "gauge"}
+	exits := &family{name: "snmpsim_process_exits_total", help: "Number of exits of the process.", typ: "counter"}
+	changes := &family{name: "snmpsim_process_changes_total", help: "Number of changes of the process.", typ: "counter"}
+	runtime := &family{name: "snmpsim_process_runtime_seconds", help: "Runtime of the process in seconds.", typ: "gauge"}
+
+	for _, process := range processes {
+		labels := []label{
+			{name: "process_id", value: strconv.Itoa(process.ID)},
+			{name: "path", value: process.Path},
+		}
+		cpu.add(float64(process.CPU), labels...)
+		memory.add(float64(process.Memory)*bytesPerMB, labels...)
+		files.add(float64(process.Files), labels...)
+		exits.add(float64(process.Exits), labels...)
+		changes.add(float64(process.Changes), labels...)
+		runtime.add(float64(process.Runtime), labels...)
+	}
+	return []*family{cpu, memory, files, exits, changes, runtime}, nil
+}
+
+// combination is a combination of filter values and the labels of its series.
+type combination struct {
+	labels  []label
+	filters map[string]string
+}
+
+// combinations returns all combinations of the values of the given dimensions, dimensions without values are left out.
+func (e *Exporter) combinations(dimensions []string, values func(dimension string) ([]string, error)) ([]combination, error) {
+	combinations := []combination{{}}
+	for _, dimension := range dimensions {
+		dimensionValues, err := values(dimension)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while getting values of "+dimension)
+		}
+		if len(dimensionValues) == 0 {
+			continue
+		}
+		sort.Strings(dimensionValues)
+		var next []combination
+		for _, c := range combinations {
+			for _, value := range dimensionValues {
+				filters := map[string]string{dimension: value}
+				for name, v := range c.filters {
+					filters[name] = v
+				}
+				next = append(next, combination{
+					labels:  append(append([]label{}, c.labels...), label{name: sanitizeLabelName(dimension), value: value}),
+					filters: filters,
+				})
+			}
+		}
+		if e.MaxSeries > 0 && len(next) > e.MaxSeries {
+			return nil, errors.New("more than " + strconv.Itoa(e.MaxSeries) + " label combinations, restrict the labels of the exporter")
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+// query calls do for the indexes from 0 to n-1 with at most Concurrency calls at the same time and returns the first error.
+func (e *Exporter) query(n int, do func(i int) error) error {
+	concurrency := e.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	indexes := make(chan int)
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs <- do(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sanitizeLabelName replaces all characters which are not allowed in Prometheus label names with underscores.
+func sanitizeLabelName(name string) string {
+	sanitized := []rune(name)
+	for i, r := range sanitized {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			sanitized[i] = '_'
+		}
+	}
+	s := string(sanitized)
+	if s == "" {
+		return "_"
+	}
+	//label names starting with __ are reserved for internal use
+	for strings.HasPrefix(s, "__") {
+		s = s[1:]
+	}
+	return s
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func writeFamilies(w io.Writer, families []*family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if len(f.samples) == 0 {
+			continue
+		}
+		_, _ = bw.WriteString("# HELP " + f.name + " " + f.help + "\n")
+		_, _ = bw.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+		for _, s := range f.samples {
+			_, _ = bw.WriteString(f.name)
+			if len(s.labels) > 0 {
+				var pairs []string
+				for _, l := range s.labels {
+					pairs = append(pairs, l.name+`="`+labelValueEscaper.Replace(l.value)+`"`)
+				}
+				_, _ = bw.WriteString("{" + strings.Join(pairs, ",") + "}")
+			}
+			_, _ = bw.WriteString(" " + strconv.FormatFloat(s.value, 'g', -1, 64) + "\n")
+		}
+	}
+	return errors.Wrap(bw.Flush(), "error while writing metrics")
+}
@@ -0,0 +1,154 @@
+package exporter
+
+import (
+	"context"
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExporter_WriteMetrics(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	server.AddPacketActivity(snmpsimtest.PacketActivity{TransportProtocol: "udpv4", LocalAddress: "127.0.0.1:1161", PeerAddress: "127.0.0.1:50000", Total: 10, AuthFailures: 1})
+	server.AddPacketActivity(snmpsimtest.PacketActivity{TransportProtocol: "udpv4", LocalAddress: "127.0.0.1:1162", PeerAddress: "127.0.0.1:50000", Total: 5})
+	server.AddMessageActivity(snmpsimtest.MessageActivity{LocalAddress: "127.0.0.1:1161", PDUType: "GetRequestPDU", PDUs: 8, VarBinds: 16,
+		Variations: []snmpsimtest.VariationActivity{{Name: "numeric", Total: 4, Failures: 1}}})
+
+	client, err := snmpsimclient.NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	exp, err := New(client)
+	if !assert.NoError(t, err, "error while creating a new exporter") {
+		return
+	}
+	exp.PacketLabels = []string{"local_address"}
+	exp.MessageLabels = []string{"local_address", "pdu_type"}
+
+	var b strings.Builder
+	err = exp.WriteMetrics(context.Background(), &b)
+	if !assert.NoError(t, err, "error during WriteMetrics") {
+		return
+	}
+	metrics := b.String()
+	assert.Contains(t, metrics, "# TYPE snmpsim_packets_total counter\n")
+	assert.Contains(t, metrics, `snmpsim_packets_total{local_address="127.0.0.1:1161"} 10`+"\n")
+	assert.Contains(t, metrics, `snmpsim_packets_total{local_address="127.0.0.1:1162"} 5`+"\n")
+	assert.Contains(t, metrics, `snmpsim_packet_auth_failures_total{local_address="127.0.0.1:1161"} 1`+"\n")
+	assert.Contains(t, metrics, `snmpsim_message_pdus_total{local_address="127.0.0.1:1161",pdu_type="GetRequestPDU"} 8`+"\n")
+	assert.Contains(t, metrics, `snmpsim_message_var_binds_total{local_address="127.0.0.1:1161",pdu_type="GetRequestPDU"} 16`+"\n")
+	assert.Contains(t, metrics, `snmpsim_variation_calls_total{local_address="127.0.0.1:1161",pdu_type="GetRequestPDU",variation="numeric"} 4`+"\n")
+	assert.NotContains(t, metrics, "snmpsim_process_cpu", "process metrics without running process")
+
+	exp.PacketLabels = nil
+	exp.MaxSeries = 1
+	err = exp.WriteMetrics(context.Background(), &b)
+	assert.Error(t, err, "no error when exceeding the maximum number of series")
+}
+
+func TestExporter_WriteMetrics_Processes(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+	server.ProcessMemory = 42
+
+	management, err := snmpsimclient.NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	spec, err := snmpsimclient.ParseLabSpecYAML([]byte(`
+labs:
+  - name: lab1
+    power: true
+    agents: [agent1]
+agents:
+  - name: agent1
+    data_dir: agent1
+    engines: [engine1]
+engines:
+  - name: engine1
+    endpoints: [endpoint1]
+endpoints:
+  - name: endpoint1
+    address: 127.0.0.1:1161
+`))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	if _, err := management.Apply(spec); !assert.NoError(t, err, "error during Apply") {
+		return
+	}
+
+	client, err := snmpsimclient.NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	exp, err := New(client)
+	if !assert.NoError(t, err, "error while creating a new exporter") {
+		return
+	}
+	var b strings.Builder
+	if !assert.NoError(t, exp.WriteMetrics(context.Background(), &b), "error during WriteMetrics") {
+		return
+	}
+	assert.Contains(t, b.String(), `snmpsim_process_memory_bytes{process_id="1",path="/opt/snmpsim/supervised/snmpsim-run-labs.sh"} 4.4040192e+07`+"\n")
+}
+
+func TestExporter_ServeHTTP(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := snmpsimclient.NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	exp, err := New(client)
+	if !assert.NoError(t, err, "error while creating a new exporter") {
+		return
+	}
+
+	recorder := httptest.NewRecorder()
+	exp.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, contentType, recorder.Header().Get("Content-Type"))
+
+	server.AddFault(snmpsimtest.Fault{Path: "/snmpsim/metrics/v1/processes", StatusCode: 500})
+	recorder = httptest.NewRecorder()
+	exp.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 500, recorder.Code)
+	body, _ := ioutil.ReadAll(recorder.Body)
+	assert.Contains(t, string(body), "error while getting process metrics")
+}
+
+func TestExporter_combinations(t *testing.T) {
+	exp := &Exporter{MaxSeries: 10}
+	values := map[string][]string{"local_address": {"127.0.0.1:1162", "127.0.0.1:1161"}, "peer-address": {"10.0.0.1"}, "context": nil}
+	combinations, err := exp.combinations([]string{"local_address", "context", "peer-address"}, func(dimension string) ([]string, error) {
+		return values[dimension], nil
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, combinations, 2, "dimension without values removed all combinations") {
+		return
+	}
+	assert.Equal(t, []label{{name: "local_address", value: "127.0.0.1:1161"}, {name: "peer_address", value: "10.0.0.1"}}, combinations[0].labels)
+	assert.Equal(t, map[string]string{"local_address": "127.0.0.1:1161", "peer-address": "10.0.0.1"}, combinations[0].filters)
+
+	combinations, err = exp.combinations([]string{"context"}, func(dimension string) ([]string, error) {
+		return nil, nil
+	})
+	if assert.NoError(t, err) && assert.Len(t, combinations, 1) {
+		assert.Empty(t, combinations[0].labels)
+	}
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	assert.Equal(t, "local_address", sanitizeLabelName("local_address"))
+	assert.Equal(t, "pdu_type", sanitizeLabelName("pdu-type"))
+	assert.Equal(t, "_d_address", sanitizeLabelName("1d.address"))
+	assert.Equal(t, "_internal", sanitizeLabelName("__internal"))
+	assert.Equal(t, "_", sanitizeLabelName(""))
+}
@@ -0,0 +1,259 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+LabFilter filters the labs returned by GetLabs, empty fields are not used for filtering.
+
+	labs, err := client.GetLabs(snmpsimclient.LabFilter{Power: "on"}.Params())
+*/
+type LabFilter struct {
+	Name string
+	// Power is "on" or "off".
+	Power string
+	TagID int
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f LabFilter) Params() map[string]string {
+	return filterParams("name", f.Name, "power", f.Power, "tag", tagParam(f.TagID))
+}
+
+/*
+AgentFilter filters the agents returned by GetAgents, empty fields are not used for filtering.
+*/
+type AgentFilter struct {
+	Name    string
+	DataDir string
+	TagID   int
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f AgentFilter) Params() map[string]string {
+	return filterParams("name", f.Name, "data_dir", f.DataDir, "tag", tagParam(f.TagID))
+}
+
+/*
+EngineFilter filters the engines returned by GetEngines, empty fields are not used for filtering.
+*/
+type EngineFilter struct {
+	Name     string
+	EngineID string
+	TagID    int
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f EngineFilter) Params() map[string]string {
+	return filterParams("name", f.Name, "engine_id", f.EngineID, "tag", tagParam(f.TagID))
+}
+
+/*
+EndpointFilter filters the endpoints returned by GetEndpoints, empty fields are not used for filtering.
+*/
+type EndpointFilter struct {
+	Name     string
+	Address  string
+	Protocol string
+	TagID    int
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f EndpointFilter) Params() map[string]string {
+	return filterParams("name", f.Name, "address", f.Address, "protocol", f.Protocol, "tag", tagParam(f.TagID))
+}
+
+/*
+UserFilter filters the users returned by GetUsers, empty fields are not used for filtering.
+*/
+type UserFilter struct {
+	User      string
+	Name      string
+	AuthProto string
+	PrivProto string
+	TagID     int
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f UserFilter) Params() map[string]string {
+	return filterParams("user", f.User, "name", f.Name, "auth_proto", f.AuthProto, "priv_proto", f.PrivProto, "tag", tagParam(f.TagID))
+}
+
+/*
+TagFilter filters the tags returned by GetTags, empty fields are not used for filtering.
+*/
+type TagFilter struct {
+	Name        string
+	Description string
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f TagFilter) Params() map[string]string {
+	return filterParams("name", f.Name, "description", f.Description)
+}
+
+/*
+PacketFilter filters the packet metrics returned by GetPackets, empty fields are not used for filtering.
+*/
+type PacketFilter struct {
+	TransportProtocol string
+	LocalAddress      string
+	PeerAddress       string
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f PacketFilter) Params() map[string]string {
+	return filterParams("transport_protocol", f.TransportProtocol, "local_address", f.LocalAddress, "peer_address", f.PeerAddress)
+}
+
+/*
+MessageFilter filters the message metrics returned by GetMessages, empty fields are not used for filtering.
+*/
+type MessageFilter struct {
+	TransportProtocol string
+	LocalAddress      string
+	PeerAddress       string
+	EngineID          string
+	SecurityModel     string
+	SecurityLevel     string
+	ContextEngineID   string
+	ContextName       string
+	PDUType           string
+	Recording         string
+}
+
+/*
+Params returns the filter as query parameters.
+*/
+func (f MessageFilter) Params() map[string]string {
+	return filterParams("transport_protocol", f.TransportProtocol, "local_address", f.LocalAddress, "peer_address", f.PeerAddress,
+		"engine_id", f.EngineID, "security_model", f.SecurityModel, "security_level", f.SecurityLevel,
+		"context_engine_id", f.ContextEngineID, "context_name", f.ContextName, "pdu_type", f.PDUType, "recording", f.Recording)
+}
+
+// filterParams converts pairs of keys and values into query parameters, empty values are skipped and nil is returned if all values are empty.
+func filterParams(keysAndValues ...string) map[string]string {
+	var params map[string]string
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if keysAndValues[i+1] == "" {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[keysAndValues[i]] = keysAndValues[i+1]
+	}
+	return params
+}
+
+func tagParam(tagID int) string {
+	if tagID == 0 {
+		return ""
+	}
+	return strconv.Itoa(tagID)
+}
+
+/*
+InvalidFilterError is returned if a filter is not supported by the metrics api.
+*/
+type InvalidFilterError struct {
+	Filter       string
+	ValidFilters []string
+}
+
+func (i *InvalidFilterError) Error() string {
+	return "invalid filter " + i.Filter + ", valid filters are: " + strings.Join(i.ValidFilters, ", ")
+}
+
+/*
+SetFilterValidation enables or disables the validation of the filter keys passed to GetPackets and GetMessages.
+If enabled, the filters supported by the api are requested before each filtered request and an InvalidFilterError is returned for unknown keys.
+*/
+func (c *MetricsClient) SetFilterValidation(enabled bool) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	c.validateFilters = enabled
+	return nil
+}
+
+/*
+ValidatePacketFilters returns an InvalidFilterError if one of the filter keys is not supported by the packet metrics.
+*/
+func (c *MetricsClient) ValidatePacketFilters(filters map[string]string) error {
+	return c.ValidatePacketFiltersCtx(context.Background(), filters)
+}
+
+/*
+ValidatePacketFiltersCtx is like ValidatePacketFilters but uses the given context for the request.
+*/
+func (c *MetricsClient) ValidatePacketFiltersCtx(ctx context.Context, filters map[string]string) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	validFilters, err := c.GetPacketFiltersCtx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error while getting packet filters")
+	}
+	return validateFilterKeys(filters, validFilters)
+}
+
+/*
+ValidateMessageFilters returns an InvalidFilterError if one of the filter keys is not supported by the message metrics.
+*/
+func (c *MetricsClient) ValidateMessageFilters(filters map[string]string) error {
+	return c.ValidateMessageFiltersCtx(context.Background(), filters)
+}
+
+/*
+ValidateMessageFiltersCtx is like ValidateMessageFilters but uses the given context for the request.
+*/
+func (c *MetricsClient) ValidateMessageFiltersCtx(ctx context.Context, filters map[string]string) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	validFilters, err := c.GetMessageFiltersCtx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error while getting message filters")
+	}
+	return validateFilterKeys(filters, validFilters)
+}
+
+func validateFilterKeys(filters map[string]string, validFilters []string) error {
+	valid := make(map[string]bool)
+	for _, filter := range validFilters {
+		valid[filter] = true
+	}
+	var keys []string
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !valid[key] {
+			sorted := append([]string{}, validFilters...)
+			sort.Strings(sorted)
+			return &InvalidFilterError{Filter: key, ValidFilters: sorted}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package snmpsimclient
+
+import (
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFilter_Params(t *testing.T) {
+	assert.Nil(t, LabFilter{}.Params(), "empty filter has params")
+	assert.Equal(t, map[string]string{"name": "lab", "power": "on", "tag": "3"}, LabFilter{Name: "lab", Power: "on", TagID: 3}.Params())
+	assert.Equal(t, map[string]string{"address": "127.0.0.1:1161", "protocol": "udpv4"}, EndpointFilter{Address: "127.0.0.1:1161", Protocol: "udpv4"}.Params())
+	assert.Equal(t, map[string]string{"user": "simulator", "auth_proto": "md5"}, UserFilter{User: "simulator", AuthProto: "md5"}.Params())
+	assert.Equal(t, map[string]string{"local_address": "127.0.0.1:1161"}, PacketFilter{LocalAddress: "127.0.0.1:1161"}.Params())
+	assert.Equal(t, map[string]string{"pdu_type": "GetRequestPDU", "context_name": "public"}, MessageFilter{PDUType: "GetRequestPDU", ContextName: "public"}.Params())
+}
+
+func TestManagementClient_GetLabs_TagFilter(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	tag, err := client.CreateTag("filter", "")
+	if !assert.NoError(t, err, "error while creating tag") {
+		return
+	}
+	tagged, err := client.CreateLabWithTag("tagged", tag.ID)
+	if !assert.NoError(t, err, "error while creating lab") {
+		return
+	}
+	_, err = client.CreateLab("untagged")
+	if !assert.NoError(t, err, "error while creating lab") {
+		return
+	}
+
+	labs, err := client.GetLabs(LabFilter{TagID: tag.ID}.Params())
+	if assert.NoError(t, err, "error during GetLabs") && assert.Len(t, labs, 1) {
+		assert.Equal(t, tagged.ID, labs[0].ID)
+	}
+	labs, err = client.GetLabs(LabFilter{Name: "untagged", Power: "off"}.Params())
+	if assert.NoError(t, err, "error during GetLabs") && assert.Len(t, labs, 1) {
+		assert.Equal(t, "untagged", labs[0].Name)
+	}
+}
+
+func TestMetricsClient_FilterValidation(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	server.AddPacketActivity(snmpsimtest.PacketActivity{TransportProtocol: "udpv4", LocalAddress: "127.0.0.1:1161", PeerAddress: "127.0.0.1:50000", Total: 10})
+
+	client, err := NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	typo := map[string]string{"local_adress": "127.0.0.1:1161"}
+	_, err = client.GetPackets(typo)
+	assert.NoError(t, err, "error without filter validation")
+
+	err = client.SetFilterValidation(true)
+	if !assert.NoError(t, err, "error while enabling filter validation") {
+		return
+	}
+	_, err = client.GetPackets(typo)
+	var invalidFilter *InvalidFilterError
+	if assert.True(t, errors.As(err, &invalidFilter), "error is not an InvalidFilterError") {
+		assert.Equal(t, "local_adress", invalidFilter.Filter)
+		assert.Contains(t, invalidFilter.ValidFilters, "local_address")
+	}
+	_, err = client.GetMessages(typo)
+	assert.True(t, errors.As(err, &invalidFilter), "error is not an InvalidFilterError")
+
+	packets, err := client.GetPackets(PacketFilter{LocalAddress: "127.0.0.1:1161"}.Params())
+	if assert.NoError(t, err, "error during GetPackets") && assert.NotNil(t, packets.Total) {
+		assert.Equal(t, int64(10), *packets.Total)
+	}
+	assert.NoError(t, client.ValidateMessageFilters(MessageFilter{PDUType: "GetRequestPDU"}.Params()))
+}
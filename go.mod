@@ -4,8 +4,10 @@ go 1.13
 
 require (
 	github.com/go-resty/resty/v2 v2.1.0
-	github.com/pkg/errors v0.8.1
+	github.com/pkg/errors v0.9.1
 	github.com/soniah/gosnmp v1.22.0
+	github.com/spf13/cobra v1.0.0
 	github.com/spf13/viper v1.6.1
 	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.4
 )
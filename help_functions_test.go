@@ -2,6 +2,7 @@ package snmpsimclient
 
 import (
 	"fmt"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -25,6 +26,7 @@ type httpConfig struct {
 
 type configMetricsAPITest struct {
 	HTTP        httpConfig `mapstructure:"http"`
+	FakeServer  bool       `mapstructure:"fakeServer"`
 	Protocol    string     `mapstructure:"protocol"`
 	Agent1      agentData  `mapstructure:"agent1"`
 	RootDataDir string     `mapstructure:"rootDataDir"`
@@ -35,6 +37,7 @@ var configMetricsTest configMetricsAPITest
 
 type configManagementAPITest struct {
 	HTTP        httpConfig `mapstructure:"http"`
+	FakeServer  bool       `mapstructure:"fakeServer"`
 	Protocol    string     `mapstructure:"protocol"`
 	Agent1      agentData  `mapstructure:"agent1"`
 	Agent2      agentData  `mapstructure:"agent2"`
@@ -92,6 +95,21 @@ func init() {
 
 	configMetricsTest.TestDataDir = testDataDir
 
+	//fake control plane
+	if configManagementTest.FakeServer || configMetricsTest.FakeServer {
+		fakeServer := snmpsimtest.NewServer()
+		if configManagementTest.FakeServer {
+			configManagementTest.HTTP.BaseURL = fakeServer.URL
+			configManagementTest.HTTP.AuthUsername = ""
+			configManagementTest.HTTP.AuthPassword = ""
+		}
+		if configMetricsTest.FakeServer {
+			configMetricsTest.HTTP.BaseURL = fakeServer.URL
+			configMetricsTest.HTTP.AuthUsername = ""
+			configMetricsTest.HTTP.AuthPassword = ""
+		}
+	}
+
 	//tags
 	//Create a new api client
 	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
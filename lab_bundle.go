@@ -0,0 +1,654 @@
+package snmpsimclient
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LabBundleVersion is the version of the bundle format written by ExportLab.
+	LabBundleVersion = 1
+	// labBundleManifest is the name of the manifest inside of a bundle.
+	labBundleManifest = "manifest.json"
+	// labBundleRecordings is the directory of the record files inside of a bundle.
+	labBundleRecordings = "recordings/"
+)
+
+/*
+LabBundleManifest describes the contents of a lab bundle.
+It is a LabSpec whose recordings reference the record files inside of the bundle, extended by the tags of the exported objects.
+*/
+type LabBundleManifest struct {
+	Version int `json:"version" yaml:"version"`
+	LabSpec `yaml:",inline"`
+	Tags    []TagDefinition `json:"tags" yaml:"tags"`
+}
+
+/*
+TagDefinition describes a tag and the names of the objects it is applied to.
+*/
+type TagDefinition struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Labs        []string `json:"labs" yaml:"labs"`
+	Agents      []string `json:"agents" yaml:"agents"`
+	Engines     []string `json:"engines" yaml:"engines"`
+	Endpoints   []string `json:"endpoints" yaml:"endpoints"`
+	Users       []string `json:"users" yaml:"users"`
+}
+
+/*
+ExportLab writes the lab with the given id as a self-contained tar.gz bundle to w.
+The bundle contains a manifest.json with the lab, its agents, engines, endpoints, users and tags, and every record file inside of the data dirs of the agents below recordings/.
+Exporting the same lab twice produces the same bundle, so bundles can be archived and compared.
+The manifest contains the auth and priv keys of the users.
+*/
+func (c *ManagementClient) ExportLab(labID int, w io.Writer) error {
+	return c.ExportLabCtx(context.Background(), labID, w)
+}
+
+/*
+ExportLabCtx is like ExportLab but uses the given context for the requests.
+*/
+func (c *ManagementClient) ExportLabCtx(ctx context.Context, labID int, w io.Writer) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	if w == nil {
+		return errors.New("invalid writer")
+	}
+
+	lab, err := c.GetLabCtx(ctx, labID)
+	if err != nil {
+		return errors.Wrap(err, "error while getting lab")
+	}
+	manifest, err := newLabBundleManifest(lab)
+	if err != nil {
+		return err
+	}
+
+	recordings, err := c.GetRecordFilesCtx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error while getting record files")
+	}
+	files := make(map[string][]byte)
+	for _, recording := range recordings {
+		if !manifest.referencesRecording(recording.Path) {
+			continue
+		}
+		content, err := c.GetRecordFileCtx(ctx, recording.Path)
+		if err != nil {
+			return errors.Wrap(err, "error while getting record file "+recording.Path)
+		}
+		file := labBundleRecordings + recording.Path
+		files[file] = []byte(content)
+		manifest.Recordings = append(manifest.Recordings, RecordingDefinition{Path: recording.Path, File: file})
+	}
+	sort.Slice(manifest.Recordings, func(i, j int) bool {
+		return manifest.Recordings[i].Path < manifest.Recordings[j].Path
+	})
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error during marshalling manifest")
+	}
+	files[labBundleManifest] = append(b, '\n')
+	return writeLabBundle(w, files)
+}
+
+// newLabBundleManifest converts a lab and all objects linked to it into a manifest without recordings.
+func newLabBundleManifest(lab Lab) (LabBundleManifest, error) {
+	manifest := LabBundleManifest{Version: LabBundleVersion}
+	names := make(map[string]map[int]string)
+	seen := func(objectType string, id int, name string) (bool, error) {
+		if names[objectType] == nil {
+			names[objectType] = make(map[int]string)
+		}
+		if _, ok := names[objectType][id]; ok {
+			return true, nil
+		}
+		for _, other := range names[objectType] {
+			if other == name {
+				return false, ambiguousNameError(objectType, name)
+			}
+		}
+		names[objectType][id] = name
+		return false, nil
+	}
+	tags := make(map[int]*TagDefinition)
+	addTags := func(objectTags Tags, add func(tag *TagDefinition)) {
+		for _, tag := range objectTags {
+			if tags[tag.ID] == nil {
+				tags[tag.ID] = &TagDefinition{Name: tag.Name, Description: tag.Description}
+			}
+			add(tags[tag.ID])
+		}
+	}
+
+	labDefinition := LabDefinition{Name: lab.Name, Power: lab.Power == "on", Agents: []string{}}
+	addTags(lab.Tags, func(tag *TagDefinition) { tag.Labs = append(tag.Labs, lab.Name) })
+	for _, agent := range lab.Agents {
+		labDefinition.Agents = append(labDefinition.Agents, agent.Name)
+		duplicate, err := seen(objectTypeAgent, agent.ID, agent.Name)
+		if err != nil {
+			return LabBundleManifest{}, err
+		}
+		if duplicate {
+			continue
+		}
+		agentDefinition := AgentDefinition{Name: agent.Name, DataDir: agent.DataDir, Engines: []string{}}
+		addTags(agent.Tags, func(tag *TagDefinition) { tag.Agents = append(tag.Agents, agent.Name) })
+		for _, engine := range agent.Engines {
+			agentDefinition.Engines = append(agentDefinition.Engines, engine.Name)
+			duplicate, err := seen(objectTypeEngine, engine.ID, engine.Name)
+			if err != nil {
+				return LabBundleManifest{}, err
+			}
+			if duplicate {
+				continue
+			}
+			engineDefinition := EngineDefinition{Name: engine.Name, EngineID: engine.EngineID, Endpoints: []string{}, Users: []string{}}
+			addTags(engine.Tags, func(tag *TagDefinition) { tag.Engines = append(tag.Engines, engine.Name) })
+			for _, endpoint := range engine.Endpoints {
+				engineDefinition.Endpoints = append(engineDefinition.Endpoints, endpoint.Name)
+				duplicate, err := seen(objectTypeEndpoint, endpoint.ID, endpoint.Name)
+				if err != nil {
+					return LabBundleManifest{}, err
+				}
+				if duplicate {
+					continue
+				}
+				manifest.Endpoints = append(manifest.Endpoints, EndpointDefinition{Name: endpoint.Name, Address: endpoint.Address, Protocol: endpoint.Protocol})
+				addTags(endpoint.Tags, func(tag *TagDefinition) { tag.Endpoints = append(tag.Endpoints, endpoint.Name) })
+			}
+			for _, user := range engine.Users {
+				engineDefinition.Users = append(engineDefinition.Users, user.Name)
+				duplicate, err := seen(objectTypeUser, user.ID, user.Name)
+				if err != nil {
+					return LabBundleManifest{}, err
+				}
+				if duplicate {
+					continue
+				}
+				manifest.Users = append(manifest.Users, UserDefinition{Name: user.Name, User: user.User, AuthKey: user.AuthKey,
+					AuthProto: user.AuthProto, PrivKey: user.PrivKey, PrivProto: user.PrivProto})
+				addTags(user.Tags, func(tag *TagDefinition) { tag.Users = append(tag.Users, user.Name) })
+			}
+			manifest.Engines = append(manifest.Engines, engineDefinition)
+		}
+		manifest.Agents = append(manifest.Agents, agentDefinition)
+	}
+	manifest.Labs = []LabDefinition{labDefinition}
+
+	var tagIDs []int
+	for id := range tags {
+		tagIDs = append(tagIDs, id)
+	}
+	sort.Ints(tagIDs)
+	for _, id := range tagIDs {
+		if _, err := seen(objectTypeTag, id, tags[id].Name); err != nil {
+			return LabBundleManifest{}, err
+		}
+		manifest.Tags = append(manifest.Tags, *tags[id])
+	}
+	return manifest, nil
+}
+
+// referencesRecording checks if the record file at the given path is inside of the data dir of one of the agents.
+func (m LabBundleManifest) referencesRecording(path string) bool {
+	for _, agent := range m.Agents {
+		if _, ok := pathInDataDir(path, agent.DataDir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// pathInDataDir returns the path of a record file relative to the given data dir and whether the file is inside of it.
+func pathInDataDir(path, dataDir string) (string, bool) {
+	dataDir = strings.Trim(strings.TrimPrefix(dataDir, "./"), "/")
+	if dataDir == "" || dataDir == "." {
+		return path, true
+	}
+	if strings.HasPrefix(path, dataDir+"/") {
+		return strings.TrimPrefix(path, dataDir+"/"), true
+	}
+	return "", false
+}
+
+// writeLabBundle writes the given files sorted by name as tar.gz, all timestamps are zero to keep the bundle reproducible.
+func writeLabBundle(w io.Writer, files map[string][]byte) error {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		//the manifest is always the first file
+		if names[i] == labBundleManifest || names[j] == labBundleManifest {
+			return names[i] == labBundleManifest
+		}
+		return names[i] < names[j]
+	})
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, name := range names {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: time.Unix(0, 0),
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return errors.Wrap(err, "error while writing "+name+" to bundle")
+		}
+		if _, err := io.Copy(tarWriter, bytes.NewReader(files[name])); err != nil {
+			return errors.Wrap(err, "error while writing "+name+" to bundle")
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return errors.Wrap(err, "error while closing bundle")
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return errors.Wrap(err, "error while closing bundle")
+	}
+	return nil
+}
+
+/*
+ReadLabBundle reads a bundle written by ExportLab. The contents of the record files are returned in the recordings of the manifest.
+*/
+func ReadLabBundle(r io.Reader) (LabBundleManifest, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return LabBundleManifest{}, errors.Wrap(err, "bundle is not gzip compressed")
+	}
+	tarReader := tar.NewReader(gzipReader)
+	files := make(map[string][]byte)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return LabBundleManifest{}, errors.Wrap(err, "error while reading bundle")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return LabBundleManifest{}, errors.Wrap(err, "error while reading "+header.Name+" from bundle")
+		}
+		files[header.Name] = b
+	}
+
+	b, ok := files[labBundleManifest]
+	if !ok {
+		return LabBundleManifest{}, errors.New("bundle does not contain a " + labBundleManifest)
+	}
+	var manifest LabBundleManifest
+	err = json.Unmarshal(b, &manifest)
+	if err != nil {
+		return LabBundleManifest{}, errors.Wrap(err, "error during unmarshalling manifest")
+	}
+	if manifest.Version < 1 || manifest.Version > LabBundleVersion {
+		return LabBundleManifest{}, errors.New("unsupported bundle version " + strconv.Itoa(manifest.Version))
+	}
+	for i, recording := range manifest.Recordings {
+		if recording.File == "" {
+			continue
+		}
+		content, ok := files[recording.File]
+		if !ok {
+			return LabBundleManifest{}, errors.New("bundle does not contain the record file " + recording.File)
+		}
+		manifest.Recordings[i].Content = string(content)
+		manifest.Recordings[i].File = ""
+	}
+	return manifest, manifest.Validate()
+}
+
+/*
+ConflictPolicy decides what ImportLab does with objects which already exist with the same name.
+*/
+type ConflictPolicy string
+
+const (
+	// ConflictFail aborts the import before anything is changed if an object already exists.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictSkip keeps existing objects and their links unchanged, imported objects are linked to them.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictReplace deletes existing objects and recreates them as described in the bundle.
+	ConflictReplace ConflictPolicy = "replace"
+)
+
+/*
+ImportLabOptions contains the options for ImportLab.
+*/
+type ImportLabOptions struct {
+	// NamePrefix is prepended to the names of all imported labs, agents, engines, endpoints, users and tags.
+	NamePrefix string
+	// AddressMap replaces the addresses of endpoints, e.g. {"127.0.0.1:1161": "10.0.0.5:1161"}.
+	AddressMap map[string]string
+	// OnConflict is the policy for objects and record files which already exist, the default is ConflictFail.
+	OnConflict ConflictPolicy
+}
+
+/*
+ImportConflictError is returned by ImportLab with ConflictFail if objects of the bundle already exist.
+It matches ErrConflict.
+*/
+type ImportConflictError struct {
+	Conflicts []string
+}
+
+func (i *ImportConflictError) Error() string {
+	return "objects already exist: " + strings.Join(i.Conflicts, ", ")
+}
+
+/*
+Is reports whether target is ErrConflict.
+*/
+func (i *ImportConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+/*
+ImportLab reads a bundle written by ExportLab and recreates the lab with all of its objects, links, tags and record files.
+The names, addresses and the handling of existing objects can be changed with the options. Data dirs and record file paths are not changed.
+The imported lab is returned.
+*/
+func (c *ManagementClient) ImportLab(bundle io.Reader, opts ImportLabOptions) (Lab, error) {
+	return c.ImportLabCtx(context.Background(), bundle, opts)
+}
+
+/*
+ImportLabCtx is like ImportLab but uses the given context for the requests.
+*/
+func (c *ManagementClient) ImportLabCtx(ctx context.Context, bundle io.Reader, opts ImportLabOptions) (Lab, error) {
+	if !c.isValid() {
+		return Lab{}, &NotValidError{}
+	}
+	if bundle == nil {
+		return Lab{}, errors.New("invalid bundle")
+	}
+	policy := opts.OnConflict
+	if policy == "" {
+		policy = ConflictFail
+	}
+	if policy != ConflictFail && policy != ConflictSkip && policy != ConflictReplace {
+		return Lab{}, errors.New("invalid conflict policy " + string(policy))
+	}
+
+	manifest, err := ReadLabBundle(bundle)
+	if err != nil {
+		return Lab{}, err
+	}
+	if len(manifest.Labs) != 1 {
+		return Lab{}, errors.New("bundle contains " + strconv.Itoa(len(manifest.Labs)) + " labs instead of one")
+	}
+	manifest.rename(opts.NamePrefix, opts.AddressMap)
+	err = manifest.Validate()
+	if err != nil {
+		return Lab{}, errors.Wrap(err, "invalid manifest")
+	}
+
+	state, err := c.getLiveState(ctx)
+	if err != nil {
+		return Lab{}, err
+	}
+	liveTags, err := c.GetTagsCtx(ctx, nil)
+	if err != nil {
+		return Lab{}, errors.Wrap(err, "error while getting tags")
+	}
+
+	var plan LabPlan
+	var conflicts []string
+	skipped := make(map[string]map[string]int)
+	for _, objectType := range []string{objectTypeLab, objectTypeAgent, objectTypeEngine, objectTypeEndpoint, objectTypeUser, objectTypeTag, objectTypeRecording} {
+		skipped[objectType] = make(map[string]int)
+	}
+	//conflict decides what happens with an existing object, it returns true if the object has to be replaced
+	conflict := func(objectType, name string, id int) bool {
+		switch policy {
+		case ConflictSkip:
+			skipped[objectType][name] = id
+		case ConflictReplace:
+			return true
+		default:
+			conflicts = append(conflicts, objectType+" "+name)
+		}
+		return false
+	}
+	importObject := func(objectType, name string, id int, ok bool, err error) error {
+		if err != nil {
+			return err
+		}
+		if ok {
+			if !conflict(objectType, name, id) {
+				return nil
+			}
+			plan = append(plan, state.unlinkFromAllParents(objectType, name, id)...)
+			plan = append(plan, state.unlinkAllChildren(objectType, name, id)...)
+			plan = append(plan, PlanAction{Type: PlanActionDelete, ObjectType: objectType, Name: name, ID: id})
+		}
+		plan = append(plan, PlanAction{Type: PlanActionCreate, ObjectType: objectType, Name: name})
+		return nil
+	}
+
+	for _, user := range manifest.Users {
+		live, ok, err := state.user(user.Name)
+		if err := importObject(objectTypeUser, user.Name, live.ID, ok, err); err != nil {
+			return Lab{}, err
+		}
+	}
+	for _, endpoint := range manifest.Endpoints {
+		live, ok, err := state.endpoint(endpoint.Name)
+		if err := importObject(objectTypeEndpoint, endpoint.Name, live.ID, ok, err); err != nil {
+			return Lab{}, err
+		}
+	}
+	for _, engine := range manifest.Engines {
+		live, ok, err := state.engine(engine.Name)
+		if err := importObject(objectTypeEngine, engine.Name, live.ID, ok, err); err != nil {
+			return Lab{}, err
+		}
+	}
+	for _, agent := range manifest.Agents {
+		live, ok, err := state.agent(agent.Name)
+		if err := importObject(objectTypeAgent, agent.Name, live.ID, ok, err); err != nil {
+			return Lab{}, err
+		}
+	}
+	lab := manifest.Labs[0]
+	live, ok, err := state.lab(lab.Name)
+	if ok && live.Power == "on" && policy == ConflictReplace {
+		plan = append(plan, PlanAction{Type: PlanActionPower, ObjectType: objectTypeLab, Name: lab.Name, ID: live.ID, Power: false})
+	}
+	if err := importObject(objectTypeLab, lab.Name, live.ID, ok, err); err != nil {
+		return Lab{}, err
+	}
+
+	for _, recording := range manifest.Recordings {
+		remote, err := c.GetRecordFileCtx(ctx, recording.Path)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				return Lab{}, errors.Wrap(err, "error while getting record file "+recording.Path)
+			}
+			plan = append(plan, PlanAction{Type: PlanActionUpload, ObjectType: objectTypeRecording, Name: recording.Path})
+			continue
+		}
+		if remote != recording.Content && conflict(objectTypeRecording, recording.Path, 0) {
+			plan = append(plan, PlanAction{Type: PlanActionDelete, ObjectType: objectTypeRecording, Name: recording.Path})
+			plan = append(plan, PlanAction{Type: PlanActionUpload, ObjectType: objectTypeRecording, Name: recording.Path})
+		}
+	}
+
+	//objects which are kept keep their links, skipped objects are linked by their id to the objects which are created by the import
+	link := func(parentType, parentName, childType string, children []string) {
+		parentID, parentSkipped := skipped[parentType][parentName]
+		for _, child := range children {
+			childID, childSkipped := skipped[childType][child]
+			if parentSkipped && childSkipped {
+				continue
+			}
+			plan = append(plan, PlanAction{Type: PlanActionLink, ObjectType: childType, Name: child, ID: childID, ParentType: parentType, ParentName: parentName, ParentID: parentID})
+		}
+	}
+	for _, engine := range manifest.Engines {
+		link(objectTypeEngine, engine.Name, objectTypeUser, engine.Users)
+		link(objectTypeEngine, engine.Name, objectTypeEndpoint, engine.Endpoints)
+	}
+	for _, agent := range manifest.Agents {
+		link(objectTypeAgent, agent.Name, objectTypeEngine, agent.Engines)
+	}
+	link(objectTypeLab, lab.Name, objectTypeAgent, lab.Agents)
+
+	replacedTags := make(map[string]int)
+	for _, tag := range manifest.Tags {
+		var found Tags
+		for _, liveTag := range liveTags {
+			if liveTag.Name == tag.Name {
+				found = append(found, liveTag)
+			}
+		}
+		if len(found) > 1 {
+			return Lab{}, ambiguousNameError(objectTypeTag, tag.Name)
+		}
+		if len(found) == 1 && conflict(objectTypeTag, tag.Name, found[0].ID) {
+			replacedTags[tag.Name] = found[0].ID
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return Lab{}, &ImportConflictError{Conflicts: conflicts}
+	}
+
+	_, isSkipped := skipped[objectTypeLab][lab.Name]
+	if lab.Power && !isSkipped {
+		plan = append(plan, PlanAction{Type: PlanActionPower, ObjectType: objectTypeLab, Name: lab.Name, Power: true})
+	}
+
+	err = c.executePlan(ctx, manifest.LabSpec, dedupeUnlinks(plan))
+	if err != nil {
+		return Lab{}, err
+	}
+
+	state, err = c.getLiveState(ctx)
+	if err != nil {
+		return Lab{}, err
+	}
+	for _, tag := range manifest.Tags {
+		err = c.importTag(ctx, state, tag, skipped, replacedTags)
+		if err != nil {
+			return Lab{}, errors.Wrap(err, "error while importing tag "+tag.Name)
+		}
+	}
+
+	id := state.id(objectTypeLab, lab.Name)
+	if id == 0 {
+		return Lab{}, errors.New("imported lab " + lab.Name + " not found")
+	}
+	return c.GetLabCtx(ctx, id)
+}
+
+// importTag creates or replaces a tag and applies it to all imported objects, skipped objects are not tagged.
+func (c *ManagementClient) importTag(ctx context.Context, state liveState, tag TagDefinition, skipped map[string]map[string]int, replaced map[string]int) error {
+	tagID, ok := skipped[objectTypeTag][tag.Name]
+	if !ok {
+		if id, ok := replaced[tag.Name]; ok {
+			err := c.DeleteTagCtx(ctx, id)
+			if err != nil {
+				return errors.Wrap(err, "error while deleting existing tag")
+			}
+		}
+		newTag, err := c.CreateTagCtx(ctx, tag.Name, tag.Description)
+		if err != nil {
+			return errors.Wrap(err, "error while creating tag")
+		}
+		tagID = newTag.ID
+	}
+
+	apply := func(objectType string, names []string, addTag func(ctx context.Context, id, tagID int) error) error {
+		for _, name := range names {
+			if _, ok := skipped[objectType][name]; ok {
+				continue
+			}
+			id := state.id(objectType, name)
+			if id == 0 {
+				return errors.New("cannot resolve " + objectType + " " + name)
+			}
+			err := addTag(ctx, id, tagID)
+			if err != nil {
+				return errors.Wrap(err, "error while tagging "+objectType+" "+name)
+			}
+		}
+		return nil
+	}
+	if err := apply(objectTypeLab, tag.Labs, c.AddTagToLabCtx); err != nil {
+		return err
+	}
+	if err := apply(objectTypeAgent, tag.Agents, c.AddTagToAgentCtx); err != nil {
+		return err
+	}
+	if err := apply(objectTypeEngine, tag.Engines, c.AddTagToEngineCtx); err != nil {
+		return err
+	}
+	if err := apply(objectTypeEndpoint, tag.Endpoints, c.AddTagToEndpointCtx); err != nil {
+		return err
+	}
+	return apply(objectTypeUser, tag.Users, c.AddTagToUserCtx)
+}
+
+// rename prepends the prefix to all names and references and replaces the endpoint addresses found in the address map.
+func (m *LabBundleManifest) rename(prefix string, addressMap map[string]string) {
+	prefixAll := func(names []string) []string {
+		var renamed []string
+		for _, name := range names {
+			renamed = append(renamed, prefix+name)
+		}
+		return renamed
+	}
+	for i := range m.Labs {
+		m.Labs[i].Name = prefix + m.Labs[i].Name
+		m.Labs[i].Agents = prefixAll(m.Labs[i].Agents)
+	}
+	for i := range m.Agents {
+		m.Agents[i].Name = prefix + m.Agents[i].Name
+		m.Agents[i].Engines = prefixAll(m.Agents[i].Engines)
+	}
+	for i := range m.Engines {
+		m.Engines[i].Name = prefix + m.Engines[i].Name
+		m.Engines[i].Endpoints = prefixAll(m.Engines[i].Endpoints)
+		m.Engines[i].Users = prefixAll(m.Engines[i].Users)
+	}
+	for i := range m.Endpoints {
+		m.Endpoints[i].Name = prefix + m.Endpoints[i].Name
+		if address, ok := addressMap[m.Endpoints[i].Address]; ok {
+			m.Endpoints[i].Address = address
+		}
+	}
+	for i := range m.Users {
+		m.Users[i].Name = prefix + m.Users[i].Name
+	}
+	for i := range m.Tags {
+		tag := &m.Tags[i]
+		tag.Name = prefix + tag.Name
+		tag.Labs = prefixAll(tag.Labs)
+		tag.Agents = prefixAll(tag.Agents)
+		tag.Engines = prefixAll(tag.Engines)
+		tag.Endpoints = prefixAll(tag.Endpoints)
+		tag.Users = prefixAll(tag.Users)
+	}
+}
@@ -0,0 +1,240 @@
+package snmpsimclient
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// readTestBundle returns the names and contents of all files in a bundle in their order.
+func readTestBundle(t *testing.T, bundle []byte) ([]string, map[string]string) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(bundle))
+	if !assert.NoError(t, err, "bundle is not gzip compressed") {
+		t.FailNow()
+	}
+	tarReader := tar.NewReader(gzipReader)
+	var names []string
+	files := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err, "error while reading bundle") {
+			t.FailNow()
+		}
+		b, err := ioutil.ReadAll(tarReader)
+		assert.NoError(t, err, "error while reading "+header.Name)
+		names = append(names, header.Name)
+		files[header.Name] = string(b)
+	}
+	return names, files
+}
+
+// setUpBundleTestLab applies the lab spec of the lab spec tests to the given server and tags its lab and user.
+func setUpBundleTestLab(t *testing.T, client *ManagementClient) Lab {
+	spec, err := ParseLabSpecYAML([]byte(testLabSpecYAML))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		t.FailNow()
+	}
+	spec.Users[0].AuthKey = "authkey1"
+	spec.Users[0].AuthProto = "md5"
+	_, err = client.Apply(spec)
+	if !assert.NoError(t, err, "error during Apply") {
+		t.FailNow()
+	}
+	content := "1.3.6.1.2.1.1.1.0|4|other\n"
+	assert.NoError(t, client.UploadRecordFileString(&content, "other-agent/public.snmprec"))
+
+	labs, err := client.GetLabs(LabFilter{Name: "test-LabSpec-lab1"}.Params())
+	if !assert.NoError(t, err, "error during GetLabs") || !assert.Len(t, labs, 1) {
+		t.FailNow()
+	}
+	tag, err := client.CreateTag("bundle", "exported objects")
+	if !assert.NoError(t, err, "error during CreateTag") {
+		t.FailNow()
+	}
+	assert.NoError(t, client.AddTagToLab(labs[0].ID, tag.ID))
+	users, err := client.GetUsers(UserFilter{Name: "test-LabSpec-user1"}.Params())
+	if assert.NoError(t, err, "error during GetUsers") && assert.Len(t, users, 1) {
+		assert.NoError(t, client.AddTagToUser(users[0].ID, tag.ID))
+	}
+	return labs[0]
+}
+
+func TestManagementClient_ExportLab(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab := setUpBundleTestLab(t, client)
+
+	var bundle bytes.Buffer
+	if !assert.NoError(t, client.ExportLab(lab.ID, &bundle), "error during ExportLab") {
+		return
+	}
+	names, files := readTestBundle(t, bundle.Bytes())
+	assert.Equal(t, []string{"manifest.json", "recordings/test-LabSpec-agent1/public.snmprec"}, names)
+	assert.Equal(t, "1.3.6.1.2.1.1.1.0|4|test\n", files["recordings/test-LabSpec-agent1/public.snmprec"])
+
+	var manifest LabBundleManifest
+	if !assert.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest), "error during unmarshalling manifest") {
+		return
+	}
+	assert.Equal(t, LabBundleVersion, manifest.Version)
+	assert.NoError(t, manifest.Validate(), "manifest is not a valid lab spec")
+	assert.Equal(t, []LabDefinition{{Name: "test-LabSpec-lab1", Agents: []string{"test-LabSpec-agent1"}}}, manifest.Labs)
+	assert.Equal(t, []string{"test-LabSpec-engine1"}, manifest.Agents[0].Engines)
+	if assert.Len(t, manifest.Engines, 1) {
+		assert.Equal(t, "010203040507080C00000000", manifest.Engines[0].EngineID)
+		assert.Equal(t, []string{"test-LabSpec-endpoint1"}, manifest.Engines[0].Endpoints)
+	}
+	assert.Equal(t, []EndpointDefinition{{Name: "test-LabSpec-endpoint1", Address: "127.0.0.1:1161", Protocol: "udpv4"}}, manifest.Endpoints)
+	if assert.Len(t, manifest.Users, 1) {
+		assert.Equal(t, "authkey1", manifest.Users[0].AuthKey)
+	}
+	assert.Equal(t, []RecordingDefinition{{Path: "test-LabSpec-agent1/public.snmprec", File: "recordings/test-LabSpec-agent1/public.snmprec"}}, manifest.Recordings)
+	assert.Equal(t, []TagDefinition{{Name: "bundle", Description: "exported objects", Labs: []string{"test-LabSpec-lab1"}, Users: []string{"test-LabSpec-user1"}}}, manifest.Tags)
+
+	//exports of an unchanged lab are identical
+	var second bytes.Buffer
+	if assert.NoError(t, client.ExportLab(lab.ID, &second), "error during ExportLab") {
+		assert.Equal(t, bundle.Bytes(), second.Bytes(), "bundles of the same lab differ")
+	}
+
+	assert.Error(t, client.ExportLab(1234, &second), "no error for missing lab")
+}
+
+func TestManagementClient_ImportLab(t *testing.T) {
+	source := snmpsimtest.NewServer()
+	defer source.Close()
+	target := snmpsimtest.NewServer()
+	defer target.Close()
+
+	sourceClient, err := NewManagementClient(source.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	client, err := NewManagementClient(target.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab := setUpBundleTestLab(t, sourceClient)
+	assert.NoError(t, sourceClient.SetLabPower(lab.ID, true))
+	var bundle bytes.Buffer
+	if !assert.NoError(t, sourceClient.ExportLab(lab.ID, &bundle), "error during ExportLab") {
+		return
+	}
+
+	opts := ImportLabOptions{NamePrefix: "copy-", AddressMap: map[string]string{"127.0.0.1:1161": "127.0.0.1:2161"}}
+	imported, err := client.ImportLab(bytes.NewReader(bundle.Bytes()), opts)
+	if !assert.NoError(t, err, "error during ImportLab") {
+		return
+	}
+	assert.Equal(t, "copy-test-LabSpec-lab1", imported.Name)
+	assert.Equal(t, "on", imported.Power)
+	if assert.Len(t, imported.Tags, 1) {
+		assert.Equal(t, "copy-bundle", imported.Tags[0].Name)
+	}
+	if assert.Len(t, imported.Agents, 1) && assert.Len(t, imported.Agents[0].Engines, 1) {
+		engine := imported.Agents[0].Engines[0]
+		assert.Equal(t, "copy-test-LabSpec-engine1", engine.Name)
+		if assert.Len(t, engine.Endpoints, 1) {
+			assert.Equal(t, "127.0.0.1:2161", engine.Endpoints[0].Address)
+		}
+		if assert.Len(t, engine.Users, 1) {
+			assert.Equal(t, "test-LabSpec-user1", engine.Users[0].User)
+			assert.Equal(t, "authkey1", engine.Users[0].AuthKey)
+			assert.Len(t, engine.Users[0].Tags, 1)
+		}
+	}
+	content, err := client.GetRecordFile("test-LabSpec-agent1/public.snmprec")
+	if assert.NoError(t, err, "error during GetRecordFile") {
+		assert.Equal(t, "1.3.6.1.2.1.1.1.0|4|test\n", content)
+	}
+
+	//the objects exist now
+	_, err = client.ImportLab(bytes.NewReader(bundle.Bytes()), opts)
+	var conflictErr *ImportConflictError
+	if assert.True(t, errors.As(err, &conflictErr), "error is not an ImportConflictError") {
+		assert.Contains(t, conflictErr.Conflicts, "engine copy-test-LabSpec-engine1")
+		assert.True(t, errors.Is(err, ErrConflict))
+	}
+
+	opts.OnConflict = ConflictSkip
+	skipped, err := client.ImportLab(bytes.NewReader(bundle.Bytes()), opts)
+	if assert.NoError(t, err, "error during ImportLab with ConflictSkip") {
+		assert.Equal(t, imported.ID, skipped.ID, "skipped lab was recreated")
+	}
+
+	opts.OnConflict = ConflictReplace
+	replaced, err := client.ImportLab(bytes.NewReader(bundle.Bytes()), opts)
+	if assert.NoError(t, err, "error during ImportLab with ConflictReplace") {
+		assert.NotEqual(t, imported.ID, replaced.ID, "replaced lab was not recreated")
+		assert.Equal(t, "on", replaced.Power)
+		assert.Len(t, replaced.Tags, 1)
+	}
+	labs, err := client.GetLabs(nil)
+	if assert.NoError(t, err, "error during GetLabs") {
+		assert.Len(t, labs, 1)
+	}
+	users, err := client.GetUsers(nil)
+	if assert.NoError(t, err, "error during GetUsers") {
+		assert.Len(t, users, 1)
+	}
+	tags, err := client.GetTags(nil)
+	if assert.NoError(t, err, "error during GetTags") {
+		assert.Len(t, tags, 1)
+	}
+
+	opts.OnConflict = "overwrite"
+	_, err = client.ImportLab(bytes.NewReader(bundle.Bytes()), opts)
+	assert.Error(t, err, "no error for invalid conflict policy")
+	_, err = client.ImportLab(bytes.NewReader([]byte("no bundle")), ImportLabOptions{})
+	assert.Error(t, err, "no error for invalid bundle")
+}
+
+func TestManagementClient_ImportLab_SkipParent(t *testing.T) {
+	source := snmpsimtest.NewServer()
+	defer source.Close()
+	target := snmpsimtest.NewServer()
+	defer target.Close()
+
+	sourceClient, err := NewManagementClient(source.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	client, err := NewManagementClient(target.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab := setUpBundleTestLab(t, sourceClient)
+	var bundle bytes.Buffer
+	if !assert.NoError(t, sourceClient.ExportLab(lab.ID, &bundle), "error during ExportLab") {
+		return
+	}
+
+	existing, err := client.CreateLab("test-LabSpec-lab1")
+	if !assert.NoError(t, err, "error during CreateLab") {
+		return
+	}
+	imported, err := client.ImportLab(bytes.NewReader(bundle.Bytes()), ImportLabOptions{OnConflict: ConflictSkip})
+	if !assert.NoError(t, err, "error during ImportLab with ConflictSkip") {
+		return
+	}
+	assert.Equal(t, existing.ID, imported.ID, "skipped lab was recreated")
+	if assert.Len(t, imported.Agents, 1, "new agent was not linked to the skipped lab") {
+		assert.Equal(t, "test-LabSpec-agent1", imported.Agents[0].Name)
+		assert.Len(t, imported.Agents[0].Engines, 1)
+	}
+}
@@ -0,0 +1,1106 @@
+package snmpsimclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+/*
+LabSpec is a declarative description of one or more labs and all objects they consist of.
+Objects are identified by their name, links between objects are expressed by referencing the names of the linked objects.
+*/
+type LabSpec struct {
+	Labs       []LabDefinition       `json:"labs" yaml:"labs"`
+	Agents     []AgentDefinition     `json:"agents" yaml:"agents"`
+	Engines    []EngineDefinition    `json:"engines" yaml:"engines"`
+	Endpoints  []EndpointDefinition  `json:"endpoints" yaml:"endpoints"`
+	Users      []UserDefinition      `json:"users" yaml:"users"`
+	Recordings []RecordingDefinition `json:"recordings" yaml:"recordings"`
+}
+
+/*
+LabDefinition describes a lab and the names of the agents which belong to it.
+*/
+type LabDefinition struct {
+	Name   string   `json:"name" yaml:"name"`
+	Power  bool     `json:"power" yaml:"power"`
+	Agents []string `json:"agents" yaml:"agents"`
+}
+
+/*
+AgentDefinition describes an agent and the names of the engines which belong to it.
+*/
+type AgentDefinition struct {
+	Name    string   `json:"name" yaml:"name"`
+	DataDir string   `json:"data_dir" yaml:"data_dir"`
+	Engines []string `json:"engines" yaml:"engines"`
+}
+
+/*
+EngineDefinition describes an engine and the names of the endpoints and users which belong to it.
+*/
+type EngineDefinition struct {
+	Name      string   `json:"name" yaml:"name"`
+	EngineID  string   `json:"engine_id" yaml:"engine_id"`
+	Endpoints []string `json:"endpoints" yaml:"endpoints"`
+	Users     []string `json:"users" yaml:"users"`
+}
+
+/*
+EndpointDefinition describes an endpoint.
+*/
+type EndpointDefinition struct {
+	Name     string `json:"name" yaml:"name"`
+	Address  string `json:"address" yaml:"address"`
+	Protocol string `json:"protocol" yaml:"protocol"`
+}
+
+/*
+UserDefinition describes a SNMPv3 USM user.
+*/
+type UserDefinition struct {
+	Name      string `json:"name" yaml:"name"`
+	User      string `json:"user" yaml:"user"`
+	AuthKey   string `json:"auth_key" yaml:"auth_key"`
+	AuthProto string `json:"auth_proto" yaml:"auth_proto"`
+	PrivKey   string `json:"priv_key" yaml:"priv_key"`
+	PrivProto string `json:"priv_proto" yaml:"priv_proto"`
+}
+
+/*
+RecordingDefinition describes a record file which has to be present at the given path inside of the data dir.
+The contents are either taken from the local file File or from Content.
+*/
+type RecordingDefinition struct {
+	Path    string `json:"path" yaml:"path"`
+	File    string `json:"file" yaml:"file"`
+	Content string `json:"content" yaml:"content"`
+}
+
+/*
+LoadLabSpec reads a lab spec from the given file. Files ending with .json are parsed as json, all other files as yaml.
+Relative record file paths inside of the spec are resolved relative to the directory of the spec file.
+*/
+func LoadLabSpec(path string) (LabSpec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return LabSpec{}, errors.Wrap(err, "error while reading file")
+	}
+
+	var spec LabSpec
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		spec, err = ParseLabSpecJSON(b)
+	} else {
+		spec, err = ParseLabSpecYAML(b)
+	}
+	if err != nil {
+		return LabSpec{}, err
+	}
+
+	for i, recording := range spec.Recordings {
+		if recording.File != "" && !filepath.IsAbs(recording.File) {
+			spec.Recordings[i].File = filepath.Join(filepath.Dir(path), recording.File)
+		}
+	}
+	return spec, nil
+}
+
+/*
+ParseLabSpecYAML parses a yaml encoded lab spec.
+*/
+func ParseLabSpecYAML(data []byte) (LabSpec, error) {
+	var spec LabSpec
+	err := yaml.UnmarshalStrict(data, &spec)
+	if err != nil {
+		return LabSpec{}, errors.Wrap(err, "error during unmarshalling yaml lab spec")
+	}
+	return spec, spec.Validate()
+}
+
+/*
+ParseLabSpecJSON parses a json encoded lab spec. Like ParseLabSpecYAML, it rejects unknown keys.
+*/
+func ParseLabSpecJSON(data []byte) (LabSpec, error) {
+	var spec LabSpec
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&spec)
+	if err != nil {
+		return LabSpec{}, errors.Wrap(err, "error during unmarshalling json lab spec")
+	}
+	if decoder.More() {
+		return LabSpec{}, errors.New("error during unmarshalling json lab spec: unexpected data after the lab spec")
+	}
+	return spec, spec.Validate()
+}
+
+/*
+Validate checks that all objects of the spec have a unique name and that all references can be resolved.
+*/
+func (s LabSpec) Validate() error {
+	names := make(map[string]map[string]bool)
+	add := func(objectType, name string) error {
+		if name == "" {
+			return errors.New("invalid " + objectType + " definition: missing name")
+		}
+		if names[objectType] == nil {
+			names[objectType] = make(map[string]bool)
+		}
+		if names[objectType][name] {
+			return errors.New("duplicate " + objectType + " definition: " + name)
+		}
+		names[objectType][name] = true
+		return nil
+	}
+	check := func(objectType, name, refType string, refs []string) error {
+		seen := make(map[string]bool)
+		for _, ref := range refs {
+			if !names[refType][ref] {
+				return errors.New(objectType + " " + name + " references unknown " + refType + " " + ref)
+			}
+			if seen[ref] {
+				return errors.New(objectType + " " + name + " references " + refType + " " + ref + " twice")
+			}
+			seen[ref] = true
+		}
+		return nil
+	}
+
+	for _, user := range s.Users {
+		if err := add(objectTypeUser, user.Name); err != nil {
+			return err
+		}
+		if user.User == "" {
+			return errors.New("invalid user definition " + user.Name + ": missing user")
+		}
+		if err := ValidateUSM(user.AuthKey, user.AuthProto, user.PrivKey, user.PrivProto); err != nil {
+			return errors.Wrap(err, "invalid user definition "+user.Name)
+		}
+	}
+	for _, endpoint := range s.Endpoints {
+		if err := add(objectTypeEndpoint, endpoint.Name); err != nil {
+			return err
+		}
+		if endpoint.Address == "" {
+			return errors.New("invalid endpoint definition " + endpoint.Name + ": missing address")
+		}
+	}
+	for _, engine := range s.Engines {
+		if err := add(objectTypeEngine, engine.Name); err != nil {
+			return err
+		}
+		if err := ValidateEngineID(engine.EngineID); err != nil {
+			return errors.Wrap(err, "invalid engine definition "+engine.Name)
+		}
+		if err := check(objectTypeEngine, engine.Name, objectTypeEndpoint, engine.Endpoints); err != nil {
+			return err
+		}
+		if err := check(objectTypeEngine, engine.Name, objectTypeUser, engine.Users); err != nil {
+			return err
+		}
+	}
+	for _, agent := range s.Agents {
+		if err := add(objectTypeAgent, agent.Name); err != nil {
+			return err
+		}
+		if err := check(objectTypeAgent, agent.Name, objectTypeEngine, agent.Engines); err != nil {
+			return err
+		}
+	}
+	for _, lab := range s.Labs {
+		if err := add(objectTypeLab, lab.Name); err != nil {
+			return err
+		}
+		if err := check(objectTypeLab, lab.Name, objectTypeAgent, lab.Agents); err != nil {
+			return err
+		}
+	}
+	for _, recording := range s.Recordings {
+		if err := add(objectTypeRecording, recording.Path); err != nil {
+			return err
+		}
+		if !strings.HasSuffix(recording.Path, ".snmprec") {
+			return errors.New("invalid recording definition " + recording.Path + ": file is not an snmprec file")
+		}
+		if recording.File != "" && recording.Content != "" {
+			return errors.New("invalid recording definition " + recording.Path + ": file and content are mutually exclusive")
+		}
+	}
+	return nil
+}
+
+const (
+	objectTypeLab       = "lab"
+	objectTypeAgent     = "agent"
+	objectTypeEngine    = "engine"
+	objectTypeEndpoint  = "endpoint"
+	objectTypeUser      = "user"
+	objectTypeRecording = "recording"
+	objectTypeTag       = "tag"
+)
+
+/*
+PlanActionType is the kind of change a PlanAction performs.
+*/
+type PlanActionType string
+
+const (
+	// PlanActionCreate creates a new object.
+	PlanActionCreate PlanActionType = "create"
+	// PlanActionDelete deletes an existing object.
+	PlanActionDelete PlanActionType = "delete"
+	// PlanActionLink links an object to a parent object.
+	PlanActionLink PlanActionType = "link"
+	// PlanActionUnlink removes the link between an object and a parent object.
+	PlanActionUnlink PlanActionType = "unlink"
+	// PlanActionUpload uploads a record file.
+	PlanActionUpload PlanActionType = "upload"
+	// PlanActionPower sets the power state of a lab.
+	PlanActionPower PlanActionType = "power"
+)
+
+/*
+PlanAction is a single step which is necessary to bring the api in line with a LabSpec.
+ID and ParentID are set for objects which already exist, objects which are created during Apply are referenced by name.
+*/
+type PlanAction struct {
+	Type       PlanActionType `json:"type"`
+	ObjectType string         `json:"object_type"`
+	Name       string         `json:"name"`
+	ID         int            `json:"id,omitempty"`
+	ParentType string         `json:"parent_type,omitempty"`
+	ParentName string         `json:"parent_name,omitempty"`
+	ParentID   int            `json:"parent_id,omitempty"`
+	Power      bool           `json:"power,omitempty"`
+}
+
+func (a PlanAction) String() string {
+	s := string(a.Type) + " " + a.ObjectType + " " + a.Name
+	switch a.Type {
+	case PlanActionLink:
+		s += " to " + a.ParentType + " " + a.ParentName
+	case PlanActionUnlink:
+		s += " from " + a.ParentType + " " + a.ParentName
+	case PlanActionPower:
+		if a.Power {
+			s += " on"
+		} else {
+			s += " off"
+		}
+	}
+	return s
+}
+
+/*
+LabPlan is an ordered list of actions.
+*/
+type LabPlan []PlanAction
+
+/*
+Empty returns true if no changes are necessary.
+*/
+func (p LabPlan) Empty() bool {
+	return len(p) == 0
+}
+
+/*
+Plan computes which actions are necessary to bring the api in line with the given spec.
+Objects are matched by name, objects whose attributes differ from the spec are deleted and recreated.
+*/
+func (c *ManagementClient) Plan(spec LabSpec) (LabPlan, error) {
+	return c.PlanCtx(context.Background(), spec)
+}
+
+/*
+PlanCtx is like Plan but uses the given context for the requests.
+*/
+func (c *ManagementClient) PlanCtx(ctx context.Context, spec LabSpec) (LabPlan, error) {
+	if !c.isValid() {
+		return nil, &NotValidError{}
+	}
+	err := spec.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid lab spec")
+	}
+
+	state, err := c.getLiveState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan LabPlan
+	recreated := make(map[string]map[int]bool)
+	for _, objectType := range []string{objectTypeLab, objectTypeAgent, objectTypeEngine, objectTypeEndpoint, objectTypeUser} {
+		recreated[objectType] = make(map[int]bool)
+	}
+
+	//recreate objects which differ from the spec
+	recreate := func(objectType, name string, id int) {
+		plan = append(plan, state.unlinkFromAllParents(objectType, name, id)...)
+		plan = append(plan, state.unlinkAllChildren(objectType, name, id)...)
+		plan = append(plan, PlanAction{Type: PlanActionDelete, ObjectType: objectType, Name: name, ID: id})
+		plan = append(plan, PlanAction{Type: PlanActionCreate, ObjectType: objectType, Name: name})
+		recreated[objectType][id] = true
+	}
+	create := func(objectType, name string) {
+		plan = append(plan, PlanAction{Type: PlanActionCreate, ObjectType: objectType, Name: name})
+	}
+
+	for _, user := range spec.Users {
+		live, ok, err := state.user(user.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			create(objectTypeUser, user.Name)
+		} else if !user.matches(live) {
+			recreate(objectTypeUser, user.Name, live.ID)
+		}
+	}
+	for _, endpoint := range spec.Endpoints {
+		live, ok, err := state.endpoint(endpoint.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			create(objectTypeEndpoint, endpoint.Name)
+		} else if !endpoint.matches(live) {
+			recreate(objectTypeEndpoint, endpoint.Name, live.ID)
+		}
+	}
+	for _, engine := range spec.Engines {
+		live, ok, err := state.engine(engine.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			create(objectTypeEngine, engine.Name)
+		} else if !engine.matches(live) {
+			recreate(objectTypeEngine, engine.Name, live.ID)
+		}
+	}
+	for _, agent := range spec.Agents {
+		live, ok, err := state.agent(agent.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			create(objectTypeAgent, agent.Name)
+		} else if !agent.matches(live) {
+			recreate(objectTypeAgent, agent.Name, live.ID)
+		}
+	}
+	for _, lab := range spec.Labs {
+		_, ok, err := state.lab(lab.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			create(objectTypeLab, lab.Name)
+		}
+	}
+
+	//upload record files
+	for _, recording := range spec.Recordings {
+		content, err := recording.content()
+		if err != nil {
+			return nil, err
+		}
+		remote, err := c.GetRecordFileCtx(ctx, recording.Path)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				return nil, errors.Wrap(err, "error while getting record file "+recording.Path)
+			}
+			plan = append(plan, PlanAction{Type: PlanActionUpload, ObjectType: objectTypeRecording, Name: recording.Path})
+			continue
+		}
+		if remote != content {
+			//TODO: remove the delete when its possible to overwrite files
+			plan = append(plan, PlanAction{Type: PlanActionDelete, ObjectType: objectTypeRecording, Name: recording.Path})
+			plan = append(plan, PlanAction{Type: PlanActionUpload, ObjectType: objectTypeRecording, Name: recording.Path})
+		}
+	}
+
+	//link and unlink objects
+	diffLinks := func(parentType, parentName string, parentID int, childType string, liveChildren []int, specChildren []string) {
+		if recreated[parentType][parentID] {
+			liveChildren = nil
+		}
+		liveNames := make(map[string]bool)
+		for _, childID := range liveChildren {
+			if recreated[childType][childID] {
+				continue
+			}
+			childName := state.name(childType, childID)
+			liveNames[childName] = true
+			if !containsString(specChildren, childName) {
+				plan = append(plan, PlanAction{Type: PlanActionUnlink, ObjectType: childType, Name: childName, ID: childID, ParentType: parentType, ParentName: parentName, ParentID: parentID})
+			}
+		}
+		for _, childName := range specChildren {
+			if !liveNames[childName] {
+				plan = append(plan, PlanAction{Type: PlanActionLink, ObjectType: childType, Name: childName, ID: state.id(childType, childName), ParentType: parentType, ParentName: parentName, ParentID: parentID})
+			}
+		}
+	}
+	for _, engine := range spec.Engines {
+		live, _, _ := state.engine(engine.Name)
+		diffLinks(objectTypeEngine, engine.Name, live.ID, objectTypeUser, live.Users.ids(), engine.Users)
+		diffLinks(objectTypeEngine, engine.Name, live.ID, objectTypeEndpoint, live.Endpoints.ids(), engine.Endpoints)
+	}
+	for _, agent := range spec.Agents {
+		live, _, _ := state.agent(agent.Name)
+		diffLinks(objectTypeAgent, agent.Name, live.ID, objectTypeEngine, live.Engines.ids(), agent.Engines)
+	}
+	for _, lab := range spec.Labs {
+		live, _, _ := state.lab(lab.Name)
+		diffLinks(objectTypeLab, lab.Name, live.ID, objectTypeAgent, live.Agents.ids(), lab.Agents)
+	}
+
+	//power
+	for _, lab := range spec.Labs {
+		live, ok, _ := state.lab(lab.Name)
+		if ok && (live.Power == "on") == lab.Power {
+			continue
+		}
+		if !ok && !lab.Power {
+			continue
+		}
+		plan = append(plan, PlanAction{Type: PlanActionPower, ObjectType: objectTypeLab, Name: lab.Name, ID: live.ID, Power: lab.Power})
+	}
+
+	//a child and its parent which are both recreated are unlinked twice
+	return dedupeUnlinks(plan), nil
+}
+
+/*
+Apply brings the api in line with the given spec and returns the executed plan.
+Applying the same spec twice does not change anything.
+*/
+func (c *ManagementClient) Apply(spec LabSpec) (LabPlan, error) {
+	return c.ApplyCtx(context.Background(), spec)
+}
+
+/*
+ApplyCtx is like Apply but uses the given context for the requests.
+*/
+func (c *ManagementClient) ApplyCtx(ctx context.Context, spec LabSpec) (LabPlan, error) {
+	plan, err := c.PlanCtx(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	err = c.executePlan(ctx, spec, plan)
+	if err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+/*
+Destroy deletes all objects described in the given spec which exist in the api, including the record files.
+*/
+func (c *ManagementClient) Destroy(spec LabSpec) error {
+	return c.DestroyCtx(context.Background(), spec)
+}
+
+/*
+DestroyCtx is like Destroy but uses the given context for the requests.
+*/
+func (c *ManagementClient) DestroyCtx(ctx context.Context, spec LabSpec) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	err := spec.Validate()
+	if err != nil {
+		return errors.Wrap(err, "invalid lab spec")
+	}
+
+	state, err := c.getLiveState(ctx)
+	if err != nil {
+		return err
+	}
+
+	var plan LabPlan
+	destroy := func(objectType, name string, id int, ok bool, err error) error {
+		if err != nil {
+			return err
+		}
+		if ok {
+			plan = append(plan, state.unlinkFromAllParents(objectType, name, id)...)
+			plan = append(plan, state.unlinkAllChildren(objectType, name, id)...)
+			plan = append(plan, PlanAction{Type: PlanActionDelete, ObjectType: objectType, Name: name, ID: id})
+		}
+		return nil
+	}
+
+	for _, lab := range spec.Labs {
+		live, ok, err := state.lab(lab.Name)
+		if ok && live.Power == "on" {
+			plan = append(plan, PlanAction{Type: PlanActionPower, ObjectType: objectTypeLab, Name: lab.Name, ID: live.ID, Power: false})
+		}
+		if err := destroy(objectTypeLab, lab.Name, live.ID, ok, err); err != nil {
+			return err
+		}
+	}
+	for _, agent := range spec.Agents {
+		live, ok, err := state.agent(agent.Name)
+		if err := destroy(objectTypeAgent, agent.Name, live.ID, ok, err); err != nil {
+			return err
+		}
+	}
+	for _, engine := range spec.Engines {
+		live, ok, err := state.engine(engine.Name)
+		if err := destroy(objectTypeEngine, engine.Name, live.ID, ok, err); err != nil {
+			return err
+		}
+	}
+	for _, endpoint := range spec.Endpoints {
+		live, ok, err := state.endpoint(endpoint.Name)
+		if err := destroy(objectTypeEndpoint, endpoint.Name, live.ID, ok, err); err != nil {
+			return err
+		}
+	}
+	for _, user := range spec.Users {
+		live, ok, err := state.user(user.Name)
+		if err := destroy(objectTypeUser, user.Name, live.ID, ok, err); err != nil {
+			return err
+		}
+	}
+
+	err = c.executePlan(ctx, spec, dedupeUnlinks(plan))
+	if err != nil {
+		return err
+	}
+
+	for _, recording := range spec.Recordings {
+		err = c.DeleteRecordFileCtx(ctx, recording.Path)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				return errors.Wrap(err, "error while deleting record file "+recording.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// executePlan executes the given actions in order. Objects which are created during the execution are resolved by name.
+func (c *ManagementClient) executePlan(ctx context.Context, spec LabSpec, plan LabPlan) error {
+	ids := make(map[string]map[string]int)
+	for _, objectType := range []string{objectTypeLab, objectTypeAgent, objectTypeEngine, objectTypeEndpoint, objectTypeUser} {
+		ids[objectType] = make(map[string]int)
+	}
+	resolve := func(objectType, name string, id int) (int, error) {
+		if newID, ok := ids[objectType][name]; ok {
+			return newID, nil
+		}
+		if id != 0 {
+			return id, nil
+		}
+		return 0, errors.New("cannot resolve " + objectType + " " + name)
+	}
+
+	for _, action := range plan {
+		var err error
+		switch action.Type {
+		case PlanActionCreate:
+			var id int
+			id, err = c.createFromSpec(ctx, spec, action.ObjectType, action.Name)
+			ids[action.ObjectType][action.Name] = id
+		case PlanActionDelete:
+			err = c.deleteObject(ctx, action.ObjectType, action.Name, action.ID)
+		case PlanActionLink:
+			var id, parentID int
+			id, err = resolve(action.ObjectType, action.Name, action.ID)
+			if err == nil {
+				parentID, err = resolve(action.ParentType, action.ParentName, action.ParentID)
+			}
+			if err == nil {
+				err = c.setLink(ctx, true, action.ParentType, parentID, action.ObjectType, id)
+			}
+		case PlanActionUnlink:
+			//unlinks always refer to existing objects, never to objects which are created with the same name during the execution
+			err = c.setLink(ctx, false, action.ParentType, action.ParentID, action.ObjectType, action.ID)
+		case PlanActionUpload:
+			err = c.uploadFromSpec(ctx, spec, action.Name)
+		case PlanActionPower:
+			var id int
+			id, err = resolve(action.ObjectType, action.Name, action.ID)
+			if err == nil {
+				err = c.SetLabPowerCtx(ctx, id, action.Power)
+			}
+		default:
+			err = errors.New("unknown plan action " + string(action.Type))
+		}
+		if err != nil {
+			return errors.Wrap(err, "error during '"+action.String()+"'")
+		}
+	}
+	return nil
+}
+
+func (c *ManagementClient) createFromSpec(ctx context.Context, spec LabSpec, objectType, name string) (int, error) {
+	switch objectType {
+	case objectTypeLab:
+		lab, err := c.CreateLabCtx(ctx, name)
+		return lab.ID, err
+	case objectTypeAgent:
+		for _, agent := range spec.Agents {
+			if agent.Name == name {
+				newAgent, err := c.CreateAgentCtx(ctx, agent.Name, agent.DataDir)
+				return newAgent.ID, err
+			}
+		}
+	case objectTypeEngine:
+		for _, engine := range spec.Engines {
+			if engine.Name == name {
+				newEngine, err := c.CreateEngineCtx(ctx, engine.Name, engine.EngineID)
+				return newEngine.ID, err
+			}
+		}
+	case objectTypeEndpoint:
+		for _, endpoint := range spec.Endpoints {
+			if endpoint.Name == name {
+				newEndpoint, err := c.CreateEndpointCtx(ctx, endpoint.Name, endpoint.Address, endpoint.Protocol)
+				return newEndpoint.ID, err
+			}
+		}
+	case objectTypeUser:
+		for _, user := range spec.Users {
+			if user.Name == name {
+				newUser, err := c.CreateUserCtx(ctx, user.User, user.Name, user.AuthKey, user.AuthProto, user.PrivKey, user.PrivProto)
+				return newUser.ID, err
+			}
+		}
+	}
+	return 0, errors.New("no definition for " + objectType + " " + name)
+}
+
+func (c *ManagementClient) uploadFromSpec(ctx context.Context, spec LabSpec, path string) error {
+	for _, recording := range spec.Recordings {
+		if recording.Path == path {
+			content, err := recording.content()
+			if err != nil {
+				return err
+			}
+			return c.UploadRecordFileStringCtx(ctx, &content, recording.Path)
+		}
+	}
+	return errors.New("no definition for recording " + path)
+}
+
+func (c *ManagementClient) deleteObject(ctx context.Context, objectType, name string, id int) error {
+	switch objectType {
+	case objectTypeLab:
+		return c.DeleteLabCtx(ctx, id)
+	case objectTypeAgent:
+		return c.DeleteAgentCtx(ctx, id)
+	case objectTypeEngine:
+		return c.DeleteEngineCtx(ctx, id)
+	case objectTypeEndpoint:
+		return c.DeleteEndpointCtx(ctx, id)
+	case objectTypeUser:
+		return c.DeleteUserCtx(ctx, id)
+	case objectTypeRecording:
+		return c.DeleteRecordFileCtx(ctx, name)
+	}
+	return errors.New("cannot delete object of type " + objectType)
+}
+
+func (c *ManagementClient) setLink(ctx context.Context, link bool, parentType string, parentID int, objectType string, id int) error {
+	switch parentType + "/" + objectType {
+	case objectTypeLab + "/" + objectTypeAgent:
+		if link {
+			return c.AddAgentToLabCtx(ctx, parentID, id)
+		}
+		return c.RemoveAgentFromLabCtx(ctx, parentID, id)
+	case objectTypeAgent + "/" + objectTypeEngine:
+		if link {
+			return c.AddEngineToAgentCtx(ctx, parentID, id)
+		}
+		return c.RemoveEngineFromAgentCtx(ctx, parentID, id)
+	case objectTypeEngine + "/" + objectTypeUser:
+		if link {
+			return c.AddUserToEngineCtx(ctx, parentID, id)
+		}
+		return c.RemoveUserFromEngineCtx(ctx, parentID, id)
+	case objectTypeEngine + "/" + objectTypeEndpoint:
+		if link {
+			return c.AddEndpointToEngineCtx(ctx, parentID, id)
+		}
+		return c.RemoveEndpointFromEngineCtx(ctx, parentID, id)
+	}
+	return errors.New("cannot link " + objectType + " to " + parentType)
+}
+
+// liveState is a snapshot of all objects which currently exist in the api.
+type liveState struct {
+	labs      Labs
+	agents    Agents
+	engines   Engines
+	endpoints Endpoints
+	users     Users
+}
+
+func (c *ManagementClient) getLiveState(ctx context.Context) (liveState, error) {
+	var state liveState
+	var err error
+	state.labs, err = c.GetLabsCtx(ctx, nil)
+	if err != nil {
+		return liveState{}, errors.Wrap(err, "error while getting labs")
+	}
+	state.agents, err = c.GetAgentsCtx(ctx, nil)
+	if err != nil {
+		return liveState{}, errors.Wrap(err, "error while getting agents")
+	}
+	state.engines, err = c.GetEnginesCtx(ctx, nil)
+	if err != nil {
+		return liveState{}, errors.Wrap(err, "error while getting engines")
+	}
+	state.endpoints, err = c.GetEndpointsCtx(ctx, nil)
+	if err != nil {
+		return liveState{}, errors.Wrap(err, "error while getting endpoints")
+	}
+	state.users, err = c.GetUsersCtx(ctx, nil)
+	if err != nil {
+		return liveState{}, errors.Wrap(err, "error while getting users")
+	}
+	return state, nil
+}
+
+func ambiguousNameError(objectType, name string) error {
+	return errors.New("found more than one " + objectType + " with the name " + name)
+}
+
+func (s liveState) lab(name string) (Lab, bool, error) {
+	var found []Lab
+	for _, lab := range s.labs {
+		if lab.Name == name {
+			found = append(found, lab)
+		}
+	}
+	if len(found) > 1 {
+		return Lab{}, false, ambiguousNameError(objectTypeLab, name)
+	}
+	if len(found) == 0 {
+		return Lab{}, false, nil
+	}
+	return found[0], true, nil
+}
+
+func (s liveState) agent(name string) (Agent, bool, error) {
+	var found []Agent
+	for _, agent := range s.agents {
+		if agent.Name == name {
+			found = append(found, agent)
+		}
+	}
+	if len(found) > 1 {
+		return Agent{}, false, ambiguousNameError(objectTypeAgent, name)
+	}
+	if len(found) == 0 {
+		return Agent{}, false, nil
+	}
+	return found[0], true, nil
+}
+
+func (s liveState) engine(name string) (Engine, bool, error) {
+	var found []Engine
+	for _, engine := range s.engines {
+		if engine.Name == name {
+			found = append(found, engine)
+		}
+	}
+	if len(found) > 1 {
+		return Engine{}, false, ambiguousNameError(objectTypeEngine, name)
+	}
+	if len(found) == 0 {
+		return Engine{}, false, nil
+	}
+	return found[0], true, nil
+}
+
+func (s liveState) endpoint(name string) (Endpoint, bool, error) {
+	var found []Endpoint
+	for _, endpoint := range s.endpoints {
+		if endpoint.Name == name {
+			found = append(found, endpoint)
+		}
+	}
+	if len(found) > 1 {
+		return Endpoint{}, false, ambiguousNameError(objectTypeEndpoint, name)
+	}
+	if len(found) == 0 {
+		return Endpoint{}, false, nil
+	}
+	return found[0], true, nil
+}
+
+func (s liveState) user(name string) (User, bool, error) {
+	var found []User
+	for _, user := range s.users {
+		if user.Name == name {
+			found = append(found, user)
+		}
+	}
+	if len(found) > 1 {
+		return User{}, false, ambiguousNameError(objectTypeUser, name)
+	}
+	if len(found) == 0 {
+		return User{}, false, nil
+	}
+	return found[0], true, nil
+}
+
+// id returns the id of the object with the given type and name or 0 if there is no such object.
+func (s liveState) id(objectType, name string) int {
+	var id int
+	switch objectType {
+	case objectTypeLab:
+		lab, _, _ := s.lab(name)
+		id = lab.ID
+	case objectTypeAgent:
+		agent, _, _ := s.agent(name)
+		id = agent.ID
+	case objectTypeEngine:
+		engine, _, _ := s.engine(name)
+		id = engine.ID
+	case objectTypeEndpoint:
+		endpoint, _, _ := s.endpoint(name)
+		id = endpoint.ID
+	case objectTypeUser:
+		user, _, _ := s.user(name)
+		id = user.ID
+	}
+	return id
+}
+
+// name returns the name of the object with the given type and id.
+func (s liveState) name(objectType string, id int) string {
+	switch objectType {
+	case objectTypeLab:
+		for _, lab := range s.labs {
+			if lab.ID == id {
+				return lab.Name
+			}
+		}
+	case objectTypeAgent:
+		for _, agent := range s.agents {
+			if agent.ID == id {
+				return agent.Name
+			}
+		}
+	case objectTypeEngine:
+		for _, engine := range s.engines {
+			if engine.ID == id {
+				return engine.Name
+			}
+		}
+	case objectTypeEndpoint:
+		for _, endpoint := range s.endpoints {
+			if endpoint.ID == id {
+				return endpoint.Name
+			}
+		}
+	case objectTypeUser:
+		for _, user := range s.users {
+			if user.ID == id {
+				return user.Name
+			}
+		}
+	}
+	return "#" + strconv.Itoa(id)
+}
+
+// unlinkFromAllParents returns the actions which are necessary to remove the object from every object it is linked to.
+func (s liveState) unlinkFromAllParents(objectType, name string, id int) LabPlan {
+	var plan LabPlan
+	unlink := func(parentType, parentName string, parentID int) {
+		plan = append(plan, PlanAction{Type: PlanActionUnlink, ObjectType: objectType, Name: name, ID: id, ParentType: parentType, ParentName: parentName, ParentID: parentID})
+	}
+	switch objectType {
+	case objectTypeAgent:
+		for _, lab := range s.labs {
+			if containsInt(lab.Agents.ids(), id) {
+				unlink(objectTypeLab, lab.Name, lab.ID)
+			}
+		}
+	case objectTypeEngine:
+		for _, agent := range s.agents {
+			if containsInt(agent.Engines.ids(), id) {
+				unlink(objectTypeAgent, agent.Name, agent.ID)
+			}
+		}
+	case objectTypeEndpoint:
+		for _, engine := range s.engines {
+			if containsInt(engine.Endpoints.ids(), id) {
+				unlink(objectTypeEngine, engine.Name, engine.ID)
+			}
+		}
+	case objectTypeUser:
+		for _, engine := range s.engines {
+			if containsInt(engine.Users.ids(), id) {
+				unlink(objectTypeEngine, engine.Name, engine.ID)
+			}
+		}
+	}
+	return plan
+}
+
+// unlinkAllChildren returns the actions which are necessary to remove every object which is linked to the object.
+func (s liveState) unlinkAllChildren(objectType, name string, id int) LabPlan {
+	var plan LabPlan
+	unlink := func(childType string, childID int) {
+		plan = append(plan, PlanAction{Type: PlanActionUnlink, ObjectType: childType, Name: s.name(childType, childID), ID: childID, ParentType: objectType, ParentName: name, ParentID: id})
+	}
+	switch objectType {
+	case objectTypeLab:
+		for _, lab := range s.labs {
+			if lab.ID == id {
+				for _, agentID := range lab.Agents.ids() {
+					unlink(objectTypeAgent, agentID)
+				}
+			}
+		}
+	case objectTypeAgent:
+		for _, agent := range s.agents {
+			if agent.ID == id {
+				for _, engineID := range agent.Engines.ids() {
+					unlink(objectTypeEngine, engineID)
+				}
+			}
+		}
+	case objectTypeEngine:
+		for _, engine := range s.engines {
+			if engine.ID == id {
+				for _, userID := range engine.Users.ids() {
+					unlink(objectTypeUser, userID)
+				}
+				for _, endpointID := range engine.Endpoints.ids() {
+					unlink(objectTypeEndpoint, endpointID)
+				}
+			}
+		}
+	}
+	return plan
+}
+
+// dedupeUnlinks removes duplicate unlink actions and unlink actions of objects which are deleted earlier in the plan, the order of all other actions is kept.
+func dedupeUnlinks(plan LabPlan) LabPlan {
+	seen := make(map[PlanAction]bool)
+	deleted := make(map[string]map[int]bool)
+	var result LabPlan
+	for _, action := range plan {
+		switch action.Type {
+		case PlanActionUnlink:
+			if seen[action] || deleted[action.ObjectType][action.ID] || deleted[action.ParentType][action.ParentID] {
+				continue
+			}
+			seen[action] = true
+		case PlanActionDelete:
+			if deleted[action.ObjectType] == nil {
+				deleted[action.ObjectType] = make(map[int]bool)
+			}
+			deleted[action.ObjectType][action.ID] = true
+		}
+		result = append(result, action)
+	}
+	return result
+}
+
+func (d UserDefinition) matches(user User) bool {
+	priv, err := ParsePrivProtocol(d.PrivProto)
+	if err != nil {
+		return false
+	}
+	return d.User == user.User &&
+		d.AuthKey == user.AuthKey && strings.EqualFold(defaultString(d.AuthProto, "none"), user.AuthProto) &&
+		d.PrivKey == user.PrivKey && strings.EqualFold(priv.apiName(), user.PrivProto)
+}
+
+func (d EndpointDefinition) matches(endpoint Endpoint) bool {
+	return d.Address == endpoint.Address && defaultString(d.Protocol, "udpv4") == endpoint.Protocol
+}
+
+func (d EngineDefinition) matches(engine Engine) bool {
+	//engine ids which are generated by the api cannot be compared
+	return d.EngineID == "" || d.EngineID == "auto" || strings.EqualFold(d.EngineID, engine.EngineID)
+}
+
+func (d AgentDefinition) matches(agent Agent) bool {
+	return defaultString(d.DataDir, ".") == agent.DataDir
+}
+
+func (d RecordingDefinition) content() (string, error) {
+	if d.File == "" {
+		return d.Content, nil
+	}
+	b, err := ioutil.ReadFile(d.File)
+	if err != nil {
+		return "", errors.Wrap(err, "error while reading file")
+	}
+	return string(b), nil
+}
+
+func (l Agents) ids() []int {
+	var ids []int
+	for _, agent := range l {
+		ids = append(ids, agent.ID)
+	}
+	return ids
+}
+
+func (l Engines) ids() []int {
+	var ids []int
+	for _, engine := range l {
+		ids = append(ids, engine.ID)
+	}
+	return ids
+}
+
+func (l Endpoints) ids() []int {
+	var ids []int
+	for _, endpoint := range l {
+		ids = append(ids, endpoint.ID)
+	}
+	return ids
+}
+
+func (l Users) ids() []int {
+	var ids []int
+	for _, user := range l {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(list []int, i int) bool {
+	for _, e := range list {
+		if e == i {
+			return true
+		}
+	}
+	return false
+}
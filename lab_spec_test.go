@@ -0,0 +1,192 @@
+package snmpsimclient
+
+import (
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+const testLabSpecYAML = `
+labs:
+  - name: test-LabSpec-lab1
+    power: false
+    agents: [test-LabSpec-agent1]
+agents:
+  - name: test-LabSpec-agent1
+    data_dir: test-LabSpec-agent1
+    engines: [test-LabSpec-engine1]
+engines:
+  - name: test-LabSpec-engine1
+    engine_id: "010203040507080C00000000"
+    endpoints: [test-LabSpec-endpoint1]
+    users: [test-LabSpec-user1]
+endpoints:
+  - name: test-LabSpec-endpoint1
+    address: 127.0.0.1:1161
+    protocol: udpv4
+users:
+  - name: test-LabSpec-user1
+    user: test-LabSpec-user1
+recordings:
+  - path: test-LabSpec-agent1/public.snmprec
+    content: "1.3.6.1.2.1.1.1.0|4|test\n"
+`
+
+func TestParseLabSpecYAML(t *testing.T) {
+	spec, err := ParseLabSpecYAML([]byte(testLabSpecYAML))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	assert.Len(t, spec.Labs, 1)
+	assert.Equal(t, []string{"test-LabSpec-engine1"}, spec.Agents[0].Engines)
+	assert.Equal(t, "010203040507080C00000000", spec.Engines[0].EngineID)
+	assert.Equal(t, "127.0.0.1:1161", spec.Endpoints[0].Address)
+	assert.Equal(t, "test-LabSpec-agent1/public.snmprec", spec.Recordings[0].Path)
+}
+
+func TestParseLabSpecJSON(t *testing.T) {
+	spec, err := ParseLabSpecJSON([]byte(`{"labs": [{"name": "lab1", "power": true}]}`))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	assert.True(t, spec.Labs[0].Power)
+
+	_, err = ParseLabSpecJSON([]byte(`{"labs": [{"name": "lab1", "powr": true}]}`))
+	assert.Error(t, err, "no error for an unknown key")
+	_, err = ParseLabSpecJSON([]byte(`{"labs": []} {"labs": []}`))
+	assert.Error(t, err, "no error for data after the lab spec")
+}
+
+func TestLabSpec_Validate(t *testing.T) {
+	_, err := ParseLabSpecYAML([]byte("labs:\n  - name: lab1\n    agents: [unknown]\n"))
+	assert.Error(t, err, "no error for a reference to an unknown agent")
+
+	_, err = ParseLabSpecYAML([]byte("users:\n  - name: user1\n    user: user1\n  - name: user1\n    user: user2\n"))
+	assert.Error(t, err, "no error for duplicate user names")
+
+	_, err = ParseLabSpecYAML([]byte("engines:\n  - name: engine1\n    engine_id: \"0102\"\n"))
+	assert.Error(t, err, "no error for an invalid engine id")
+
+	_, err = ParseLabSpecYAML([]byte("recordings:\n  - path: public.txt\n"))
+	assert.Error(t, err, "no error for a recording which is not an snmprec file")
+
+	_, err = ParseLabSpecYAML([]byte("lab:\n  - name: lab1\n"))
+	assert.Error(t, err, "no error for an unknown key")
+}
+
+func TestManagementClient_ApplyLabSpec(t *testing.T) {
+	spec, err := ParseLabSpecYAML([]byte(testLabSpecYAML))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	spec.Agents[0].DataDir = configManagementTest.RootDataDir + spec.Agents[0].DataDir
+	spec.Recordings[0].Path = configManagementTest.RootDataDir + spec.Recordings[0].Path
+	spec.Endpoints[0].Address = configManagementTest.Agent1.EndpointAddress + ":" + strconv.Itoa(configManagementTest.Agent1.EndpointPort[0])
+
+	//Create a new api client
+	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	//Set configManagementTest.HTTP.AuthUsername and password
+	if configManagementTest.HTTP.AuthUsername != "" && configManagementTest.HTTP.AuthPassword != "" {
+		err = client.SetUsernameAndPassword(configManagementTest.HTTP.AuthUsername, configManagementTest.HTTP.AuthPassword)
+		if !assert.NoError(t, err, "error while creating a new api client") {
+			return
+		}
+	}
+
+	plan, err := client.Apply(spec)
+	if !assert.NoError(t, err, "error during Apply()") {
+		_ = client.Destroy(spec)
+		return
+	}
+	defer func() {
+		assert.NoError(t, client.Destroy(spec), "error during Destroy()")
+		labs, err := client.GetLabs(map[string]string{"name": spec.Labs[0].Name})
+		if assert.NoError(t, err, "error during GetLabs()") {
+			assert.Len(t, labs, 0, "destroyed lab was found in list of labs")
+		}
+	}()
+	assert.False(t, plan.Empty(), "plan of first Apply() is empty")
+
+	labs, err := client.GetLabs(map[string]string{"name": spec.Labs[0].Name})
+	if !assert.NoError(t, err, "error during GetLabs()") || !assert.Len(t, labs, 1) {
+		return
+	}
+	lab, err := client.GetLab(labs[0].ID)
+	if !assert.NoError(t, err, "error during GetLab()") || !assert.Len(t, lab.Agents, 1) {
+		return
+	}
+	agent, err := client.GetAgent(lab.Agents[0].ID)
+	if !assert.NoError(t, err, "error during GetAgent()") || !assert.Len(t, agent.Engines, 1) {
+		return
+	}
+	engine, err := client.GetEngine(agent.Engines[0].ID)
+	if assert.NoError(t, err, "error during GetEngine()") {
+		assert.Len(t, engine.Users, 1, "user was not added to engine")
+		assert.Len(t, engine.Endpoints, 1, "endpoint was not added to engine")
+	}
+
+	//a second apply must not change anything
+	plan, err = client.Plan(spec)
+	if assert.NoError(t, err, "error during Plan()") {
+		assert.True(t, plan.Empty(), "plan after Apply() is not empty: %v", plan)
+	}
+
+	//removing the user from the spec unlinks it from the engine
+	spec.Engines[0].Users = nil
+	plan, err = client.Plan(spec)
+	if assert.NoError(t, err, "error during Plan()") && assert.Len(t, plan, 1) {
+		assert.Equal(t, PlanActionUnlink, plan[0].Type)
+		assert.Equal(t, "test-LabSpec-user1", plan[0].Name)
+	}
+}
+
+func TestManagementClient_ApplyLabSpec_RecreateParentAndChild(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	spec, err := ParseLabSpecYAML([]byte(testLabSpecYAML))
+	if !assert.NoError(t, err, "error while parsing lab spec") {
+		return
+	}
+	_, err = client.Apply(spec)
+	if !assert.NoError(t, err, "error during first Apply()") {
+		return
+	}
+
+	//the user and the engine it is linked to differ from the live state, both are recreated
+	spec.Users[0].AuthKey = "authkey2"
+	spec.Users[0].AuthProto = "md5"
+	spec.Engines[0].EngineID = "010203040507080D00000000"
+	plan, err := client.Apply(spec)
+	if !assert.NoError(t, err, "error during second Apply()") {
+		return
+	}
+	unlinks := 0
+	for _, action := range plan {
+		if action.Type == PlanActionUnlink && action.ObjectType == objectTypeUser {
+			unlinks++
+		}
+	}
+	assert.Equal(t, 1, unlinks, "user was unlinked more than once: %v", plan)
+
+	engines, err := client.GetEngines(EngineFilter{Name: "test-LabSpec-engine1"}.Params())
+	if assert.NoError(t, err, "error during GetEngines()") && assert.Len(t, engines, 1) {
+		engine, err := client.GetEngine(engines[0].ID)
+		if assert.NoError(t, err, "error during GetEngine()") && assert.Len(t, engine.Users, 1) {
+			assert.Equal(t, "010203040507080D00000000", engine.EngineID)
+			assert.Equal(t, "authkey2", engine.Users[0].AuthKey)
+		}
+	}
+	plan, err = client.Plan(spec)
+	if assert.NoError(t, err, "error during Plan()") {
+		assert.True(t, plan.Empty(), "plan after Apply() is not empty: %v", plan)
+	}
+}
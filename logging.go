@@ -0,0 +1,177 @@
+package snmpsimclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Logger receives a log entry for every request sent by a client, see SetLogger.
+The keys and values alternate like in log/slog and logr, so both a *slog.Logger and a logr.Logger can be used directly.
+*/
+type Logger interface {
+	Info(msg string, keysAndValues ...interface{})
+}
+
+/*
+LoggerFunc is an adapter to use a func as Logger.
+*/
+type LoggerFunc func(msg string, keysAndValues ...interface{})
+
+/*
+Info calls f.
+*/
+func (f LoggerFunc) Info(msg string, keysAndValues ...interface{}) {
+	f(msg, keysAndValues...)
+}
+
+/*
+NewWriterLogger returns a Logger which writes every entry as a line of key=value pairs to w, e.g. to dump requests to os.Stderr while debugging.
+*/
+func NewWriterLogger(w io.Writer) Logger {
+	var mutex sync.Mutex
+	return LoggerFunc(func(msg string, keysAndValues ...interface{}) {
+		line := strconv.Quote(msg)
+		for i := 0; i+1 < len(keysAndValues); i += 2 {
+			line += fmt.Sprintf(" %v=%s", keysAndValues[i], formatLogValue(keysAndValues[i+1]))
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		_, _ = io.WriteString(w, line+"\n")
+	})
+}
+
+func formatLogValue(value interface{}) string {
+	s := fmt.Sprint(value)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+/*
+SetLogger sets a logger which receives the method, path, query parameters, status and latency of every request, nil disables logging.
+*/
+func (c *client) SetLogger(logger Logger) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	c.logger = logger
+	return nil
+}
+
+/*
+SetBodyLogging enables or disables logging of the request and response headers and bodies.
+Credentials like the authorization header and the auth and priv keys of users are masked.
+*/
+func (c *client) SetBodyLogging(enabled bool) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	c.logBodies = enabled
+	return nil
+}
+
+// redacted replaces sensitive values in logs.
+const redacted = "***"
+
+// sensitiveFields are the json fields which are masked in logged bodies.
+var sensitiveFields = map[string]bool{
+	"auth_key": true,
+	"priv_key": true,
+	"password": true,
+	"token":    true,
+}
+
+// logRequest logs a single attempt of a request.
+func (c *client) logRequest(method, path string, queryParams map[string]string, requestHeader http.Header, body string, started time.Time, status int, responseBody []byte, err error) {
+	keysAndValues := []interface{}{
+		"method", method,
+		"path", urlEscapePath(path),
+	}
+	if len(queryParams) > 0 {
+		values := url.Values{}
+		for key, value := range queryParams {
+			values.Set(key, value)
+		}
+		keysAndValues = append(keysAndValues, "query", values.Encode())
+	}
+	if status != 0 {
+		keysAndValues = append(keysAndValues, "status", status)
+	}
+	keysAndValues = append(keysAndValues, "latency", time.Since(started))
+	if err != nil {
+		keysAndValues = append(keysAndValues, "error", err.Error())
+	}
+	if c.logBodies {
+		keysAndValues = append(keysAndValues, "request_header", redactHeader(requestHeader))
+		if body != "" {
+			keysAndValues = append(keysAndValues, "request_body", redactBody([]byte(body)))
+		}
+		if len(responseBody) > 0 {
+			keysAndValues = append(keysAndValues, "response_body", redactBody(responseBody))
+		}
+	}
+	c.logger.Info("snmpsim api request", keysAndValues...)
+}
+
+// redactHeader formats a header, values of headers which may contain credentials are masked.
+func redactHeader(header http.Header) string {
+	var keys []string
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var fields []string
+	for _, key := range keys {
+		value := strings.Join(header[key], ",")
+		lower := strings.ToLower(key)
+		if strings.Contains(lower, "auth") || strings.Contains(lower, "key") || strings.Contains(lower, "token") || strings.Contains(lower, "secret") {
+			value = redacted
+		}
+		fields = append(fields, key+": "+value)
+	}
+	return strings.Join(fields, "; ")
+}
+
+// redactBody masks sensitive fields of json bodies, other bodies like record files are only logged with their size.
+func redactBody(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "<" + strconv.Itoa(len(body)) + " bytes>"
+	}
+	value = redactValue(value)
+	redactedBody, err := json.Marshal(value)
+	if err != nil {
+		return "<" + strconv.Itoa(len(body)) + " bytes>"
+	}
+	return string(redactedBody)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				if field != nil && field != "" {
+					v[key] = redacted
+				}
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = redactValue(element)
+		}
+	}
+	return value
+}
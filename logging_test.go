@@ -0,0 +1,93 @@
+package snmpsimclient
+
+import (
+	"bytes"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type logEntry struct {
+	msg    string
+	fields map[string]interface{}
+}
+
+func newRecordingLogger(entries *[]logEntry) Logger {
+	return LoggerFunc(func(msg string, keysAndValues ...interface{}) {
+		fields := make(map[string]interface{})
+		for i := 0; i+1 < len(keysAndValues); i += 2 {
+			fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+		}
+		*entries = append(*entries, logEntry{msg: msg, fields: fields})
+	})
+}
+
+func TestClient_Logger(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	var entries []logEntry
+	assert.NoError(t, client.SetLogger(newRecordingLogger(&entries)))
+
+	_, err = client.GetLabs(LabFilter{Name: "my lab"}.Params())
+	if assert.NoError(t, err, "error during GetLabs") && assert.Len(t, entries, 1) {
+		fields := entries[0].fields
+		assert.Equal(t, "GET", fields["method"])
+		assert.Equal(t, "snmpsim/mgmt/v1/labs", fields["path"])
+		assert.Equal(t, "name=my+lab", fields["query"])
+		assert.Equal(t, 200, fields["status"])
+		assert.IsType(t, time.Duration(0), fields["latency"])
+		assert.NotContains(t, fields, "request_body", "body logged without body logging")
+	}
+
+	_, err = client.GetLab(1234)
+	if assert.Error(t, err, "no error for missing lab") && assert.Len(t, entries, 2) {
+		assert.Equal(t, 404, entries[1].fields["status"])
+	}
+
+	assert.NoError(t, client.SetBodyLogging(true))
+	assert.NoError(t, client.SetUsernameAndPassword("user", "secretPassword"))
+	entries = nil
+	_, err = client.CreateUser("simulator", "simulator", "secretAuthKey", "md5", "secretPrivKey", "des")
+	if assert.NoError(t, err, "error during CreateUser") && assert.Len(t, entries, 1) {
+		fields := entries[0].fields
+		assert.Equal(t, "POST", fields["method"])
+		assert.Equal(t, 201, fields["status"])
+		for _, key := range []string{"request_header", "request_body", "response_body"} {
+			if assert.Contains(t, fields, key) {
+				assert.NotContains(t, fields[key], "secret", key+" is not redacted")
+			}
+		}
+		assert.Contains(t, fields["request_body"], `"auth_key":"***"`)
+		assert.Contains(t, fields["request_body"], `"user":"simulator"`)
+		assert.Contains(t, fields["request_header"], "Authorization: ***")
+	}
+
+	assert.NoError(t, client.SetLogger(nil))
+	entries = nil
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "error during GetLabs")
+	assert.Empty(t, entries, "logged without logger")
+}
+
+func TestNewWriterLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := NewWriterLogger(&buffer)
+	logger.Info("snmpsim api request", "method", "GET", "query", "name=my lab", "status", 200)
+	assert.Equal(t, `"snmpsim api request" method=GET query="name=my lab" status=200`+"\n", buffer.String())
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, `[{"auth_key":"***","name":"user","priv_key":null}]`, redactBody([]byte(`[{"auth_key": "key", "name": "user", "priv_key": null}]`)))
+	assert.Equal(t, "<13 bytes>", redactBody([]byte("1.3.6.1|4|abc")))
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("X-API-Key", "secret")
+	assert.Equal(t, "Content-Type: application/json; X-Api-Key: ***", redactHeader(header))
+}
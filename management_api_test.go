@@ -1,6 +1,8 @@
 package snmpsimclient
 
 import (
+	"github.com/inexio/snmpsim-restapi-go-client/snmprec"
+	"github.com/pkg/errors"
 	"github.com/soniah/gosnmp"
 	"github.com/stretchr/testify/assert"
 	"strconv"
@@ -26,7 +28,7 @@ func TestManagementClient_buildUpSetupAndTestIt(t *testing.T) {
 	privProto1 := "des"
 	//engine
 	engineName1 := "test-buildUpSetupAndTestIt-engine1"
-	engineID1 := "0102030405070809"
+	engineID1 := "010203040507080900000000"
 	//Record File:
 	localRecordFilePath1 := configManagementTest.TestDataDir + "snmprecs/TestManagementClient_buildUpSetupAndTestIt/agent1/" + community + ".snmprec"
 	remoteRecordFilePath1 := agentDataDir1 + "/" + community + ".snmprec"
@@ -43,7 +45,7 @@ func TestManagementClient_buildUpSetupAndTestIt(t *testing.T) {
 	userIdentifier2 := "test-buildUpSetupAndTestIt-user2"
 	//Engine
 	engineName2 := "test-buildUpSetupAndTestIt-engine2"
-	engineID2 := "010203040507080A"
+	engineID2 := "010203040507080A00000000"
 	//Record File
 	localRecordFilePath2 := configManagementTest.TestDataDir + "snmprecs/TestManagementClient_buildUpSetupAndTestIt/agent2/" + community + ".snmprec"
 	remoteRecordFilePath2 := agentDataDir2 + "/" + community + ".snmprec"
@@ -75,14 +77,8 @@ func TestManagementClient_buildUpSetupAndTestIt(t *testing.T) {
 	//Record file agent 1
 	//TODO: remove this when its possible to overwrite files
 	err = client.DeleteRecordFile(remoteRecordFilePath1)
-	if err != nil {
-		if err, ok := err.(HTTPError); assert.True(t, ok, "unknown error returned while deleting record file") {
-			if !assert.True(t, err.StatusCode == 404, "http error code for deleting record file is not 404! error: "+err.Error()) {
-				return
-			}
-		} else {
-			return
-		}
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "http error code for deleting record file is not 404! error: "+err.Error()) {
+		return
 	}
 
 	err = uploadRecordFileAndCheckForSuccess(t, client, localRecordFilePath1, remoteRecordFilePath1)
@@ -98,14 +94,8 @@ func TestManagementClient_buildUpSetupAndTestIt(t *testing.T) {
 	//Record file agent 2
 	//TODO: remove this when its possible to overwrite files
 	err = client.DeleteRecordFile(remoteRecordFilePath2)
-	if err != nil {
-		if err, ok := err.(HTTPError); assert.True(t, ok, "unknown error returned while deleting record file") {
-			if !assert.True(t, err.StatusCode == 404, "http error code for deleting record file is not 404! error: "+err.Error()) {
-				return
-			}
-		} else {
-			return
-		}
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "http error code for deleting record file is not 404! error: "+err.Error()) {
+		return
 	}
 
 	err = uploadRecordFileAndCheckForSuccess(t, client, localRecordFilePath2, remoteRecordFilePath2)
@@ -317,6 +307,11 @@ func TestManagementClient_buildUpSetupAndTestIt(t *testing.T) {
 		  SNMP Requests
 	  --------------------*/
 
+	//the fake server does not respond to snmp requests
+	if configManagementTest.FakeServer {
+		return
+	}
+
 	//SNMP Request Agent 1 SNMPv3
 
 	agent1Snmpv3 := &gosnmp.GoSNMP{
@@ -440,24 +435,12 @@ func TestManagementClient_UploadRecordFileString_DeleteRecordFile(t *testing.T)
 
 	//init cleanup
 	err = client.DeleteRecordFile(remotePathFile1)
-	if err != nil {
-		if err, ok := err.(HTTPError); assert.True(t, ok, "error while initial delete is not a http error"+err.Error()) {
-			if !assert.True(t, err.StatusCode == 404, "cleanup delete error != 404") {
-				return
-			}
-		} else {
-			return
-		}
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "cleanup delete error != 404") {
+		return
 	}
 	err = client.DeleteRecordFile(remotePathFile2)
-	if err != nil {
-		if err, ok := err.(HTTPError); assert.True(t, ok, "error while initial delete is not a http error"+err.Error()) {
-			if !assert.True(t, err.StatusCode == 404, "cleanup delete error != 404") {
-				return
-			}
-		} else {
-			return
-		}
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "cleanup delete error != 404") {
+		return
 	}
 
 	err = uploadRecordFileStringAndCheckForSuccess(t, client, &fileContent, remotePathFile1)
@@ -493,6 +476,133 @@ func TestManagementClient_UploadRecordFileString_DeleteRecordFile(t *testing.T)
 	*/
 }
 
+func TestManagementClient_UploadRecords_GetRecords(t *testing.T) {
+	remotePath := configManagementTest.RootDataDir + "test-UploadRecords_GetRecords/public.snmprec"
+
+	records := snmprec.Records{
+		snmprec.NewTimeTicks("1.3.6.1.2.1.1.3.0", 123999999),
+		snmprec.NewOctetString("1.3.6.1.2.1.1.1.0", []byte("test-UploadRecords")),
+		snmprec.NewObjectIdentifier("1.3.6.1.2.1.1.2.0", "1.3.6.1.4.1.8072.3.2.10"),
+	}
+
+	//Create a new api client
+	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	//Set configManagementTest.HTTP.AuthUsername and password
+	if configManagementTest.HTTP.AuthUsername != "" && configManagementTest.HTTP.AuthPassword != "" {
+		err = client.SetUsernameAndPassword(configManagementTest.HTTP.AuthUsername, configManagementTest.HTTP.AuthPassword)
+		if !assert.NoError(t, err, "error while creating a new api client") {
+			return
+		}
+	}
+
+	//init cleanup
+	err = client.DeleteRecordFile(remotePath)
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "cleanup delete error != 404") {
+		return
+	}
+
+	err = client.UploadRecords(records, remotePath)
+	if !assert.NoError(t, err, "error during UploadRecords") {
+		return
+	}
+	defer func() {
+		err = deleteRecordFileAndCheckForSuccess(t, client, remotePath)
+		assert.NoError(t, err, "error while deleting record file")
+	}()
+
+	uploaded, err := client.GetRecords(remotePath)
+	if assert.NoError(t, err, "error during GetRecords") && assert.Len(t, uploaded, 3) {
+		assert.Equal(t, records[1], uploaded[0], "records were not sorted before upload")
+		assert.Equal(t, records[2], uploaded[1], "records were not sorted before upload")
+		assert.Equal(t, records[0], uploaded[2], "records were not sorted before upload")
+	}
+	assert.Equal(t, snmprec.TimeTicks, records[0].Type, "records passed to UploadRecords were modified")
+
+	//upload invalid record
+	err = client.UploadRecords(snmprec.Records{{OID: "1.3.6.1.2.1.1.1.0", Type: snmprec.Integer, Value: "abc"}}, remotePath)
+	assert.Error(t, err, "no error when uploading invalid record")
+}
+
+func TestManagementClient_UploadSnmpwalk(t *testing.T) {
+	remotePath := configManagementTest.RootDataDir + "test-UploadSnmpwalk/public.snmprec"
+	snmpwalkOutput := `.1.3.6.1.2.1.1.3.0 = Timeticks: (123999999) 14 days, 8:26:39.99
+.1.3.6.1.2.1.1.1.0 = STRING: "test-UploadSnmpwalk"
+`
+
+	//Create a new api client
+	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	//Set configManagementTest.HTTP.AuthUsername and password
+	if configManagementTest.HTTP.AuthUsername != "" && configManagementTest.HTTP.AuthPassword != "" {
+		err = client.SetUsernameAndPassword(configManagementTest.HTTP.AuthUsername, configManagementTest.HTTP.AuthPassword)
+		if !assert.NoError(t, err, "error while creating a new api client") {
+			return
+		}
+	}
+
+	//init cleanup
+	err = client.DeleteRecordFile(remotePath)
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "cleanup delete error != 404") {
+		return
+	}
+
+	err = client.UploadSnmpwalk(snmpwalkOutput, remotePath)
+	if !assert.NoError(t, err, "error during UploadSnmpwalk") {
+		return
+	}
+	defer func() {
+		err = deleteRecordFileAndCheckForSuccess(t, client, remotePath)
+		assert.NoError(t, err, "error while deleting record file")
+	}()
+
+	recordFile, err := client.GetRecordFile(remotePath)
+	if assert.NoError(t, err, "error during GetRecordFile") {
+		assert.Equal(t, "1.3.6.1.2.1.1.1.0|4|test-UploadSnmpwalk\n1.3.6.1.2.1.1.3.0|67|123999999\n", recordFile)
+	}
+}
+
+func TestManagementClient_RecordFileValidation(t *testing.T) {
+	remotePath := configManagementTest.RootDataDir + "test-RecordFileValidation/public.snmprec"
+	invalidContent := "1.3.6.1.2.1.1.2.0|4|second\n1.3.6.1.2.1.1.1.0|2|first"
+
+	//Create a new api client
+	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	//Set configManagementTest.HTTP.AuthUsername and password
+	if configManagementTest.HTTP.AuthUsername != "" && configManagementTest.HTTP.AuthPassword != "" {
+		err = client.SetUsernameAndPassword(configManagementTest.HTTP.AuthUsername, configManagementTest.HTTP.AuthPassword)
+		if !assert.NoError(t, err, "error while creating a new api client") {
+			return
+		}
+	}
+	err = client.SetRecordFileValidation(true)
+	if !assert.NoError(t, err, "error while enabling record file validation") {
+		return
+	}
+
+	err = client.UploadRecordFileString(&invalidContent, remotePath)
+	if assert.Error(t, err, "no error when uploading an invalid record file") {
+		var validationError *RecordFileValidationError
+		if assert.True(t, errors.As(err, &validationError), "error is not a record file validation error") {
+			assert.Equal(t, remotePath, validationError.RemotePath)
+			if assert.Len(t, validationError.Problems, 2) {
+				assert.Equal(t, 2, validationError.Problems[0].Line)
+				assert.Equal(t, 2, validationError.Problems[1].Line)
+			}
+		}
+	}
+
+	_, err = client.GetRecordFile(remotePath)
+	assert.True(t, errors.Is(err, ErrNotFound), "invalid record file was uploaded")
+}
+
 func TestManagementClient_Tags(t *testing.T) {
 	//Create a new api client
 	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
@@ -567,7 +677,7 @@ func TestManagementClient_Tags(t *testing.T) {
 	}()
 
 	//engine
-	engine, err := createEngineAndCheckForSuccess(t, client, "TestManagementClient_Tags", "010203040507080E")
+	engine, err := createEngineAndCheckForSuccess(t, client, "TestManagementClient_Tags", "010203040507080E00000000")
 	if err != nil {
 		return
 	}
@@ -600,6 +710,128 @@ func TestManagementClient_Tags(t *testing.T) {
 	}()
 }
 
+func TestManagementClient_Update(t *testing.T) {
+	//Create a new api client
+	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	//Set configManagementTest.HTTP.AuthUsername and password
+	if configManagementTest.HTTP.AuthUsername != "" && configManagementTest.HTTP.AuthPassword != "" {
+		err = client.SetUsernameAndPassword(configManagementTest.HTTP.AuthUsername, configManagementTest.HTTP.AuthPassword)
+		if !assert.NoError(t, err, "error while creating a new api client") {
+			return
+		}
+	}
+
+	//lab
+	lab, err := createLabAndCheckForSuccess(t, client, "TestManagementClient_Update")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = deleteLabAndCheckForSuccess(t, client, lab)
+	}()
+	labName := "TestManagementClient_Update_renamed"
+	updatedLab, err := client.UpdateLab(lab.ID, LabUpdate{Name: &labName})
+	if assert.NoError(t, err, "error during update lab") {
+		assert.Equal(t, labName, updatedLab.Name)
+		assert.Equal(t, lab.Power, updatedLab.Power, "power changed by update")
+	}
+	_, err = client.UpdateLab(lab.ID, LabUpdate{})
+	assert.Error(t, err, "no error when updating no fields")
+
+	//agent and engine, the link between them has to survive the update
+	agent, err := createAgentAndCheckForSuccess(t, client, "TestManagementClient_Update", "TestManagementClient_Update")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = deleteAgentAndCheckForSuccess(t, client, agent)
+	}()
+	engine, err := createEngineAndCheckForSuccess(t, client, "TestManagementClient_Update", "010203040507080F00000000")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = deleteEngineAndCheckForSuccess(t, client, engine)
+	}()
+	err = addEngineToAgentAndCheckForSuccess(t, client, agent, engine)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = removeEngineFromAgentAndCheckForSuccess(t, client, agent, engine)
+	}()
+
+	dataDir := "TestManagementClient_Update_moved"
+	updatedAgent, err := client.UpdateAgent(agent.ID, AgentUpdate{DataDir: &dataDir})
+	if assert.NoError(t, err, "error during update agent") {
+		assert.Equal(t, dataDir, updatedAgent.DataDir)
+		assert.Equal(t, agent.Name, updatedAgent.Name, "name changed by update")
+		if assert.Len(t, updatedAgent.Engines, 1, "engine link lost by update") {
+			assert.Equal(t, engine.ID, updatedAgent.Engines[0].ID)
+		}
+	}
+	engineID := "010203040507081000000000"
+	updatedEngine, err := client.UpdateEngine(engine.ID, EngineUpdate{EngineID: &engineID})
+	if assert.NoError(t, err, "error during update engine") {
+		assert.Equal(t, engineID, updatedEngine.EngineID)
+	}
+
+	//endpoint
+	endpoint, err := createEndpointAndCheckForSuccess(t, client, "TestManagementClient_Update", configManagementTest.Agent1.EndpointAddress+":"+strconv.Itoa(configManagementTest.Agent1.EndpointPort[0]), configManagementTest.Protocol)
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = deleteEndpointAndCheckForSuccess(t, client, endpoint)
+	}()
+	address := configManagementTest.Agent1.EndpointAddress + ":" + strconv.Itoa(configManagementTest.Agent1.EndpointPort[0]+1)
+	updatedEndpoint, err := client.UpdateEndpoint(endpoint.ID, EndpointUpdate{Address: &address})
+	if assert.NoError(t, err, "error during update endpoint") {
+		assert.Equal(t, address, updatedEndpoint.Address)
+		assert.Equal(t, endpoint.Protocol, updatedEndpoint.Protocol)
+	}
+	invalidAddress := "no address"
+	_, err = client.UpdateEndpoint(endpoint.ID, EndpointUpdate{Address: &invalidAddress})
+	assert.Error(t, err, "no error when updating an endpoint with an invalid address")
+
+	//user
+	user, err := createUserAndCheckForSuccess(t, client, "TestManagementClient_Update", "TestManagementClient_Update", "authkey1", "sha", "privkey1", "des")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = deleteUserAndCheckForSuccess(t, client, user)
+	}()
+	authKey, privKey := "authkey2", "privkey2"
+	updatedUser, err := client.UpdateUser(user.ID, UserUpdate{AuthKey: &authKey, PrivKey: &privKey})
+	if assert.NoError(t, err, "error during update user") {
+		assert.Equal(t, authKey, updatedUser.AuthKey)
+		assert.Equal(t, privKey, updatedUser.PrivKey)
+		assert.Equal(t, user.AuthProto, updatedUser.AuthProto)
+	}
+
+	//tag
+	tag, err := createTagAndCheckForSuccess(t, client, "TestManagementClient_Update", "TestManagementClient_Update")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = deleteTagAndCheckForSuccess(t, client, tag)
+	}()
+	description := ""
+	updatedTag, err := client.UpdateTag(tag.ID, TagUpdate{Description: &description})
+	if assert.NoError(t, err, "error during update tag") {
+		assert.Equal(t, "", updatedTag.Description, "empty description was not sent")
+		assert.Equal(t, tag.Name, updatedTag.Name)
+	}
+
+	_, err = client.UpdateTag(0, TagUpdate{Description: &description})
+	assert.Error(t, err, "no error when updating a tag which does not exist")
+}
+
 func TestManagementClient_Search(t *testing.T) {
 	//Create a new api client
 	client, err := NewManagementClient(configManagementTest.HTTP.BaseURL)
@@ -721,9 +953,9 @@ func TestManagementClient_Search(t *testing.T) {
 
 	//ENGINES
 	engineName1 := "TestManagementClient_Search_1"
-	engineID1 := "010203040507080C"
+	engineID1 := "010203040507080C00000000"
 	engineName2 := "TestManagementClient_Search_2"
-	engineID2 := "010203040507080D"
+	engineID2 := "010203040507080D00000000"
 	engine1, err := createEngineAndCheckForSuccess(t, client, engineName1, engineID1)
 	if err != nil {
 		return
@@ -948,7 +1180,7 @@ func TestManagementClient_Agent_Failures(t *testing.T) {
 	*/
 
 	//create valid engine
-	engine, err := createEngineAndCheckForSuccess(t, client, "test-Agent_Failures-engine1", "010203040507080B")
+	engine, err := createEngineAndCheckForSuccess(t, client, "test-Agent_Failures-engine1", "010203040507080B00000000")
 	if err != nil {
 		return
 	}
@@ -1141,16 +1373,9 @@ func TestManagementClient_Engine_Failures(t *testing.T) {
 		}
 	}
 
-	//TODO: this should cause an api error but does not
-	/*
-		//Create Engine with invalid params
-		_, err = client.CreateEngine("name", "this is not a valid engine id")
-		if assert.Error(t, err, "no error when an engine with an invalid engine id was created") {
-			if err, ok := err.(HTTPError); assert.True(t, ok, "error is not a http error", err.Error()) {
-				assert.True(t, err.StatusCode == 404, "error != 404")
-			}
-		}
-	*/
+	//Create Engine with invalid params, the api does not reject it so the client has to
+	_, err = client.CreateEngine("name", "this is not a valid engine id")
+	assert.Error(t, err, "no error when an engine with an invalid engine id was created")
 
 	//Get Invalid Engine
 	_, err = client.GetEngine(-1)
@@ -1169,7 +1394,7 @@ func TestManagementClient_Engine_Failures(t *testing.T) {
 	}
 
 	//create valid engine
-	engine, err := createEngineAndCheckForSuccess(t, client, "test-Engine_Failures-engine1", "010203040507080B")
+	engine, err := createEngineAndCheckForSuccess(t, client, "test-Engine_Failures-engine1", "010203040507080B00000000")
 	if err != nil {
 		return
 	}
@@ -1177,7 +1402,7 @@ func TestManagementClient_Engine_Failures(t *testing.T) {
 
 	//TODO: this should cause an api error but does not
 	/*
-		_, err = client.CreateEngine("test-Engine_Failures-engine1", "010203040507080B")
+		_, err = client.CreateEngine("test-Engine_Failures-engine1", "010203040507080B00000000")
 		if assert.Error(t, err, "no error when an engine was created twice") {
 			if err, ok := err.(HTTPError); assert.True(t, ok, "error is not a http error", err.Error()) {
 				assert.True(t, err.StatusCode == 404, "error != 404")
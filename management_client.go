@@ -1,8 +1,9 @@
 package snmpsimclient
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/go-resty/resty/v2"
+	"github.com/inexio/snmpsim-restapi-go-client/snmprec"
 	"github.com/pkg/errors"
 	"io/ioutil"
 	"strconv"
@@ -17,9 +18,9 @@ type ManagementClient struct {
 }
 
 /*
-NewManagementClient creates a new ManagementClient.
+NewManagementClient creates a new ManagementClient, the options configure the http connection, e.g. WithCACertFile.
 */
-func NewManagementClient(baseURL string) (*ManagementClient, error) {
+func NewManagementClient(baseURL string, opts ...ClientOption) (*ManagementClient, error) {
 	if baseURL == "" {
 		return nil, errors.New("invalid base url")
 	}
@@ -28,7 +29,11 @@ func NewManagementClient(baseURL string) (*ManagementClient, error) {
 	if lastChar := baseURL[len(baseURL)-1:]; lastChar != "/" {
 		baseURL += "/"
 	}
-	clientData := clientData{baseURL: baseURL, resty: resty.New(), useAuth: false}
+	restyClient, err := newResty(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid client option")
+	}
+	clientData := clientData{baseURL: baseURL, resty: restyClient}
 	newClient := client{&clientData}
 	return &ManagementClient{newClient}, nil
 }
@@ -39,13 +44,21 @@ LABS
 
 /*
 GetLabs returns a list of labs, optionally filtered.
+The filters can be created with LabFilter.Params().
 */
 func (c *ManagementClient) GetLabs(filter map[string]string) (Labs, error) {
+	return c.GetLabsCtx(context.Background(), filter)
+}
+
+/*
+GetLabsCtx is like GetLabs but uses the given context for the request.
+*/
+func (c *ManagementClient) GetLabsCtx(ctx context.Context, filter map[string]string) (Labs, error) {
 	if !c.isValid() {
 		return nil, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"labs", "", nil, filter)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"labs", "", nil, filter)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during search labs request")
 	}
@@ -65,11 +78,18 @@ func (c *ManagementClient) GetLabs(filter map[string]string) (Labs, error) {
 GetLab returns the lab with the given id.
 */
 func (c *ManagementClient) GetLab(id int) (Lab, error) {
+	return c.GetLabCtx(context.Background(), id)
+}
+
+/*
+GetLabCtx is like GetLab but uses the given context for the request.
+*/
+func (c *ManagementClient) GetLabCtx(ctx context.Context, id int) (Lab, error) {
 	if !c.isValid() {
 		return Lab{}, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"labs/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"labs/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return Lab{}, errors.Wrap(err, "error during get labs request")
 	}
@@ -89,17 +109,31 @@ func (c *ManagementClient) GetLab(id int) (Lab, error) {
 CreateLab creates a new lab.
 */
 func (c *ManagementClient) CreateLab(name string) (Lab, error) {
-	return c.createLab(&name, nil)
+	return c.CreateLabCtx(context.Background(), name)
+}
+
+/*
+CreateLabCtx is like CreateLab but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateLabCtx(ctx context.Context, name string) (Lab, error) {
+	return c.createLab(ctx, &name, nil)
 }
 
 /*
 CreateLabWithTag creates a new lab tagged with the given tag.
 */
 func (c *ManagementClient) CreateLabWithTag(name string, tagID int) (Lab, error) {
-	return c.createLab(&name, &tagID)
+	return c.CreateLabWithTagCtx(context.Background(), name, tagID)
 }
 
-func (c *ManagementClient) createLab(name *string, tagID *int) (Lab, error) {
+/*
+CreateLabWithTagCtx is like CreateLabWithTag but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateLabWithTagCtx(ctx context.Context, name string, tagID int) (Lab, error) {
+	return c.createLab(ctx, &name, &tagID)
+}
+
+func (c *ManagementClient) createLab(ctx context.Context, name *string, tagID *int) (Lab, error) {
 	if !c.isValid() {
 		return Lab{}, &NotValidError{}
 	}
@@ -123,7 +157,7 @@ func (c *ManagementClient) createLab(name *string, tagID *int) (Lab, error) {
 		path = mgmtEndpointPath + "tags/" + strconv.Itoa(*tagID) + "/lab"
 	}
 
-	response, err := c.request("POST", path, string(jsonString), nil, nil)
+	response, err := c.request(ctx, "POST", path, string(jsonString), nil, nil)
 
 	if err != nil {
 		return Lab{}, errors.Wrap(err, "error during add lab request")
@@ -144,11 +178,18 @@ func (c *ManagementClient) createLab(name *string, tagID *int) (Lab, error) {
 DeleteLab deletes the Lab with the given id.
 */
 func (c *ManagementClient) DeleteLab(id int) error {
+	return c.DeleteLabCtx(context.Background(), id)
+}
+
+/*
+DeleteLabCtx is like DeleteLab but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteLabCtx(ctx context.Context, id int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"labs/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"labs/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -158,15 +199,57 @@ func (c *ManagementClient) DeleteLab(id int) error {
 	return nil
 }
 
+/*
+LabUpdate contains the fields of a lab which are changed by UpdateLab, nil fields are left unchanged.
+*/
+type LabUpdate struct {
+	// Name is the name of the lab.
+	Name *string `json:"name,omitempty"`
+}
+
+/*
+UpdateLab changes the fields of a lab which are set in the update and returns the updated lab.
+Links to other objects are kept.
+*/
+func (c *ManagementClient) UpdateLab(id int, update LabUpdate) (Lab, error) {
+	return c.UpdateLabCtx(context.Background(), id, update)
+}
+
+/*
+UpdateLabCtx is like UpdateLab but uses the given context for the request.
+*/
+func (c *ManagementClient) UpdateLabCtx(ctx context.Context, id int, update LabUpdate) (Lab, error) {
+	if !c.isValid() {
+		return Lab{}, &NotValidError{}
+	}
+	if update.Name != nil && *update.Name == "" {
+		return Lab{}, errors.New("invalid name")
+	}
+
+	var lab Lab
+	err := c.update(ctx, "labs", id, update, &lab)
+	if err != nil {
+		return Lab{}, err
+	}
+	return lab, nil
+}
+
 /*
 AddAgentToLab adds an Agent to a Lab.
 */
 func (c *ManagementClient) AddAgentToLab(labID, agentID int) error {
+	return c.AddAgentToLabCtx(context.Background(), labID, agentID)
+}
+
+/*
+AddAgentToLabCtx is like AddAgentToLab but uses the given context for the request.
+*/
+func (c *ManagementClient) AddAgentToLabCtx(ctx context.Context, labID, agentID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("PUT", mgmtEndpointPath+"labs/"+strconv.Itoa(labID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"labs/"+strconv.Itoa(labID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -181,11 +264,18 @@ func (c *ManagementClient) AddAgentToLab(labID, agentID int) error {
 RemoveAgentFromLab removes an Agent from a Lab.
 */
 func (c *ManagementClient) RemoveAgentFromLab(labID, agentID int) error {
+	return c.RemoveAgentFromLabCtx(context.Background(), labID, agentID)
+}
+
+/*
+RemoveAgentFromLabCtx is like RemoveAgentFromLab but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveAgentFromLabCtx(ctx context.Context, labID, agentID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"labs/"+strconv.Itoa(labID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"labs/"+strconv.Itoa(labID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -200,6 +290,13 @@ func (c *ManagementClient) RemoveAgentFromLab(labID, agentID int) error {
 SetLabPower activates or deactivates a lab.
 */
 func (c *ManagementClient) SetLabPower(labID int, power bool) error {
+	return c.SetLabPowerCtx(context.Background(), labID, power)
+}
+
+/*
+SetLabPowerCtx is like SetLabPower but uses the given context for the request.
+*/
+func (c *ManagementClient) SetLabPowerCtx(ctx context.Context, labID int, power bool) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
@@ -212,7 +309,7 @@ func (c *ManagementClient) SetLabPower(labID int, power bool) error {
 		labPowerState = "off"
 	}
 
-	response, err := c.request("PUT", mgmtEndpointPath+"labs/"+strconv.Itoa(labID)+"/power/"+labPowerState, "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"labs/"+strconv.Itoa(labID)+"/power/"+labPowerState, "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -228,10 +325,17 @@ func (c *ManagementClient) SetLabPower(labID int, power bool) error {
 AddTagToLab adds a tag to a lab.
 */
 func (c *ManagementClient) AddTagToLab(labID, tagID int) error {
+	return c.AddTagToLabCtx(context.Background(), labID, tagID)
+}
+
+/*
+AddTagToLabCtx is like AddTagToLab but uses the given context for the request.
+*/
+func (c *ManagementClient) AddTagToLabCtx(ctx context.Context, labID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/lab/"+strconv.Itoa(labID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/lab/"+strconv.Itoa(labID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -246,10 +350,17 @@ func (c *ManagementClient) AddTagToLab(labID, tagID int) error {
 RemoveTagFromLab removes a tag from a lab.
 */
 func (c *ManagementClient) RemoveTagFromLab(labID, tagID int) error {
+	return c.RemoveTagFromLabCtx(context.Background(), labID, tagID)
+}
+
+/*
+RemoveTagFromLabCtx is like RemoveTagFromLab but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveTagFromLabCtx(ctx context.Context, labID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/lab/"+strconv.Itoa(labID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/lab/"+strconv.Itoa(labID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -266,13 +377,21 @@ ENGINES
 
 /*
 GetEngines returns a list of all engines.
+The filters can be created with EngineFilter.Params().
 */
 func (c *ManagementClient) GetEngines(filter map[string]string) (Engines, error) {
+	return c.GetEnginesCtx(context.Background(), filter)
+}
+
+/*
+GetEnginesCtx is like GetEngines but uses the given context for the request.
+*/
+func (c *ManagementClient) GetEnginesCtx(ctx context.Context, filter map[string]string) (Engines, error) {
 	if !c.isValid() {
 		return nil, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"engines", "", nil, filter)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"engines", "", nil, filter)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during get engines request")
 	}
@@ -292,11 +411,18 @@ func (c *ManagementClient) GetEngines(filter map[string]string) (Engines, error)
 GetEngine returns the engine with the given id.
 */
 func (c *ManagementClient) GetEngine(id int) (Engine, error) {
+	return c.GetEngineCtx(context.Background(), id)
+}
+
+/*
+GetEngineCtx is like GetEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) GetEngineCtx(ctx context.Context, id int) (Engine, error) {
 	if !c.isValid() {
 		return Engine{}, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"engines/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"engines/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return Engine{}, errors.Wrap(err, "error during get labs request")
 	}
@@ -316,17 +442,31 @@ func (c *ManagementClient) GetEngine(id int) (Engine, error) {
 CreateEngine creates a new engine.
 */
 func (c *ManagementClient) CreateEngine(name, engineID string) (Engine, error) {
-	return c.createEngine(&name, &engineID, nil)
+	return c.CreateEngineCtx(context.Background(), name, engineID)
+}
+
+/*
+CreateEngineCtx is like CreateEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateEngineCtx(ctx context.Context, name, engineID string) (Engine, error) {
+	return c.createEngine(ctx, &name, &engineID, nil)
 }
 
 /*
 CreateEngineWithTag creates a new engine tagged with the given tag.
 */
 func (c *ManagementClient) CreateEngineWithTag(name, engineID string, tagID int) (Engine, error) {
-	return c.createEngine(&name, &engineID, &tagID)
+	return c.CreateEngineWithTagCtx(context.Background(), name, engineID, tagID)
+}
+
+/*
+CreateEngineWithTagCtx is like CreateEngineWithTag but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateEngineWithTagCtx(ctx context.Context, name, engineID string, tagID int) (Engine, error) {
+	return c.createEngine(ctx, &name, &engineID, &tagID)
 }
 
-func (c *ManagementClient) createEngine(name, engineID *string, tagID *int) (Engine, error) {
+func (c *ManagementClient) createEngine(ctx context.Context, name, engineID *string, tagID *int) (Engine, error) {
 	if !c.isValid() {
 		return Engine{}, &NotValidError{}
 	}
@@ -338,6 +478,10 @@ func (c *ManagementClient) createEngine(name, engineID *string, tagID *int) (Eng
 	if *engineID == "" {
 		*engineID = "auto"
 	}
+	err := ValidateEngineID(*engineID)
+	if err != nil {
+		return Engine{}, err
+	}
 
 	type requestParams struct {
 		Name     string `json:"name"`
@@ -355,7 +499,7 @@ func (c *ManagementClient) createEngine(name, engineID *string, tagID *int) (Eng
 		path = mgmtEndpointPath + "tags/" + strconv.Itoa(*tagID) + "/engine"
 	}
 
-	response, err := c.request("POST", path, string(jsonString), nil, nil)
+	response, err := c.request(ctx, "POST", path, string(jsonString), nil, nil)
 	if err != nil {
 		return Engine{}, errors.Wrap(err, "error during request")
 	}
@@ -374,11 +518,18 @@ func (c *ManagementClient) createEngine(name, engineID *string, tagID *int) (Eng
 DeleteEngine deletes the engine with the given id.
 */
 func (c *ManagementClient) DeleteEngine(id int) error {
+	return c.DeleteEngineCtx(context.Background(), id)
+}
+
+/*
+DeleteEngineCtx is like DeleteEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteEngineCtx(ctx context.Context, id int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"engines/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"engines/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -388,15 +539,65 @@ func (c *ManagementClient) DeleteEngine(id int) error {
 	return nil
 }
 
+/*
+EngineUpdate contains the fields of an engine which are changed by UpdateEngine, nil fields are left unchanged.
+*/
+type EngineUpdate struct {
+	// Name is the name of the engine.
+	Name *string `json:"name,omitempty"`
+	// EngineID is the SNMP engine id.
+	EngineID *string `json:"engine_id,omitempty"`
+}
+
+/*
+UpdateEngine changes the fields of an engine which are set in the update and returns the updated engine.
+Links to other objects are kept.
+*/
+func (c *ManagementClient) UpdateEngine(id int, update EngineUpdate) (Engine, error) {
+	return c.UpdateEngineCtx(context.Background(), id, update)
+}
+
+/*
+UpdateEngineCtx is like UpdateEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) UpdateEngineCtx(ctx context.Context, id int, update EngineUpdate) (Engine, error) {
+	if !c.isValid() {
+		return Engine{}, &NotValidError{}
+	}
+	if update.Name != nil && *update.Name == "" {
+		return Engine{}, errors.New("invalid name")
+	}
+	if update.EngineID != nil {
+		err := ValidateEngineID(*update.EngineID)
+		if err != nil {
+			return Engine{}, err
+		}
+	}
+
+	var engine Engine
+	err := c.update(ctx, "engines", id, update, &engine)
+	if err != nil {
+		return Engine{}, err
+	}
+	return engine, nil
+}
+
 /*
 AddUserToEngine adds an User to an Engine
 */
 func (c *ManagementClient) AddUserToEngine(engineID, userID int) error {
+	return c.AddUserToEngineCtx(context.Background(), engineID, userID)
+}
+
+/*
+AddUserToEngineCtx is like AddUserToEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) AddUserToEngineCtx(ctx context.Context, engineID, userID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("PUT", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -412,11 +613,18 @@ func (c *ManagementClient) AddUserToEngine(engineID, userID int) error {
 RemoveUserFromEngine removes an User from an Engine.
 */
 func (c *ManagementClient) RemoveUserFromEngine(engineID, userID int) error {
+	return c.RemoveUserFromEngineCtx(context.Background(), engineID, userID)
+}
+
+/*
+RemoveUserFromEngineCtx is like RemoveUserFromEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveUserFromEngineCtx(ctx context.Context, engineID, userID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -432,11 +640,18 @@ func (c *ManagementClient) RemoveUserFromEngine(engineID, userID int) error {
 AddEndpointToEngine adds an Endpoint to an Engine.
 */
 func (c *ManagementClient) AddEndpointToEngine(engineID, endpointID int) error {
+	return c.AddEndpointToEngineCtx(context.Background(), engineID, endpointID)
+}
+
+/*
+AddEndpointToEngineCtx is like AddEndpointToEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) AddEndpointToEngineCtx(ctx context.Context, engineID, endpointID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("PUT", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -452,11 +667,18 @@ func (c *ManagementClient) AddEndpointToEngine(engineID, endpointID int) error {
 RemoveEndpointFromEngine removes an Endpoint from an Engine.
 */
 func (c *ManagementClient) RemoveEndpointFromEngine(engineID, endpointID int) error {
+	return c.RemoveEndpointFromEngineCtx(context.Background(), engineID, endpointID)
+}
+
+/*
+RemoveEndpointFromEngineCtx is like RemoveEndpointFromEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveEndpointFromEngineCtx(ctx context.Context, engineID, endpointID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"engines/"+strconv.Itoa(engineID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -471,10 +693,17 @@ func (c *ManagementClient) RemoveEndpointFromEngine(engineID, endpointID int) er
 AddTagToEngine adds a tag to a engine.
 */
 func (c *ManagementClient) AddTagToEngine(engineID, tagID int) error {
+	return c.AddTagToEngineCtx(context.Background(), engineID, tagID)
+}
+
+/*
+AddTagToEngineCtx is like AddTagToEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) AddTagToEngineCtx(ctx context.Context, engineID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -489,10 +718,17 @@ func (c *ManagementClient) AddTagToEngine(engineID, tagID int) error {
 RemoveTagFromEngine removes a tag from a engine.
 */
 func (c *ManagementClient) RemoveTagFromEngine(engineID, tagID int) error {
+	return c.RemoveTagFromEngineCtx(context.Background(), engineID, tagID)
+}
+
+/*
+RemoveTagFromEngineCtx is like RemoveTagFromEngine but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveTagFromEngineCtx(ctx context.Context, engineID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -509,13 +745,21 @@ AGENTS
 
 /*
 GetAgents returns a list of agents, optionally filtered.
+The filters can be created with AgentFilter.Params().
 */
 func (c *ManagementClient) GetAgents(filters map[string]string) (Agents, error) {
+	return c.GetAgentsCtx(context.Background(), filters)
+}
+
+/*
+GetAgentsCtx is like GetAgents but uses the given context for the request.
+*/
+func (c *ManagementClient) GetAgentsCtx(ctx context.Context, filters map[string]string) (Agents, error) {
 	if !c.isValid() {
 		return nil, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"agents", "", nil, filters)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"agents", "", nil, filters)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during get agents request")
 	}
@@ -535,11 +779,18 @@ func (c *ManagementClient) GetAgents(filters map[string]string) (Agents, error)
 GetAgent returns the agent with the given id.
 */
 func (c *ManagementClient) GetAgent(id int) (Agent, error) {
+	return c.GetAgentCtx(context.Background(), id)
+}
+
+/*
+GetAgentCtx is like GetAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) GetAgentCtx(ctx context.Context, id int) (Agent, error) {
 	if !c.isValid() {
 		return Agent{}, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"agents/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"agents/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return Agent{}, errors.Wrap(err, "error during get labs request")
 	}
@@ -559,17 +810,31 @@ func (c *ManagementClient) GetAgent(id int) (Agent, error) {
 CreateAgent creates a new agent.
 */
 func (c *ManagementClient) CreateAgent(name, dataDir string) (Agent, error) {
-	return c.createAgent(&name, &dataDir, nil)
+	return c.CreateAgentCtx(context.Background(), name, dataDir)
+}
+
+/*
+CreateAgentCtx is like CreateAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateAgentCtx(ctx context.Context, name, dataDir string) (Agent, error) {
+	return c.createAgent(ctx, &name, &dataDir, nil)
 }
 
 /*
 CreateAgentWithTag creates a new agent tagged with the given tag.
 */
 func (c *ManagementClient) CreateAgentWithTag(name, dataDir string, tagID int) (Agent, error) {
-	return c.createAgent(&name, &dataDir, &tagID)
+	return c.CreateAgentWithTagCtx(context.Background(), name, dataDir, tagID)
 }
 
-func (c *ManagementClient) createAgent(name, dataDir *string, tagID *int) (Agent, error) {
+/*
+CreateAgentWithTagCtx is like CreateAgentWithTag but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateAgentWithTagCtx(ctx context.Context, name, dataDir string, tagID int) (Agent, error) {
+	return c.createAgent(ctx, &name, &dataDir, &tagID)
+}
+
+func (c *ManagementClient) createAgent(ctx context.Context, name, dataDir *string, tagID *int) (Agent, error) {
 	if !c.isValid() {
 		return Agent{}, &NotValidError{}
 	}
@@ -598,7 +863,7 @@ func (c *ManagementClient) createAgent(name, dataDir *string, tagID *int) (Agent
 		path = mgmtEndpointPath + "tags/" + strconv.Itoa(*tagID) + "/agent"
 	}
 
-	response, err := c.request("POST", path, string(jsonString), nil, nil)
+	response, err := c.request(ctx, "POST", path, string(jsonString), nil, nil)
 	if err != nil {
 		return Agent{}, errors.Wrap(err, "error during request")
 	}
@@ -618,11 +883,18 @@ func (c *ManagementClient) createAgent(name, dataDir *string, tagID *int) (Agent
 DeleteAgent deletes the agent with the given id.
 */
 func (c *ManagementClient) DeleteAgent(id int) error {
+	return c.DeleteAgentCtx(context.Background(), id)
+}
+
+/*
+DeleteAgentCtx is like DeleteAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteAgentCtx(ctx context.Context, id int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"agents/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"agents/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -632,15 +904,59 @@ func (c *ManagementClient) DeleteAgent(id int) error {
 	return nil
 }
 
+/*
+AgentUpdate contains the fields of an agent which are changed by UpdateAgent, nil fields are left unchanged.
+*/
+type AgentUpdate struct {
+	// Name is the name of the agent.
+	Name *string `json:"name,omitempty"`
+	// DataDir is the directory containing the record files of the agent.
+	DataDir *string `json:"data_dir,omitempty"`
+}
+
+/*
+UpdateAgent changes the fields of an agent which are set in the update and returns the updated agent.
+Links to other objects are kept.
+*/
+func (c *ManagementClient) UpdateAgent(id int, update AgentUpdate) (Agent, error) {
+	return c.UpdateAgentCtx(context.Background(), id, update)
+}
+
+/*
+UpdateAgentCtx is like UpdateAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) UpdateAgentCtx(ctx context.Context, id int, update AgentUpdate) (Agent, error) {
+	if !c.isValid() {
+		return Agent{}, &NotValidError{}
+	}
+	if update.Name != nil && *update.Name == "" {
+		return Agent{}, errors.New("invalid name")
+	}
+
+	var agent Agent
+	err := c.update(ctx, "agents", id, update, &agent)
+	if err != nil {
+		return Agent{}, err
+	}
+	return agent, nil
+}
+
 /*
 AddEngineToAgent adds an Engine to an Agent.
 */
 func (c *ManagementClient) AddEngineToAgent(agentID, engineID int) error {
+	return c.AddEngineToAgentCtx(context.Background(), agentID, engineID)
+}
+
+/*
+AddEngineToAgentCtx is like AddEngineToAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) AddEngineToAgentCtx(ctx context.Context, agentID, engineID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("PUT", mgmtEndpointPath+"agents/"+strconv.Itoa(agentID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"agents/"+strconv.Itoa(agentID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -656,11 +972,18 @@ func (c *ManagementClient) AddEngineToAgent(agentID, engineID int) error {
 RemoveEngineFromAgent removes an Engine from an Agent.
 */
 func (c *ManagementClient) RemoveEngineFromAgent(agentID, engineID int) error {
+	return c.RemoveEngineFromAgentCtx(context.Background(), agentID, engineID)
+}
+
+/*
+RemoveEngineFromAgentCtx is like RemoveEngineFromAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveEngineFromAgentCtx(ctx context.Context, agentID, engineID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"agents/"+strconv.Itoa(agentID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"agents/"+strconv.Itoa(agentID)+"/engine/"+strconv.Itoa(engineID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -692,10 +1015,17 @@ func (c *ManagementClient) RemoveSelectorFromAgent(agentID, selectorID int) (Age
 AddTagToAgent adds a tag to a agent.
 */
 func (c *ManagementClient) AddTagToAgent(agentID, tagID int) error {
+	return c.AddTagToAgentCtx(context.Background(), agentID, tagID)
+}
+
+/*
+AddTagToAgentCtx is like AddTagToAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) AddTagToAgentCtx(ctx context.Context, agentID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -710,10 +1040,17 @@ func (c *ManagementClient) AddTagToAgent(agentID, tagID int) error {
 RemoveTagFromAgent removes a tag from a agent.
 */
 func (c *ManagementClient) RemoveTagFromAgent(agentID, tagID int) error {
+	return c.RemoveTagFromAgentCtx(context.Background(), agentID, tagID)
+}
+
+/*
+RemoveTagFromAgentCtx is like RemoveTagFromAgent but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveTagFromAgentCtx(ctx context.Context, agentID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/agent/"+strconv.Itoa(agentID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -730,13 +1067,21 @@ ENDPOINTS
 
 /*
 GetEndpoints returns a list of endpoints, optionally filtered.
+The filters can be created with EndpointFilter.Params().
 */
 func (c *ManagementClient) GetEndpoints(filters map[string]string) (Endpoints, error) {
+	return c.GetEndpointsCtx(context.Background(), filters)
+}
+
+/*
+GetEndpointsCtx is like GetEndpoints but uses the given context for the request.
+*/
+func (c *ManagementClient) GetEndpointsCtx(ctx context.Context, filters map[string]string) (Endpoints, error) {
 	if !c.isValid() {
 		return nil, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"endpoints", "", nil, filters)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"endpoints", "", nil, filters)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during get endpoints request")
 	}
@@ -756,11 +1101,18 @@ func (c *ManagementClient) GetEndpoints(filters map[string]string) (Endpoints, e
 GetEndpoint returns the endpoint with the given id.
 */
 func (c *ManagementClient) GetEndpoint(id int) (Endpoint, error) {
+	return c.GetEndpointCtx(context.Background(), id)
+}
+
+/*
+GetEndpointCtx is like GetEndpoint but uses the given context for the request.
+*/
+func (c *ManagementClient) GetEndpointCtx(ctx context.Context, id int) (Endpoint, error) {
 	if !c.isValid() {
 		return Endpoint{}, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"endpoints/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"endpoints/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return Endpoint{}, errors.Wrap(err, "error during get labs request")
 	}
@@ -780,17 +1132,31 @@ func (c *ManagementClient) GetEndpoint(id int) (Endpoint, error) {
 CreateEndpoint creates a new endpoint.
 */
 func (c *ManagementClient) CreateEndpoint(name, address, protocol string) (Endpoint, error) {
-	return c.createEndpoint(&name, &address, &protocol, nil)
+	return c.CreateEndpointCtx(context.Background(), name, address, protocol)
+}
+
+/*
+CreateEndpointCtx is like CreateEndpoint but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateEndpointCtx(ctx context.Context, name, address, protocol string) (Endpoint, error) {
+	return c.createEndpoint(ctx, &name, &address, &protocol, nil)
 }
 
 /*
 CreateEndpointWithTag creates a new endpoint tagged with the given tag.
 */
 func (c *ManagementClient) CreateEndpointWithTag(name, address, protocol string, tagID int) (Endpoint, error) {
-	return c.createEndpoint(&name, &address, &protocol, &tagID)
+	return c.CreateEndpointWithTagCtx(context.Background(), name, address, protocol, tagID)
 }
 
-func (c *ManagementClient) createEndpoint(name, address, protocol *string, tagID *int) (Endpoint, error) {
+/*
+CreateEndpointWithTagCtx is like CreateEndpointWithTag but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateEndpointWithTagCtx(ctx context.Context, name, address, protocol string, tagID int) (Endpoint, error) {
+	return c.createEndpoint(ctx, &name, &address, &protocol, &tagID)
+}
+
+func (c *ManagementClient) createEndpoint(ctx context.Context, name, address, protocol *string, tagID *int) (Endpoint, error) {
 	if !c.isValid() {
 		return Endpoint{}, &NotValidError{}
 	}
@@ -824,7 +1190,7 @@ func (c *ManagementClient) createEndpoint(name, address, protocol *string, tagID
 		path = mgmtEndpointPath + "tags/" + strconv.Itoa(*tagID) + "/endpoint"
 	}
 
-	response, err := c.request("POST", path, string(jsonString), nil, nil)
+	response, err := c.request(ctx, "POST", path, string(jsonString), nil, nil)
 
 	if err != nil {
 		return Endpoint{}, errors.Wrap(err, "error during request")
@@ -845,11 +1211,18 @@ func (c *ManagementClient) createEndpoint(name, address, protocol *string, tagID
 DeleteEndpoint the Lab with the given id.
 */
 func (c *ManagementClient) DeleteEndpoint(id int) error {
+	return c.DeleteEndpointCtx(context.Background(), id)
+}
+
+/*
+DeleteEndpointCtx is like DeleteEndpoint but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteEndpointCtx(ctx context.Context, id int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"endpoints/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"endpoints/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -859,14 +1232,60 @@ func (c *ManagementClient) DeleteEndpoint(id int) error {
 	return nil
 }
 
+/*
+EndpointUpdate contains the fields of an endpoint which are changed by UpdateEndpoint, nil fields are left unchanged.
+*/
+type EndpointUpdate struct {
+	// Name is the name of the endpoint.
+	Name *string `json:"name,omitempty"`
+	// Address is the address of the endpoint, e.g. 127.0.0.1:1161.
+	Address *string `json:"address,omitempty"`
+	// Protocol is the transport protocol, udpv4 or udpv6.
+	Protocol *string `json:"protocol,omitempty"`
+}
+
+/*
+UpdateEndpoint changes the fields of an endpoint which are set in the update and returns the updated endpoint.
+Links to other objects are kept.
+*/
+func (c *ManagementClient) UpdateEndpoint(id int, update EndpointUpdate) (Endpoint, error) {
+	return c.UpdateEndpointCtx(context.Background(), id, update)
+}
+
+/*
+UpdateEndpointCtx is like UpdateEndpoint but uses the given context for the request.
+*/
+func (c *ManagementClient) UpdateEndpointCtx(ctx context.Context, id int, update EndpointUpdate) (Endpoint, error) {
+	if !c.isValid() {
+		return Endpoint{}, &NotValidError{}
+	}
+	if update.Name != nil && *update.Name == "" {
+		return Endpoint{}, errors.New("invalid name")
+	}
+
+	var endpoint Endpoint
+	err := c.update(ctx, "endpoints", id, update, &endpoint)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return endpoint, nil
+}
+
 /*
 AddTagToEndpoint adds a tag to a endpoint.
 */
 func (c *ManagementClient) AddTagToEndpoint(endpointID, tagID int) error {
+	return c.AddTagToEndpointCtx(context.Background(), endpointID, tagID)
+}
+
+/*
+AddTagToEndpointCtx is like AddTagToEndpoint but uses the given context for the request.
+*/
+func (c *ManagementClient) AddTagToEndpointCtx(ctx context.Context, endpointID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -881,10 +1300,17 @@ func (c *ManagementClient) AddTagToEndpoint(endpointID, tagID int) error {
 RemoveTagFromEndpoint removes a tag from a endpoint.
 */
 func (c *ManagementClient) RemoveTagFromEndpoint(endpointID, tagID int) error {
+	return c.RemoveTagFromEndpointCtx(context.Background(), endpointID, tagID)
+}
+
+/*
+RemoveTagFromEndpointCtx is like RemoveTagFromEndpoint but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveTagFromEndpointCtx(ctx context.Context, endpointID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/endpoint/"+strconv.Itoa(endpointID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -899,15 +1325,46 @@ func (c *ManagementClient) RemoveTagFromEndpoint(endpointID, tagID int) error {
 RECORD FILES
 */
 
+/*
+RecordFileValidationError is returned instead of uploading a record file if record file validation is enabled and the record file is invalid.
+*/
+type RecordFileValidationError struct {
+	RemotePath string
+	Problems   snmprec.Problems
+}
+
+func (r *RecordFileValidationError) Error() string {
+	return "record file " + r.RemotePath + " was not uploaded: " + r.Problems.Error()
+}
+
+/*
+SetRecordFileValidation enables or disables the validation of record files before they are uploaded.
+If enabled, invalid record files are not uploaded and a RecordFileValidationError containing all problems is returned.
+*/
+func (c *ManagementClient) SetRecordFileValidation(enabled bool) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	c.validateRecordFiles = enabled
+	return nil
+}
+
 /*
 GetRecordFiles returns a list of all record files.
 */
 func (c *ManagementClient) GetRecordFiles() (Recordings, error) {
+	return c.GetRecordFilesCtx(context.Background())
+}
+
+/*
+GetRecordFilesCtx is like GetRecordFiles but uses the given context for the request.
+*/
+func (c *ManagementClient) GetRecordFilesCtx(ctx context.Context) (Recordings, error) {
 	if !c.isValid() {
 		return nil, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"recordings", "", nil, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"recordings", "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during get labs request")
 	}
@@ -927,6 +1384,13 @@ func (c *ManagementClient) GetRecordFiles() (Recordings, error) {
 UploadRecordFile uploads the given record file to the api and saves it at the given remote path inside of the data dir.
 */
 func (c *ManagementClient) UploadRecordFile(localPath, remotePath string) error {
+	return c.UploadRecordFileCtx(context.Background(), localPath, remotePath)
+}
+
+/*
+UploadRecordFileCtx is like UploadRecordFile but uses the given context for the request.
+*/
+func (c *ManagementClient) UploadRecordFileCtx(ctx context.Context, localPath, remotePath string) error {
 	localPath = strings.TrimSpace(localPath)
 	if !strings.HasSuffix(localPath, ".snmprec") {
 		return errors.New("file is not an snmprec file")
@@ -936,16 +1400,28 @@ func (c *ManagementClient) UploadRecordFile(localPath, remotePath string) error
 		return errors.Wrap(err, "error while reading file")
 	}
 	s := string(b)
-	return c.UploadRecordFileString(&s, remotePath)
+	return c.UploadRecordFileStringCtx(ctx, &s, remotePath)
 }
 
 /*
 UploadRecordFileString uploads the given record data to the api and saves it as a .snmprec file at the given remote path inside of the data dir.
 */
 func (c *ManagementClient) UploadRecordFileString(recordContents *string, remotePath string) error {
+	return c.UploadRecordFileStringCtx(context.Background(), recordContents, remotePath)
+}
+
+/*
+UploadRecordFileStringCtx is like UploadRecordFileString but uses the given context for the request.
+*/
+func (c *ManagementClient) UploadRecordFileStringCtx(ctx context.Context, recordContents *string, remotePath string) error {
+	if c.validateRecordFiles {
+		if problems := snmprec.ValidateString(*recordContents); len(problems) > 0 {
+			return &RecordFileValidationError{RemotePath: remotePath, Problems: problems}
+		}
+	}
 	headerMap := make(map[string]string)
 	headerMap["Content-Type"] = "text/plain"
-	response, err := c.request("POST", mgmtEndpointPath+"recordings/"+remotePath, *recordContents, headerMap, nil)
+	response, err := c.request(ctx, "POST", mgmtEndpointPath+"recordings/"+remotePath, *recordContents, headerMap, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -959,13 +1435,20 @@ func (c *ManagementClient) UploadRecordFileString(recordContents *string, remote
 DeleteRecordFile deletes the record file at the given path.
 */
 func (c *ManagementClient) DeleteRecordFile(remotePath string) error {
+	return c.DeleteRecordFileCtx(context.Background(), remotePath)
+}
+
+/*
+DeleteRecordFileCtx is like DeleteRecordFile but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteRecordFileCtx(ctx context.Context, remotePath string) error {
 	remotePath = strings.TrimSpace(remotePath)
 	if !strings.HasSuffix(remotePath, ".snmprec") {
 		return errors.New("file is not an snmprec file")
 	}
 	headerMap := make(map[string]string)
 	headerMap["Content-Type"] = "text/plain"
-	response, err := c.request("DELETE", mgmtEndpointPath+"recordings/"+remotePath, "", headerMap, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"recordings/"+remotePath, "", headerMap, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -979,13 +1462,20 @@ func (c *ManagementClient) DeleteRecordFile(remotePath string) error {
 GetRecordFile returns the record file at the given path.
 */
 func (c *ManagementClient) GetRecordFile(remotePath string) (string, error) {
+	return c.GetRecordFileCtx(context.Background(), remotePath)
+}
+
+/*
+GetRecordFileCtx is like GetRecordFile but uses the given context for the request.
+*/
+func (c *ManagementClient) GetRecordFileCtx(ctx context.Context, remotePath string) (string, error) {
 	remotePath = strings.TrimSpace(remotePath)
 	if !strings.HasSuffix(remotePath, ".snmprec") {
 		return "", errors.New("file is not an snmprec file")
 	}
 	headerMap := make(map[string]string)
 	headerMap["Content-Type"] = "text/plain"
-	response, err := c.request("GET", mgmtEndpointPath+"recordings/"+remotePath, "", headerMap, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"recordings/"+remotePath, "", headerMap, nil)
 	if err != nil {
 		return "", errors.Wrap(err, "error during request")
 	}
@@ -995,6 +1485,72 @@ func (c *ManagementClient) GetRecordFile(remotePath string) (string, error) {
 	return string(response.Body()), nil
 }
 
+/*
+UploadRecords sorts a copy of the given records by their OIDs and uploads them as a record file to the given remote path inside of the data dir.
+*/
+func (c *ManagementClient) UploadRecords(records snmprec.Records, remotePath string) error {
+	return c.UploadRecordsCtx(context.Background(), records, remotePath)
+}
+
+/*
+UploadRecordsCtx is like UploadRecords but uses the given context for the request.
+*/
+func (c *ManagementClient) UploadRecordsCtx(ctx context.Context, records snmprec.Records, remotePath string) error {
+	sorted := make(snmprec.Records, len(records))
+	copy(sorted, records)
+	sorted.Sort()
+	for _, record := range sorted {
+		if err := snmprec.ValidateOID(record.OID); err != nil {
+			return errors.Wrap(err, "invalid record")
+		}
+		if err := record.Check(); err != nil {
+			return errors.Wrap(err, "invalid record "+record.OID)
+		}
+	}
+	s := sorted.String()
+	return c.UploadRecordFileStringCtx(ctx, &s, remotePath)
+}
+
+/*
+GetRecords returns the parsed records of the record file at the given path.
+*/
+func (c *ManagementClient) GetRecords(remotePath string) (snmprec.Records, error) {
+	return c.GetRecordsCtx(context.Background(), remotePath)
+}
+
+/*
+GetRecordsCtx is like GetRecords but uses the given context for the request.
+*/
+func (c *ManagementClient) GetRecordsCtx(ctx context.Context, remotePath string) (snmprec.Records, error) {
+	s, err := c.GetRecordFileCtx(ctx, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	records, err := snmprec.ParseString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while parsing record file")
+	}
+	return records, nil
+}
+
+/*
+UploadSnmpwalk converts the output of net-snmp's snmpwalk -On into a record file and uploads it to the given remote path inside of the data dir.
+*/
+func (c *ManagementClient) UploadSnmpwalk(snmpwalkOutput string, remotePath string) error {
+	return c.UploadSnmpwalkCtx(context.Background(), snmpwalkOutput, remotePath)
+}
+
+/*
+UploadSnmpwalkCtx is like UploadSnmpwalk but uses the given context for the request.
+*/
+func (c *ManagementClient) UploadSnmpwalkCtx(ctx context.Context, snmpwalkOutput string, remotePath string) error {
+	records, err := snmprec.ConvertSnmpwalkString(snmpwalkOutput)
+	if err != nil {
+		return errors.Wrap(err, "error while converting snmpwalk output")
+	}
+	return c.UploadRecordsCtx(ctx, records, remotePath)
+}
+
 /*
 USERS
 */
@@ -1003,17 +1559,31 @@ USERS
 CreateUser creates a new user.
 */
 func (c *ManagementClient) CreateUser(user, name, authKey, authProto, privKey, privProto string) (User, error) {
-	return c.createUser(&user, &name, &authKey, &authProto, &privKey, &privProto, nil)
+	return c.CreateUserCtx(context.Background(), user, name, authKey, authProto, privKey, privProto)
+}
+
+/*
+CreateUserCtx is like CreateUser but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateUserCtx(ctx context.Context, user, name, authKey, authProto, privKey, privProto string) (User, error) {
+	return c.createUser(ctx, &user, &name, &authKey, &authProto, &privKey, &privProto, nil)
 }
 
 /*
 CreateUserWithTag creates a new user tagged with the given tag.
 */
 func (c *ManagementClient) CreateUserWithTag(user, name, authKey, authProto, privKey, privProto string, tagID int) (User, error) {
-	return c.createUser(&user, &name, &authKey, &authProto, &privKey, &privProto, &tagID)
+	return c.CreateUserWithTagCtx(context.Background(), user, name, authKey, authProto, privKey, privProto, tagID)
+}
+
+/*
+CreateUserWithTagCtx is like CreateUserWithTag but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateUserWithTagCtx(ctx context.Context, user, name, authKey, authProto, privKey, privProto string, tagID int) (User, error) {
+	return c.createUser(ctx, &user, &name, &authKey, &authProto, &privKey, &privProto, &tagID)
 }
 
-func (c *ManagementClient) createUser(user, name, authKey, authProto, privKey, privProto *string, tagID *int) (User, error) {
+func (c *ManagementClient) createUser(ctx context.Context, user, name, authKey, authProto, privKey, privProto *string, tagID *int) (User, error) {
 	if !c.isValid() {
 		return User{}, &NotValidError{}
 	}
@@ -1026,12 +1596,14 @@ func (c *ManagementClient) createUser(user, name, authKey, authProto, privKey, p
 		return User{}, errors.New("invalid user")
 	}
 
-	if *authProto == "" {
-		*authProto = "none"
-	}
-	if *privProto == "" {
-		*privProto = "none"
+	err := ValidateUSM(*authKey, *authProto, *privKey, *privProto)
+	if err != nil {
+		return User{}, err
 	}
+	auth, _ := ParseAuthProtocol(*authProto)
+	*authProto = string(auth)
+	priv, _ := ParsePrivProtocol(*privProto)
+	*privProto = priv.apiName()
 
 	type requestParams struct {
 		User      string  `json:"user"`
@@ -1071,7 +1643,7 @@ func (c *ManagementClient) createUser(user, name, authKey, authProto, privKey, p
 		path = mgmtEndpointPath + "tags/" + strconv.Itoa(*tagID) + "/user"
 	}
 
-	response, err := c.request("POST", path, string(jsonString), nil, nil)
+	response, err := c.request(ctx, "POST", path, string(jsonString), nil, nil)
 	if err != nil {
 		return User{}, errors.Wrap(err, "error during request")
 	}
@@ -1089,13 +1661,21 @@ func (c *ManagementClient) createUser(user, name, authKey, authProto, privKey, p
 
 /*
 GetUsers returns a list of users, optionally filtered.
+The filters can be created with UserFilter.Params().
 */
 func (c *ManagementClient) GetUsers(filters map[string]string) (Users, error) {
+	return c.GetUsersCtx(context.Background(), filters)
+}
+
+/*
+GetUsersCtx is like GetUsers but uses the given context for the request.
+*/
+func (c *ManagementClient) GetUsersCtx(ctx context.Context, filters map[string]string) (Users, error) {
 	if !c.isValid() {
 		return nil, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"users", "", nil, filters)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"users", "", nil, filters)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during get users request")
 	}
@@ -1115,11 +1695,18 @@ func (c *ManagementClient) GetUsers(filters map[string]string) (Users, error) {
 GetUser returns the user with the given id.
 */
 func (c *ManagementClient) GetUser(id int) (User, error) {
+	return c.GetUserCtx(context.Background(), id)
+}
+
+/*
+GetUserCtx is like GetUser but uses the given context for the request.
+*/
+func (c *ManagementClient) GetUserCtx(ctx context.Context, id int) (User, error) {
 	if !c.isValid() {
 		return User{}, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"users/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"users/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return User{}, errors.Wrap(err, "error during get labs request")
 	}
@@ -1139,11 +1726,18 @@ func (c *ManagementClient) GetUser(id int) (User, error) {
 DeleteUser deletes the user with the given id.
 */
 func (c *ManagementClient) DeleteUser(id int) error {
+	return c.DeleteUserCtx(context.Background(), id)
+}
+
+/*
+DeleteUserCtx is like DeleteUser but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteUserCtx(ctx context.Context, id int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"users/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"users/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -1153,14 +1747,87 @@ func (c *ManagementClient) DeleteUser(id int) error {
 	return nil
 }
 
+/*
+UserUpdate contains the fields of a user which are changed by UpdateUser, nil fields are left unchanged.
+*/
+type UserUpdate struct {
+	// User is the SNMPv3 security name.
+	User *string `json:"user,omitempty"`
+	// Name is the name of the user.
+	Name *string `json:"name,omitempty"`
+	// AuthKey is the authentication key.
+	AuthKey *string `json:"auth_key,omitempty"`
+	// AuthProto is the authentication protocol.
+	AuthProto *string `json:"auth_proto,omitempty"`
+	// PrivKey is the privacy key.
+	PrivKey *string `json:"priv_key,omitempty"`
+	// PrivProto is the privacy protocol.
+	PrivProto *string `json:"priv_proto,omitempty"`
+}
+
+/*
+UpdateUser changes the fields of a user which are set in the update and returns the updated user.
+Links to other objects are kept.
+*/
+func (c *ManagementClient) UpdateUser(id int, update UserUpdate) (User, error) {
+	return c.UpdateUserCtx(context.Background(), id, update)
+}
+
+/*
+UpdateUserCtx is like UpdateUser but uses the given context for the request.
+*/
+func (c *ManagementClient) UpdateUserCtx(ctx context.Context, id int, update UserUpdate) (User, error) {
+	if !c.isValid() {
+		return User{}, &NotValidError{}
+	}
+	if update.Name != nil && *update.Name == "" {
+		return User{}, errors.New("invalid name")
+	}
+	if update.AuthProto != nil {
+		auth, err := ParseAuthProtocol(*update.AuthProto)
+		if err != nil {
+			return User{}, err
+		}
+		normalized := string(auth)
+		update.AuthProto = &normalized
+	}
+	if update.PrivProto != nil {
+		priv, err := ParsePrivProtocol(*update.PrivProto)
+		if err != nil {
+			return User{}, err
+		}
+		normalized := priv.apiName()
+		update.PrivProto = &normalized
+	}
+	for _, key := range []*string{update.AuthKey, update.PrivKey} {
+		if key != nil && *key != "" && len(*key) < MinKeyLength {
+			return User{}, errors.New("keys must have at least " + strconv.Itoa(MinKeyLength) + " characters")
+		}
+	}
+
+	var user User
+	err := c.update(ctx, "users", id, update, &user)
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
 /*
 AddTagToUser adds a tag to a user.
 */
 func (c *ManagementClient) AddTagToUser(userID, tagID int) error {
+	return c.AddTagToUserCtx(context.Background(), userID, tagID)
+}
+
+/*
+AddTagToUserCtx is like AddTagToUser but uses the given context for the request.
+*/
+func (c *ManagementClient) AddTagToUserCtx(ctx context.Context, userID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -1175,10 +1842,17 @@ func (c *ManagementClient) AddTagToUser(userID, tagID int) error {
 RemoveTagFromUser removes a tag from a user.
 */
 func (c *ManagementClient) RemoveTagFromUser(userID, tagID int) error {
+	return c.RemoveTagFromUserCtx(context.Background(), userID, tagID)
+}
+
+/*
+RemoveTagFromUserCtx is like RemoveTagFromUser but uses the given context for the request.
+*/
+func (c *ManagementClient) RemoveTagFromUserCtx(ctx context.Context, userID, tagID int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
-	response, err := c.request("DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/user/"+strconv.Itoa(userID), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -1234,6 +1908,13 @@ func (c *ManagementClient) DeleteSelector(id int) error {
 CreateTag creates a new tag.
 */
 func (c *ManagementClient) CreateTag(name, description string) (Tag, error) {
+	return c.CreateTagCtx(context.Background(), name, description)
+}
+
+/*
+CreateTagCtx is like CreateTag but uses the given context for the request.
+*/
+func (c *ManagementClient) CreateTagCtx(ctx context.Context, name, description string) (Tag, error) {
 	if !c.isValid() {
 		return Tag{}, &NotValidError{}
 	}
@@ -1252,7 +1933,7 @@ func (c *ManagementClient) CreateTag(name, description string) (Tag, error) {
 		return Tag{}, errors.Wrap(err, "error during marshal")
 	}
 
-	response, err := c.request("POST", mgmtEndpointPath+"tags", string(jsonString), nil, nil)
+	response, err := c.request(ctx, "POST", mgmtEndpointPath+"tags", string(jsonString), nil, nil)
 	if err != nil {
 		return Tag{}, errors.Wrap(err, "error during request")
 	}
@@ -1272,11 +1953,18 @@ func (c *ManagementClient) CreateTag(name, description string) (Tag, error) {
 GetTag returns the lab with the given id.
 */
 func (c *ManagementClient) GetTag(id int) (Tag, error) {
+	return c.GetTagCtx(context.Background(), id)
+}
+
+/*
+GetTagCtx is like GetTag but uses the given context for the request.
+*/
+func (c *ManagementClient) GetTagCtx(ctx context.Context, id int) (Tag, error) {
 	if !c.isValid() {
 		return Tag{}, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"tags/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"tags/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return Tag{}, errors.Wrap(err, "error during get tags request")
 	}
@@ -1294,13 +1982,21 @@ func (c *ManagementClient) GetTag(id int) (Tag, error) {
 
 /*
 GetTags returns a list of users, optionally filtered.
+The filters can be created with TagFilter.Params().
 */
 func (c *ManagementClient) GetTags(filters map[string]string) (Tags, error) {
+	return c.GetTagsCtx(context.Background(), filters)
+}
+
+/*
+GetTagsCtx is like GetTags but uses the given context for the request.
+*/
+func (c *ManagementClient) GetTagsCtx(ctx context.Context, filters map[string]string) (Tags, error) {
 	if !c.isValid() {
 		return nil, &NotValidError{}
 	}
 
-	response, err := c.request("GET", mgmtEndpointPath+"tags", "", nil, filters)
+	response, err := c.request(ctx, "GET", mgmtEndpointPath+"tags", "", nil, filters)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during get users request")
 	}
@@ -1320,11 +2016,18 @@ func (c *ManagementClient) GetTags(filters map[string]string) (Tags, error) {
 DeleteTag deletes the tag with the given id.
 */
 func (c *ManagementClient) DeleteTag(id int) error {
+	return c.DeleteTagCtx(context.Background(), id)
+}
+
+/*
+DeleteTagCtx is like DeleteTag but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteTagCtx(ctx context.Context, id int) error {
 	if !c.isValid() {
 		return &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(id), "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return errors.Wrap(err, "error during request")
 	}
@@ -1334,15 +2037,59 @@ func (c *ManagementClient) DeleteTag(id int) error {
 	return nil
 }
 
+/*
+TagUpdate contains the fields of a tag which are changed by UpdateTag, nil fields are left unchanged.
+*/
+type TagUpdate struct {
+	// Name is the name of the tag.
+	Name *string `json:"name,omitempty"`
+	// Description is the description of the tag.
+	Description *string `json:"description,omitempty"`
+}
+
+/*
+UpdateTag changes the fields of a tag which are set in the update and returns the updated tag.
+Links to other objects are kept.
+*/
+func (c *ManagementClient) UpdateTag(id int, update TagUpdate) (Tag, error) {
+	return c.UpdateTagCtx(context.Background(), id, update)
+}
+
+/*
+UpdateTagCtx is like UpdateTag but uses the given context for the request.
+*/
+func (c *ManagementClient) UpdateTagCtx(ctx context.Context, id int, update TagUpdate) (Tag, error) {
+	if !c.isValid() {
+		return Tag{}, &NotValidError{}
+	}
+	if update.Name != nil && *update.Name == "" {
+		return Tag{}, errors.New("invalid name")
+	}
+
+	var tag Tag
+	err := c.update(ctx, "tags", id, update, &tag)
+	if err != nil {
+		return Tag{}, err
+	}
+	return tag, nil
+}
+
 /*
 DeleteAllObjectsWithTag deletes all objects with the given tag.
 */
 func (c *ManagementClient) DeleteAllObjectsWithTag(tagID int) (Tag, error) {
+	return c.DeleteAllObjectsWithTagCtx(context.Background(), tagID)
+}
+
+/*
+DeleteAllObjectsWithTagCtx is like DeleteAllObjectsWithTag but uses the given context for the request.
+*/
+func (c *ManagementClient) DeleteAllObjectsWithTagCtx(ctx context.Context, tagID int) (Tag, error) {
 	if !c.isValid() {
 		return Tag{}, &NotValidError{}
 	}
 
-	response, err := c.request("DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/objects", "", nil, nil)
+	response, err := c.request(ctx, "DELETE", mgmtEndpointPath+"tags/"+strconv.Itoa(tagID)+"/objects", "", nil, nil)
 	if err != nil {
 		return Tag{}, errors.Wrap(err, "error during request")
 	}
@@ -1358,3 +2105,28 @@ func (c *ManagementClient) DeleteAllObjectsWithTag(tagID int) (Tag, error) {
 
 	return tag, nil
 }
+
+// update sends the fields which are set in update to the object with the given id and unmarshals the updated object into result.
+func (c *ManagementClient) update(ctx context.Context, objectPath string, id int, update interface{}, result interface{}) error {
+	jsonString, err := json.Marshal(update)
+	if err != nil {
+		return errors.Wrap(err, "error during marshal")
+	}
+	if string(jsonString) == "{}" {
+		return errors.New("no fields to update")
+	}
+
+	response, err := c.request(ctx, "PUT", mgmtEndpointPath+objectPath+"/"+strconv.Itoa(id), string(jsonString), nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "error during update request")
+	}
+	if response.StatusCode() != 200 {
+		return getHTTPError(response)
+	}
+
+	err = json.Unmarshal(response.Body(), result)
+	if err != nil {
+		return errors.Wrap(err, "error during unmarshalling http response")
+	}
+	return nil
+}
@@ -15,6 +15,9 @@ func TestMetricsClient_BuildUpSetupAndTestMetrics(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping TestMetricsClient_BuildUpSetupAndTestMetrics in short mode")
 	}
+	if configMetricsTest.FakeServer {
+		t.Skip("skipping TestMetricsClient_BuildUpSetupAndTestMetrics, the fake server does not respond to snmp requests")
+	}
 	community := "public"
 	//	Agent 1
 	//Agent
@@ -32,7 +35,7 @@ func TestMetricsClient_BuildUpSetupAndTestMetrics(t *testing.T) {
 	userIdentifier1 := "test-buildUpSetupAndTestMetrics"
 	//engine
 	engineName1 := "test-buildUpSetupAndTestMetrics-engine1"
-	engineID1 := "0102030405070809"
+	engineID1 := "010203040507080900000000"
 	//Record File:
 	localRecordFilePath1 := configMetricsTest.TestDataDir + "snmprecs/TestMetricsClient_BuildUpSetupAndTestMetrics/" + community + ".snmprec"
 	remoteRecordFilePath1 := agentDataDir1 + "/" + community + ".snmprec"
@@ -53,14 +56,8 @@ func TestMetricsClient_BuildUpSetupAndTestMetrics(t *testing.T) {
 	//Record file
 	//TODO: remove this when its possible to overwrite files
 	err = managementClient.DeleteRecordFile(remoteRecordFilePath1)
-	if err != nil {
-		if err, ok := err.(HTTPError); assert.True(t, ok, "unknown error returned while deleting record file") {
-			if !assert.True(t, err.StatusCode == 404, "http error code for deleting record file is not 404! error: "+err.Error()) {
-				return
-			}
-		} else {
-			return
-		}
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "http error code for deleting record file is not 404! error: "+err.Error()) {
+		return
 	}
 
 	err = uploadRecordFileAndCheckForSuccess(t, managementClient, localRecordFilePath1, remoteRecordFilePath1)
@@ -352,7 +349,7 @@ func TestMetricsClient_Processes(t *testing.T) {
 	userIdentifier1 := "test-buildUpSetupAndTestMetrics"
 	//engine
 	engineName1 := "test-buildUpSetupAndTestMetrics-engine1"
-	engineID1 := "0102030405070809"
+	engineID1 := "010203040507080900000000"
 	//Record File:
 	localRecordFilePath1 := configMetricsTest.TestDataDir + "snmprecs/TestMetricsClient_BuildUpSetupAndTestMetrics/" + community + ".snmprec"
 	remoteRecordFilePath1 := agentDataDir1 + "/" + community + ".snmprec"
@@ -373,14 +370,8 @@ func TestMetricsClient_Processes(t *testing.T) {
 	//Record file
 	//TODO: remove this when its possible to overwrite files
 	err = managementClient.DeleteRecordFile(remoteRecordFilePath1)
-	if err != nil {
-		if err, ok := err.(HTTPError); assert.True(t, ok, "unknown error returned while deleting record file") {
-			if !assert.True(t, err.StatusCode == 404, "http error code for deleting record file is not 404! error: "+err.Error()) {
-				return
-			}
-		} else {
-			return
-		}
+	if err != nil && !assert.True(t, errors.Is(err, ErrNotFound), "http error code for deleting record file is not 404! error: "+err.Error()) {
+		return
 	}
 
 	//Create a new api client
@@ -536,7 +527,9 @@ func TestMetricsClient_Processes(t *testing.T) {
 	}()
 
 	//waiting for asynchronous metrics importer
-	time.Sleep(20 * time.Second)
+	if !configMetricsTest.FakeServer {
+		time.Sleep(20 * time.Second)
+	}
 
 	//Test GetProcesses
 	processes, err := metricsClient.GetProcesses(nil)
@@ -1,8 +1,8 @@
 package snmpsimclient
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/go-resty/resty/v2"
 	"github.com/pkg/errors"
 	"strconv"
 )
@@ -15,9 +15,9 @@ type MetricsClient struct {
 }
 
 /*
-NewMetricsClient creates a new NewMetricsClient.
+NewMetricsClient creates a new NewMetricsClient, the options configure the http connection, e.g. WithCACertFile.
 */
-func NewMetricsClient(baseURL string) (*MetricsClient, error) {
+func NewMetricsClient(baseURL string, opts ...ClientOption) (*MetricsClient, error) {
 	if baseURL == "" {
 		return nil, errors.New("invalid base url")
 	}
@@ -25,7 +25,11 @@ func NewMetricsClient(baseURL string) (*MetricsClient, error) {
 	if lastChar := baseURL[len(baseURL)-1:]; lastChar != "/" {
 		baseURL += "/"
 	}
-	clientData := clientData{baseURL: baseURL, resty: resty.New(), useAuth: false}
+	restyClient, err := newResty(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid client option")
+	}
+	clientData := clientData{baseURL: baseURL, resty: restyClient}
 	newClient := client{&clientData}
 	return &MetricsClient{newClient}, nil
 }
@@ -34,7 +38,14 @@ func NewMetricsClient(baseURL string) (*MetricsClient, error) {
 GetProcesses returns process metrics.
 */
 func (c *MetricsClient) GetProcesses(filters map[string]string) (ProcessesMetrics, error) {
-	response, err := c.request("GET", metricsEndpointPath+"processes", "", nil, filters)
+	return c.GetProcessesCtx(context.Background(), filters)
+}
+
+/*
+GetProcessesCtx is like GetProcesses but uses the given context for the request.
+*/
+func (c *MetricsClient) GetProcessesCtx(ctx context.Context, filters map[string]string) (ProcessesMetrics, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"processes", "", nil, filters)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during request")
 	}
@@ -53,7 +64,14 @@ func (c *MetricsClient) GetProcesses(filters map[string]string) (ProcessesMetric
 GetProcess returns the process with the given id.
 */
 func (c *MetricsClient) GetProcess(id int) (ProcessMetrics, error) {
-	response, err := c.request("GET", metricsEndpointPath+"processes/"+strconv.Itoa(id), "", nil, nil)
+	return c.GetProcessCtx(context.Background(), id)
+}
+
+/*
+GetProcessCtx is like GetProcess but uses the given context for the request.
+*/
+func (c *MetricsClient) GetProcessCtx(ctx context.Context, id int) (ProcessMetrics, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"processes/"+strconv.Itoa(id), "", nil, nil)
 	if err != nil {
 		return ProcessMetrics{}, errors.Wrap(err, "error during request")
 	}
@@ -72,7 +90,14 @@ func (c *MetricsClient) GetProcess(id int) (ProcessMetrics, error) {
 GetProcessEndpoints returns an array of endpoints for the given process-id.
 */
 func (c *MetricsClient) GetProcessEndpoints(id int) (ProcessEndpoints, error) {
-	response, err := c.request("GET", metricsEndpointPath+"processes/"+strconv.Itoa(id)+"/endpoints", "", nil, nil)
+	return c.GetProcessEndpointsCtx(context.Background(), id)
+}
+
+/*
+GetProcessEndpointsCtx is like GetProcessEndpoints but uses the given context for the request.
+*/
+func (c *MetricsClient) GetProcessEndpointsCtx(ctx context.Context, id int) (ProcessEndpoints, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"processes/"+strconv.Itoa(id)+"/endpoints", "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during request")
 	}
@@ -91,7 +116,14 @@ func (c *MetricsClient) GetProcessEndpoints(id int) (ProcessEndpoints, error) {
 GetProcessEndpoint returns the endpoint for the given process- and endpoint-id.
 */
 func (c *MetricsClient) GetProcessEndpoint(processID int, endpointID int) (ProcessEndpoint, error) {
-	response, err := c.request("GET", metricsEndpointPath+"processes/"+strconv.Itoa(processID)+"/endpoints/"+strconv.Itoa(endpointID), "", nil, nil)
+	return c.GetProcessEndpointCtx(context.Background(), processID, endpointID)
+}
+
+/*
+GetProcessEndpointCtx is like GetProcessEndpoint but uses the given context for the request.
+*/
+func (c *MetricsClient) GetProcessEndpointCtx(ctx context.Context, processID int, endpointID int) (ProcessEndpoint, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"processes/"+strconv.Itoa(processID)+"/endpoints/"+strconv.Itoa(endpointID), "", nil, nil)
 	if err != nil {
 		return ProcessEndpoint{}, errors.Wrap(err, "error during request")
 	}
@@ -110,7 +142,14 @@ func (c *MetricsClient) GetProcessEndpoint(processID int, endpointID int) (Proce
 GetProcessConsolePages returns an array of console-pages for the given process-id.
 */
 func (c *MetricsClient) GetProcessConsolePages(processID int) (Consoles, error) {
-	response, err := c.request("GET", metricsEndpointPath+"processes/"+strconv.Itoa(processID)+"/console", "", nil, nil)
+	return c.GetProcessConsolePagesCtx(context.Background(), processID)
+}
+
+/*
+GetProcessConsolePagesCtx is like GetProcessConsolePages but uses the given context for the request.
+*/
+func (c *MetricsClient) GetProcessConsolePagesCtx(ctx context.Context, processID int) (Consoles, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"processes/"+strconv.Itoa(processID)+"/console", "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during request")
 	}
@@ -129,7 +168,14 @@ func (c *MetricsClient) GetProcessConsolePages(processID int) (Consoles, error)
 GetProcessConsolePage returns the console-pages for the given process- and console-page-id.
 */
 func (c *MetricsClient) GetProcessConsolePage(processID int, pageID int) (Console, error) {
-	response, err := c.request("GET", metricsEndpointPath+"processes/"+strconv.Itoa(processID)+"/console/"+strconv.Itoa(pageID), "", nil, nil)
+	return c.GetProcessConsolePageCtx(context.Background(), processID, pageID)
+}
+
+/*
+GetProcessConsolePageCtx is like GetProcessConsolePage but uses the given context for the request.
+*/
+func (c *MetricsClient) GetProcessConsolePageCtx(ctx context.Context, processID int, pageID int) (Console, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"processes/"+strconv.Itoa(processID)+"/console/"+strconv.Itoa(pageID), "", nil, nil)
 	if err != nil {
 		return Console{}, errors.Wrap(err, "error during request")
 	}
@@ -146,9 +192,23 @@ func (c *MetricsClient) GetProcessConsolePage(processID int, pageID int) (Consol
 
 /*
 GetPackets returns packet metrics.
+The filters can be created with PacketFilter.Params().
 */
 func (c *MetricsClient) GetPackets(filters map[string]string) (PacketMetrics, error) {
-	response, err := c.request("GET", metricsEndpointPath+"activity/packets", "", nil, filters)
+	return c.GetPacketsCtx(context.Background(), filters)
+}
+
+/*
+GetPacketsCtx is like GetPackets but uses the given context for the request.
+*/
+func (c *MetricsClient) GetPacketsCtx(ctx context.Context, filters map[string]string) (PacketMetrics, error) {
+	if c.validateFilters {
+		err := c.ValidatePacketFiltersCtx(ctx, filters)
+		if err != nil {
+			return PacketMetrics{}, err
+		}
+	}
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"activity/packets", "", nil, filters)
 	if err != nil {
 		return PacketMetrics{}, errors.Wrap(err, "error during request")
 	}
@@ -167,7 +227,14 @@ func (c *MetricsClient) GetPackets(filters map[string]string) (PacketMetrics, er
 GetPacketFilters returns all packet filters.
 */
 func (c *MetricsClient) GetPacketFilters() (PacketFilters, error) {
-	response, err := c.request("GET", metricsEndpointPath+"activity/packets/filters", "", nil, nil)
+	return c.GetPacketFiltersCtx(context.Background())
+}
+
+/*
+GetPacketFiltersCtx is like GetPacketFilters but uses the given context for the request.
+*/
+func (c *MetricsClient) GetPacketFiltersCtx(ctx context.Context) (PacketFilters, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"activity/packets/filters", "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during request")
 	}
@@ -193,7 +260,14 @@ func (c *MetricsClient) GetPacketFilters() (PacketFilters, error) {
 GetPossibleValuesForPacketFilter returns a list of all values that can be used for the given filter.
 */
 func (c *MetricsClient) GetPossibleValuesForPacketFilter(filter string) ([]string, error) {
-	response, err := c.request("GET", metricsEndpointPath+"activity/packets/filters/"+filter, "", nil, nil)
+	return c.GetPossibleValuesForPacketFilterCtx(context.Background(), filter)
+}
+
+/*
+GetPossibleValuesForPacketFilterCtx is like GetPossibleValuesForPacketFilter but uses the given context for the request.
+*/
+func (c *MetricsClient) GetPossibleValuesForPacketFilterCtx(ctx context.Context, filter string) ([]string, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"activity/packets/filters/"+filter, "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during request")
 	}
@@ -211,9 +285,23 @@ func (c *MetricsClient) GetPossibleValuesForPacketFilter(filter string) ([]strin
 
 /*
 GetMessages returns message metrics.
+The filters can be created with MessageFilter.Params().
 */
 func (c *MetricsClient) GetMessages(filters map[string]string) (MessageMetrics, error) {
-	response, err := c.request("GET", metricsEndpointPath+"activity/messages", "", nil, filters)
+	return c.GetMessagesCtx(context.Background(), filters)
+}
+
+/*
+GetMessagesCtx is like GetMessages but uses the given context for the request.
+*/
+func (c *MetricsClient) GetMessagesCtx(ctx context.Context, filters map[string]string) (MessageMetrics, error) {
+	if c.validateFilters {
+		err := c.ValidateMessageFiltersCtx(ctx, filters)
+		if err != nil {
+			return MessageMetrics{}, err
+		}
+	}
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"activity/messages", "", nil, filters)
 	if err != nil {
 		return MessageMetrics{}, errors.Wrap(err, "error during request")
 	}
@@ -232,7 +320,14 @@ func (c *MetricsClient) GetMessages(filters map[string]string) (MessageMetrics,
 GetMessageFilters returns all message filters.
 */
 func (c *MetricsClient) GetMessageFilters() (MessageFilters, error) {
-	response, err := c.request("GET", metricsEndpointPath+"activity/messages/filters", "", nil, nil)
+	return c.GetMessageFiltersCtx(context.Background())
+}
+
+/*
+GetMessageFiltersCtx is like GetMessageFilters but uses the given context for the request.
+*/
+func (c *MetricsClient) GetMessageFiltersCtx(ctx context.Context) (MessageFilters, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"activity/messages/filters", "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during request")
 	}
@@ -259,7 +354,14 @@ func (c *MetricsClient) GetMessageFilters() (MessageFilters, error) {
 GetPossibleValuesForMessageFilter returns a list of all values that can be used for the given filter.
 */
 func (c *MetricsClient) GetPossibleValuesForMessageFilter(filter string) ([]string, error) {
-	response, err := c.request("GET", metricsEndpointPath+"activity/messages/filters/"+filter, "", nil, nil)
+	return c.GetPossibleValuesForMessageFilterCtx(context.Background(), filter)
+}
+
+/*
+GetPossibleValuesForMessageFilterCtx is like GetPossibleValuesForMessageFilter but uses the given context for the request.
+*/
+func (c *MetricsClient) GetPossibleValuesForMessageFilterCtx(ctx context.Context, filter string) ([]string, error) {
+	response, err := c.request(ctx, "GET", metricsEndpointPath+"activity/messages/filters/"+filter, "", nil, nil)
 	if err != nil {
 		return nil, errors.Wrap(err, "error during request")
 	}
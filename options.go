@@ -0,0 +1,179 @@
+package snmpsimclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+)
+
+/*
+ClientOption configures the http connection of a client, it can be passed to NewManagementClient and NewMetricsClient.
+*/
+type ClientOption func(o *clientOptions) error
+
+type clientOptions struct {
+	httpClient *http.Client
+	transport  http.RoundTripper
+	tlsConfig  *tls.Config
+	proxy      *url.URL
+}
+
+func (o *clientOptions) tls() *tls.Config {
+	if o.tlsConfig == nil {
+		o.tlsConfig = &tls.Config{}
+	}
+	return o.tlsConfig
+}
+
+/*
+WithHTTPClient uses a copy of the given http client for all requests.
+*/
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(o *clientOptions) error {
+		if httpClient == nil {
+			return errors.New("invalid http client")
+		}
+		o.httpClient = httpClient
+		return nil
+	}
+}
+
+/*
+WithTransport uses the given round tripper for all requests.
+It cannot be combined with WithTLSConfig, WithCACertFile, WithClientCert and WithProxy unless it is an *http.Transport.
+*/
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(o *clientOptions) error {
+		if transport == nil {
+			return errors.New("invalid transport")
+		}
+		o.transport = transport
+		return nil
+	}
+}
+
+/*
+WithTLSConfig uses a copy of the given tls config, e.g. to set InsecureSkipVerify for lab setups.
+It replaces the tls settings of options passed before it.
+*/
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(o *clientOptions) error {
+		if config == nil {
+			return errors.New("invalid tls config")
+		}
+		o.tlsConfig = config.Clone()
+		return nil
+	}
+}
+
+/*
+WithCACertFile trusts the certificate authorities in the given PEM file in addition to the system ones.
+*/
+func WithCACertFile(path string) ClientOption {
+	return func(o *clientOptions) error {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "failed to read ca cert file")
+		}
+		config := o.tls()
+		if config.RootCAs == nil {
+			config.RootCAs, err = x509.SystemCertPool()
+			if err != nil || config.RootCAs == nil {
+				config.RootCAs = x509.NewCertPool()
+			}
+		}
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return errors.New("no certificates found in ca cert file " + path)
+		}
+		return nil
+	}
+}
+
+/*
+WithClientCert authenticates the client with the certificate and key in the given PEM files, e.g. at mutual tls gateways.
+*/
+func WithClientCert(certFile, keyFile string) ClientOption {
+	return func(o *clientOptions) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to load client certificate")
+		}
+		config := o.tls()
+		config.Certificates = append(config.Certificates, cert)
+		return nil
+	}
+}
+
+/*
+WithProxy sends all requests through the given proxy, e.g. "http://proxy:3128".
+*/
+func WithProxy(proxyURL string) ClientOption {
+	return func(o *clientOptions) error {
+		proxy, err := url.Parse(proxyURL)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return errors.New("invalid proxy url " + proxyURL)
+		}
+		o.proxy = proxy
+		return nil
+	}
+}
+
+// newResty creates the resty client for the given options.
+func newResty(opts []ClientOption) (*resty.Client, error) {
+	if len(opts) == 0 {
+		return resty.New(), nil
+	}
+
+	var o clientOptions
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	httpClient := &http.Client{}
+	if o.httpClient != nil {
+		copied := *o.httpClient
+		httpClient = &copied
+	}
+	if o.transport != nil {
+		httpClient.Transport = o.transport
+	}
+	// resty.New keeps cookies between requests, e.g. session cookies of gateways, so custom clients do as well unless they bring their own jar
+	if httpClient.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create cookie jar")
+		}
+		httpClient.Jar = jar
+	}
+
+	if o.tlsConfig != nil || o.proxy != nil {
+		var transport *http.Transport
+		switch t := httpClient.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			return nil, errors.New("tls and proxy options can only be combined with an *http.Transport")
+		}
+		if o.tlsConfig != nil {
+			transport.TLSClientConfig = o.tlsConfig
+		}
+		if o.proxy != nil {
+			transport.Proxy = http.ProxyURL(o.proxy)
+		}
+		httpClient.Transport = transport
+	}
+
+	return resty.NewWithClient(httpClient), nil
+}
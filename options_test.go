@@ -0,0 +1,187 @@
+package snmpsimclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newOptionsTestHandler(requests *[]*http.Request) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r)
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`[]`))
+	})
+}
+
+// writeClientCert writes a self-signed client certificate and its key as PEM files into dir.
+func writeClientCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err, "error while generating key") {
+		t.FailNow()
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "snmpsim client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if !assert.NoError(t, err, "error while creating certificate") {
+		t.FailNow()
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if !assert.NoError(t, err, "error while marshalling key") {
+		t.FailNow()
+	}
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	assert.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestClientOptions_TLS(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewUnstartedServer(newOptionsTestHandler(&requests))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "snmpsimclient")
+	if !assert.NoError(t, err, "error while creating temp dir") {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.crt")
+	err = ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+	if !assert.NoError(t, err, "error while writing ca cert file") {
+		return
+	}
+	certFile, keyFile := writeClientCert(t, dir)
+
+	//the server certificate is not trusted by default
+	client, err := NewManagementClient(server.URL)
+	if assert.NoError(t, err, "error while creating a new api client") {
+		_, err = client.GetLabs(nil)
+		assert.Error(t, err, "no error for an untrusted server certificate")
+	}
+
+	//the server requires a client certificate
+	client, err = NewManagementClient(server.URL, WithCACertFile(caFile))
+	if assert.NoError(t, err, "error while creating a new api client") {
+		_, err = client.GetLabs(nil)
+		assert.Error(t, err, "no error without client certificate")
+	}
+
+	client, err = NewManagementClient(server.URL, WithCACertFile(caFile), WithClientCert(certFile, keyFile))
+	if assert.NoError(t, err, "error while creating a new api client") {
+		_, err = client.GetLabs(nil)
+		assert.NoError(t, err, "error with trusted ca and client certificate")
+	}
+
+	metricsClient, err := NewMetricsClient(server.URL, WithTLSConfig(&tls.Config{InsecureSkipVerify: true}), WithClientCert(certFile, keyFile))
+	if assert.NoError(t, err, "error while creating a new api client") {
+		_, err = metricsClient.GetProcesses(nil)
+		assert.NoError(t, err, "error with insecure skip verify")
+	}
+
+	_, err = NewManagementClient(server.URL, WithCACertFile(filepath.Join(dir, "missing.crt")))
+	assert.Error(t, err, "no error for missing ca cert file")
+	_, err = NewManagementClient(server.URL, WithCACertFile(keyFile))
+	assert.Error(t, err, "no error for ca cert file without certificates")
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestClientOptions_Transport(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(newOptionsTestHandler(&requests))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	client, err := NewManagementClient(server.URL, WithHTTPClient(&http.Client{Timeout: time.Second}), WithTransport(transport))
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "error during GetLabs")
+	assert.Equal(t, 1, transport.requests, "request was not sent with the custom transport")
+
+	_, err = NewManagementClient(server.URL, WithTransport(transport), WithProxy("http://127.0.0.1:3128"))
+	assert.Error(t, err, "no error when combining a custom round tripper with a proxy")
+	_, err = NewManagementClient(server.URL, WithProxy("no proxy"))
+	assert.Error(t, err, "no error for invalid proxy url")
+}
+
+func TestClientOptions_Proxy(t *testing.T) {
+	var requests []*http.Request
+	proxy := httptest.NewServer(newOptionsTestHandler(&requests))
+	defer proxy.Close()
+
+	client, err := NewManagementClient("http://snmpsim.invalid:8000", WithProxy(proxy.URL))
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	_, err = client.GetLabs(nil)
+	if assert.NoError(t, err, "error during GetLabs") && assert.Len(t, requests, 1) {
+		assert.Equal(t, "http://snmpsim.invalid:8000/snmpsim/mgmt/v1/labs", requests[0].URL.String())
+	}
+}
+
+func TestClientOptions_Cookies(t *testing.T) {
+	var cookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "1", Path: "/"})
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL, WithTransport(http.DefaultTransport))
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		_, err = client.GetLabs(nil)
+		assert.NoError(t, err, "error during GetLabs")
+	}
+	assert.Equal(t, []string{"", "session=1"}, cookies, "cookie was not sent back")
+
+	jar, _ := cookiejar.New(nil)
+	client, err = NewManagementClient(server.URL, WithHTTPClient(&http.Client{Jar: jar}))
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "error during GetLabs")
+	serverURL, _ := url.Parse(server.URL)
+	assert.Len(t, jar.Cookies(serverURL), 1, "cookie jar of the http client was not used")
+}
@@ -0,0 +1,171 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPortAllocationAttempts is the number of ports CreateEndpointAuto tries if the api rejects an allocated port.
+const maxPortAllocationAttempts = 5
+
+/*
+PortAllocator hands out free ports from a range for new endpoints, see CreateEndpointAuto.
+It is safe for concurrent use and can be shared between clients of the same api.
+*/
+type PortAllocator struct {
+	minPort int
+	maxPort int
+
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+/*
+NewPortAllocator creates a new allocator for the ports from minPort to maxPort, both inclusive.
+*/
+func NewPortAllocator(minPort, maxPort int) (*PortAllocator, error) {
+	if minPort < 1 || maxPort > 65535 || minPort > maxPort {
+		return nil, errors.New("invalid port range " + strconv.Itoa(minPort) + "-" + strconv.Itoa(maxPort))
+	}
+	return &PortAllocator{
+		minPort: minPort,
+		maxPort: maxPort,
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+/*
+SetPortAllocator sets the allocator used by CreateEndpointAuto.
+*/
+func (c *ManagementClient) SetPortAllocator(allocator *PortAllocator) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	c.portAllocator = allocator
+	return nil
+}
+
+/*
+CreateEndpointAuto creates a new endpoint on the given ip with a port which is not used by any other endpoint.
+The port is taken from the range of the port allocator set with SetPortAllocator, the search starts at a random port to avoid collisions with other processes.
+If the api rejects the port, e.g. because another process created an endpoint with the same address in the meantime, the next free port is tried.
+*/
+func (c *ManagementClient) CreateEndpointAuto(name, ip, protocol string) (Endpoint, error) {
+	return c.CreateEndpointAutoCtx(context.Background(), name, ip, protocol)
+}
+
+/*
+CreateEndpointAutoCtx is like CreateEndpointAuto but uses the given context for the requests.
+*/
+func (c *ManagementClient) CreateEndpointAutoCtx(ctx context.Context, name, ip, protocol string) (Endpoint, error) {
+	if !c.isValid() {
+		return Endpoint{}, &NotValidError{}
+	}
+	allocator := c.portAllocator
+	if allocator == nil {
+		return Endpoint{}, errors.New("no port allocator set")
+	}
+	if name == "" {
+		return Endpoint{}, errors.New("invalid name")
+	}
+	if protocol == "" {
+		protocol = "udpv4"
+	}
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return Endpoint{}, errors.New("invalid ip " + ip)
+	}
+	switch protocol {
+	case "udpv4":
+		if parsedIP.To4() == nil {
+			return Endpoint{}, errors.New("ip " + ip + " is not an ipv4 address")
+		}
+	case "udpv6":
+		if parsedIP.To4() != nil {
+			return Endpoint{}, errors.New("ip " + ip + " is not an ipv6 address")
+		}
+	default:
+		return Endpoint{}, errors.New("invalid protocol " + protocol)
+	}
+
+	//the lock keeps other goroutines from allocating the same port before the endpoint exists
+	allocator.mutex.Lock()
+	defer allocator.mutex.Unlock()
+
+	endpoints, err := c.GetEndpointsCtx(ctx, EndpointFilter{Protocol: protocol}.Params())
+	if err != nil {
+		return Endpoint{}, errors.Wrap(err, "error while getting endpoints")
+	}
+	used := usedPorts(endpoints, parsedIP, protocol)
+
+	for attempt := 1; ; attempt++ {
+		port, ok := allocator.next(used)
+		if !ok {
+			return Endpoint{}, errors.New("no free port left in range " + strconv.Itoa(allocator.minPort) + "-" + strconv.Itoa(allocator.maxPort) + " for " + ip)
+		}
+		used[port] = true
+		endpoint, err := c.CreateEndpointCtx(ctx, name, net.JoinHostPort(ip, strconv.Itoa(port)), protocol)
+		if err == nil {
+			return endpoint, nil
+		}
+		if attempt == maxPortAllocationAttempts || !isAddressConflict(err) {
+			return Endpoint{}, err
+		}
+	}
+}
+
+// isAddressConflict reports whether the api rejected an endpoint because of its address, e.g. because the port is in use already.
+// Other validation errors, e.g. of the name, would occur with every port.
+func isAddressConflict(err error) bool {
+	if !errors.Is(err, ErrValidation) && !errors.Is(err, ErrConflict) {
+		return false
+	}
+	var httpError HTTPError
+	if !errors.As(err, &httpError) || httpError.Body == nil {
+		return false
+	}
+	message := strings.ToLower(httpError.Body.Message)
+	return strings.Contains(message, "address") || strings.Contains(message, "port")
+}
+
+// next returns a random port of the range which is not used, it continues with the following ports if the random port is used.
+func (p *PortAllocator) next(used map[int]bool) (int, bool) {
+	size := p.maxPort - p.minPort + 1
+	start := p.random.Intn(size)
+	for i := 0; i < size; i++ {
+		port := p.minPort + (start+i)%size
+		if !used[port] {
+			return port, true
+		}
+	}
+	return 0, false
+}
+
+// usedPorts returns the ports of all endpoints of the protocol which are bound to the ip or to all ips.
+func usedPorts(endpoints Endpoints, ip net.IP, protocol string) map[int]bool {
+	used := make(map[int]bool)
+	for _, endpoint := range endpoints {
+		if endpoint.Protocol != protocol {
+			continue
+		}
+		host, port, err := net.SplitHostPort(endpoint.Address)
+		if err != nil {
+			continue
+		}
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			continue
+		}
+		endpointIP := net.ParseIP(host)
+		if endpointIP == nil || endpointIP.Equal(ip) || endpointIP.IsUnspecified() || ip.IsUnspecified() {
+			used[p] = true
+		}
+	}
+	return used
+}
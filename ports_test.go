@@ -0,0 +1,173 @@
+package snmpsimclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewPortAllocator(t *testing.T) {
+	_, err := NewPortAllocator(0, 100)
+	assert.Error(t, err, "no error for port 0")
+	_, err = NewPortAllocator(2000, 1000)
+	assert.Error(t, err, "no error for empty range")
+	_, err = NewPortAllocator(1000, 70000)
+	assert.Error(t, err, "no error for port out of range")
+	_, err = NewPortAllocator(1161, 1161)
+	assert.NoError(t, err, "error for range with a single port")
+}
+
+func TestManagementClient_CreateEndpointAuto(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	_, err = client.CreateEndpointAuto("endpoint", "127.0.0.1", "udpv4")
+	assert.Error(t, err, "no error without port allocator")
+
+	allocator, err := NewPortAllocator(20000, 20019)
+	if !assert.NoError(t, err, "error while creating port allocator") {
+		return
+	}
+	assert.NoError(t, client.SetPortAllocator(allocator))
+
+	_, err = client.CreateEndpoint("taken", "127.0.0.1:20000", "udpv4")
+	assert.NoError(t, err, "error during CreateEndpoint")
+	_, err = client.CreateEndpoint("wildcard", "0.0.0.0:20001", "udpv4")
+	assert.NoError(t, err, "error during CreateEndpoint")
+	_, err = client.CreateEndpoint("other ip", "127.0.0.2:20002", "udpv4")
+	assert.NoError(t, err, "error during CreateEndpoint")
+	_, err = client.CreateEndpoint("ipv6", "[::1]:20003", "udpv6")
+	assert.NoError(t, err, "error during CreateEndpoint")
+
+	//18 ports are free for 127.0.0.1, they are allocated concurrently
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	ports := make(map[int]bool)
+	for i := 0; i < 18; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			endpoint, err := client.CreateEndpointAuto("auto"+strconv.Itoa(i), "127.0.0.1", "udpv4")
+			if !assert.NoError(t, err, "error during CreateEndpointAuto") {
+				return
+			}
+			_, port, err := net.SplitHostPort(endpoint.Address)
+			assert.NoError(t, err, "invalid address "+endpoint.Address)
+			p, _ := strconv.Atoi(port)
+			mutex.Lock()
+			defer mutex.Unlock()
+			assert.False(t, ports[p], "port "+port+" was allocated twice")
+			ports[p] = true
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, ports, 18)
+	assert.False(t, ports[20000], "port of existing endpoint was allocated")
+	assert.False(t, ports[20001], "port of wildcard endpoint was allocated")
+	assert.True(t, ports[20002], "port of endpoint on another ip was not allocated")
+	assert.True(t, ports[20003], "port of endpoint with another protocol was not allocated")
+
+	_, err = client.CreateEndpointAuto("full", "127.0.0.1", "udpv4")
+	assert.Error(t, err, "no error for exhausted port range")
+	endpoint, err := client.CreateEndpointAuto("ipv6 auto", "::1", "udpv6")
+	if assert.NoError(t, err, "error during CreateEndpointAuto for ipv6") {
+		assert.Equal(t, "udpv6", endpoint.Protocol)
+		assert.NotEqual(t, "[::1]:20003", endpoint.Address)
+	}
+
+	_, err = client.CreateEndpointAuto("mismatch", "::1", "udpv4")
+	assert.Error(t, err, "no error for ipv6 address with udpv4")
+	_, err = client.CreateEndpointAuto("invalid", "localhost", "udpv4")
+	assert.Error(t, err, "no error for invalid ip")
+}
+
+// racingTransport creates an endpoint with the same address before the first endpoint of the client is created, like another process would.
+type racingTransport struct {
+	other *ManagementClient
+	raced bool
+}
+
+func (r *racingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/endpoints") && !r.raced {
+		r.raced = true
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		var endpoint Endpoint
+		if err := json.Unmarshal(body, &endpoint); err != nil {
+			return nil, err
+		}
+		if _, err := r.other.CreateEndpoint("other process", endpoint.Address, endpoint.Protocol); err != nil {
+			return nil, err
+		}
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestManagementClient_CreateEndpointAuto_Retry(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	other, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	transport := &racingTransport{other: other}
+	client, err := NewManagementClient(server.URL, WithTransport(transport))
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	allocator, err := NewPortAllocator(30000, 30001)
+	if !assert.NoError(t, err, "error while creating port allocator") {
+		return
+	}
+	assert.NoError(t, client.SetPortAllocator(allocator))
+
+	endpoint, err := client.CreateEndpointAuto("endpoint", "127.0.0.1", "udpv4")
+	if assert.NoError(t, err, "error during CreateEndpointAuto") {
+		assert.True(t, transport.raced)
+		endpoints, err := client.GetEndpoints(nil)
+		if assert.NoError(t, err, "error during GetEndpoints") && assert.Len(t, endpoints, 2) {
+			assert.NotEqual(t, endpoints[0].Address, endpoints[1].Address)
+			assert.Contains(t, []string{"127.0.0.1:30000", "127.0.0.1:30001"}, endpoint.Address)
+		}
+	}
+}
+
+func TestManagementClient_CreateEndpointAuto_NoRetry(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	allocator, err := NewPortAllocator(30000, 30010)
+	if !assert.NoError(t, err, "error while creating port allocator") {
+		return
+	}
+	assert.NoError(t, client.SetPortAllocator(allocator))
+
+	server.AddFault(snmpsimtest.Fault{Method: "POST", Path: "/snmpsim/mgmt/v1/endpoints", StatusCode: 400, Message: "invalid name", Count: 1})
+	_, err = client.CreateEndpointAuto("endpoint", "127.0.0.1", "udpv4")
+	assert.True(t, errors.Is(err, ErrValidation), "validation error of the name was retried with another port")
+
+	server.AddFault(snmpsimtest.Fault{Method: "POST", Path: "/snmpsim/mgmt/v1/endpoints", StatusCode: 409, Message: "address 127.0.0.1 is already in use", Count: 1})
+	_, err = client.CreateEndpointAuto("endpoint", "127.0.0.1", "udpv4")
+	assert.NoError(t, err, "conflict of the address was not retried with another port")
+}
@@ -0,0 +1,346 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/soniah/gosnmp"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+PowerOnOptions configures how PowerOnAndWait checks whether a lab is ready.
+*/
+type PowerOnOptions struct {
+	// MetricsClient is used to wait until every endpoint of the lab is served by a snmpsim process.
+	// If nil, the process list is not checked.
+	MetricsClient *MetricsClient
+	// VerifySNMP enables an SNMP GET against every endpoint of the lab, the endpoint is ready once it answers.
+	VerifySNMP bool
+	// OID is requested by the SNMP GET, by default sysDescr.0. Any answer counts, even noSuchObject.
+	OID string
+	// Community is used for SNMPv2c requests, by default the community of the first record file in the data dir of the agent.
+	Community string
+	// Timeout limits the whole wait including powering on the lab, by default 60s.
+	Timeout time.Duration
+	// PollInterval is the time between two checks, by default 1s.
+	PollInterval time.Duration
+	// SNMPTimeout is the timeout of a single SNMP GET, by default 1s.
+	SNMPTimeout time.Duration
+}
+
+func (o PowerOnOptions) withDefaults() PowerOnOptions {
+	if o.OID == "" {
+		o.OID = "1.3.6.1.2.1.1.1.0"
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 60 * time.Second
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.SNMPTimeout <= 0 {
+		o.SNMPTimeout = time.Second
+	}
+	return o
+}
+
+/*
+EndpointReadiness is the readiness of a single endpoint of a lab.
+*/
+type EndpointReadiness struct {
+	AgentID    int
+	EngineID   int
+	EndpointID int
+	Protocol   string
+	Address    string
+	// Served is true if the endpoint is served by a snmpsim process according to the metrics api.
+	Served bool
+	// Responding is true if the endpoint answered the SNMP GET.
+	Responding bool
+	// Ready is true if all enabled checks succeeded.
+	Ready bool
+	// Err is the error of the last failed check.
+	Err error
+}
+
+/*
+LabReadiness is the readiness report of all endpoints of a lab.
+*/
+type LabReadiness []EndpointReadiness
+
+/*
+Ready returns true if all endpoints are ready.
+*/
+func (l LabReadiness) Ready() bool {
+	for _, endpoint := range l {
+		if !endpoint.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+LabNotReadyError is returned by PowerOnAndWait if the lab was not ready before the timeout.
+*/
+type LabNotReadyError struct {
+	LabID     int
+	Readiness LabReadiness
+}
+
+func (l *LabNotReadyError) Error() string {
+	var notReady []string
+	for _, endpoint := range l.Readiness {
+		if endpoint.Ready {
+			continue
+		}
+		msg := endpoint.Address
+		if endpoint.Err != nil {
+			msg += " (" + endpoint.Err.Error() + ")"
+		}
+		notReady = append(notReady, msg)
+	}
+	return "lab " + strconv.Itoa(l.LabID) + " was not ready in time, endpoints not ready: " + strings.Join(notReady, ", ")
+}
+
+/*
+PowerOnAndWait powers on a lab and waits until all of its endpoints are ready.
+Depending on the options an endpoint is ready once it is served by a snmpsim process and/or answers an SNMP GET.
+The readiness report is returned in any case, a LabNotReadyError is returned if the lab was not ready before the timeout.
+*/
+func (c *ManagementClient) PowerOnAndWait(labID int, opts PowerOnOptions) (LabReadiness, error) {
+	return c.PowerOnAndWaitCtx(context.Background(), labID, opts)
+}
+
+/*
+PowerOnAndWaitCtx is like PowerOnAndWait but uses the given context for the requests.
+*/
+func (c *ManagementClient) PowerOnAndWaitCtx(ctx context.Context, labID int, opts PowerOnOptions) (LabReadiness, error) {
+	if !c.isValid() {
+		return nil, &NotValidError{}
+	}
+	if opts.MetricsClient == nil && !opts.VerifySNMP {
+		return nil, errors.New("neither a metrics client nor snmp verification configured, nothing to wait for")
+	}
+	opts = opts.withDefaults()
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	err := c.SetLabPowerCtx(ctx, labID, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while powering on lab")
+	}
+
+	targets, err := c.readinessTargets(ctx, labID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		readiness := checkReadiness(ctx, targets, opts)
+		if readiness.Ready() {
+			return readiness, nil
+		}
+		select {
+		case <-ctx.Done():
+			return readiness, &LabNotReadyError{LabID: labID, Readiness: readiness}
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+// readinessTarget is an endpoint of a lab and the credentials which are used to verify it.
+type readinessTarget struct {
+	readiness EndpointReadiness
+	community string
+	users     Users
+}
+
+func (c *ManagementClient) readinessTargets(ctx context.Context, labID int, opts PowerOnOptions) ([]readinessTarget, error) {
+	lab, err := c.GetLabCtx(ctx, labID)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while getting lab")
+	}
+
+	var recordings Recordings
+	if opts.VerifySNMP && opts.Community == "" {
+		recordings, err = c.GetRecordFilesCtx(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while getting record files")
+		}
+	}
+
+	var targets []readinessTarget
+	for _, agent := range lab.Agents {
+		community := opts.Community
+		if community == "" {
+			community = communityOfDataDir(recordings, agent.DataDir)
+		}
+		for _, engine := range agent.Engines {
+			for _, endpoint := range engine.Endpoints {
+				targets = append(targets, readinessTarget{
+					readiness: EndpointReadiness{
+						AgentID:    agent.ID,
+						EngineID:   engine.ID,
+						EndpointID: endpoint.ID,
+						Protocol:   endpoint.Protocol,
+						Address:    endpoint.Address,
+					},
+					community: community,
+					users:     engine.Users,
+				})
+			}
+		}
+	}
+	return targets, nil
+}
+
+// communityOfDataDir returns the community of the first record file in the given data dir, or public if the data dir contains a public record file.
+func communityOfDataDir(recordings Recordings, dataDir string) string {
+	communities := communitiesOfDataDir(recordings, dataDir)
+	if len(communities) == 0 || containsString(communities, "public") {
+		return "public"
+	}
+	return communities[0]
+}
+
+func checkReadiness(ctx context.Context, targets []readinessTarget, opts PowerOnOptions) LabReadiness {
+	readiness := make(LabReadiness, len(targets))
+	for i, target := range targets {
+		readiness[i] = target.readiness
+	}
+
+	if opts.MetricsClient != nil {
+		served, err := servedAddresses(ctx, opts.MetricsClient)
+		for i := range readiness {
+			readiness[i].Served = served[readiness[i].Address]
+			if err != nil {
+				readiness[i].Err = err
+			} else if !readiness[i].Served {
+				readiness[i].Err = errors.New("endpoint is not served by a snmpsim process")
+			}
+		}
+	}
+
+	for i, target := range targets {
+		if opts.MetricsClient != nil && !readiness[i].Served {
+			continue
+		}
+		if opts.VerifySNMP {
+			if err := verifySNMP(target, opts); err != nil {
+				readiness[i].Err = err
+				continue
+			}
+			readiness[i].Responding = true
+		}
+		readiness[i].Ready = true
+		readiness[i].Err = nil
+	}
+	return readiness
+}
+
+// servedAddresses returns the addresses of all endpoints of all snmpsim processes.
+func servedAddresses(ctx context.Context, client *MetricsClient) (map[string]bool, error) {
+	processes, err := client.GetProcessesCtx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while getting processes")
+	}
+	served := make(map[string]bool)
+	for _, process := range processes {
+		endpoints, err := client.GetProcessEndpointsCtx(ctx, process.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while getting process endpoints")
+		}
+		for _, endpoint := range endpoints {
+			served[endpoint.Address] = true
+		}
+	}
+	return served, nil
+}
+
+// verifySNMP sends an SNMP GET to the endpoint, it uses the first user of the engine gosnmp supports or SNMPv2c otherwise.
+func verifySNMP(target readinessTarget, opts PowerOnOptions) error {
+	host, portString, err := net.SplitHostPort(target.readiness.Address)
+	if err != nil {
+		return errors.Wrap(err, "invalid endpoint address")
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return errors.Wrap(err, "invalid endpoint port")
+	}
+
+	snmp := &gosnmp.GoSNMP{
+		Target:    host,
+		Port:      uint16(port),
+		Transport: "udp",
+		Timeout:   opts.SNMPTimeout,
+		Version:   gosnmp.Version2c,
+		Community: target.community,
+	}
+	if target.readiness.Protocol == "udpv6" {
+		snmp.Transport = "udp6"
+	}
+	for _, user := range target.users {
+		if setSNMPv3User(snmp, user, target.community) {
+			break
+		}
+	}
+
+	if err := snmp.Connect(); err != nil {
+		return errors.Wrap(err, "error during snmp connect")
+	}
+	defer snmp.Conn.Close()
+
+	_, err = snmp.Get([]string{opts.OID})
+	return errors.Wrap(err, "error during snmp get")
+}
+
+// setSNMPv3User configures the snmp client to use the given user, it returns false if gosnmp does not support its protocols.
+func setSNMPv3User(snmp *gosnmp.GoSNMP, user User, community string) bool {
+	authProtocols := map[AuthProtocol]gosnmp.SnmpV3AuthProtocol{AuthNone: gosnmp.NoAuth, AuthMD5: gosnmp.MD5, AuthSHA: gosnmp.SHA}
+	privProtocols := map[PrivProtocol]gosnmp.SnmpV3PrivProtocol{PrivNone: gosnmp.NoPriv, PrivDES: gosnmp.DES, PrivAES: gosnmp.AES, PrivAES128: gosnmp.AES}
+
+	auth, err := ParseAuthProtocol(user.AuthProto)
+	if err != nil {
+		return false
+	}
+	authProtocol, ok := authProtocols[auth]
+	if !ok {
+		return false
+	}
+	priv, err := ParsePrivProtocol(user.PrivProto)
+	if err != nil {
+		return false
+	}
+	privProtocol, ok := privProtocols[priv]
+	if !ok {
+		return false
+	}
+
+	msgFlags := gosnmp.NoAuthNoPriv
+	if authProtocol != gosnmp.NoAuth {
+		msgFlags = gosnmp.AuthNoPriv
+		if privProtocol != gosnmp.NoPriv {
+			msgFlags = gosnmp.AuthPriv
+		}
+	} else if privProtocol != gosnmp.NoPriv {
+		return false
+	}
+
+	snmp.Version = gosnmp.Version3
+	snmp.SecurityModel = gosnmp.UserSecurityModel
+	snmp.MsgFlags = msgFlags
+	snmp.ContextName = community
+	snmp.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		UserName:                 user.User,
+		AuthenticationProtocol:   authProtocol,
+		AuthenticationPassphrase: user.AuthKey,
+		PrivacyProtocol:          privProtocol,
+		PrivacyPassphrase:        user.PrivKey,
+	}
+	return true
+}
@@ -0,0 +1,192 @@
+package snmpsimclient
+
+import (
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// startGetResponder starts a minimal SNMPv2c responder which answers every GET for the given community.
+func startGetResponder(t *testing.T, community string) (string, func()) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if !assert.NoError(t, err, "error while starting snmp responder") {
+		t.FailNow()
+	}
+
+	go func() {
+		buf := make([]byte, 65535)
+		decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c}
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			// gosnmp cannot decode GET requests, so the pdu type is changed to GETNEXT before decoding
+			packet := append([]byte{}, buf[:n]...)
+			i := pduTypeOffset(packet)
+			if i == -1 || packet[i] != byte(gosnmp.GetRequest) {
+				continue
+			}
+			packet[i] = byte(gosnmp.GetNextRequest)
+			request, err := decoder.SnmpDecodePacket(packet)
+			if err != nil || request.Community != community {
+				continue
+			}
+			response := &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version2c,
+				Community: request.Community,
+				PDUType:   gosnmp.GetResponse,
+				RequestID: request.RequestID,
+				Variables: []gosnmp.SnmpPDU{{Name: request.Variables[0].Name, Type: gosnmp.OctetString, Value: []byte("test")}},
+			}
+			out, err := response.MarshalMsg()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(out, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String(), func() { _ = conn.Close() }
+}
+
+// pduTypeOffset returns the offset of the pdu type in a SNMPv1/v2c message, which follows the version and the community.
+func pduTypeOffset(packet []byte) int {
+	i := 0
+	skipHeader := func() int {
+		if i+1 >= len(packet) {
+			return -1
+		}
+		length := int(packet[i+1])
+		i += 2
+		if length&0x80 != 0 {
+			bytes := length & 0x7f
+			length = 0
+			for ; bytes > 0 && i < len(packet); bytes-- {
+				length = length<<8 | int(packet[i])
+				i++
+			}
+		}
+		return length
+	}
+	if skipHeader() == -1 {
+		return -1
+	}
+	for field := 0; field < 2; field++ {
+		length := skipHeader()
+		if length == -1 {
+			return -1
+		}
+		i += length
+	}
+	if i >= len(packet) {
+		return -1
+	}
+	return i
+}
+
+func createPowerTestLab(t *testing.T, client *ManagementClient, address string) (Lab, bool) {
+	lab, err := client.CreateLab("powerLab")
+	if !assert.NoError(t, err, "error while creating lab") {
+		return Lab{}, false
+	}
+	agent, err := client.CreateAgent("powerAgent", "power")
+	if !assert.NoError(t, err, "error while creating agent") {
+		return Lab{}, false
+	}
+	engine, err := client.CreateEngine("powerEngine", "auto")
+	if !assert.NoError(t, err, "error while creating engine") {
+		return Lab{}, false
+	}
+	endpoint, err := client.CreateEndpoint("powerEndpoint", address, "udpv4")
+	if !assert.NoError(t, err, "error while creating endpoint") {
+		return Lab{}, false
+	}
+	if !assert.NoError(t, client.AddEndpointToEngine(engine.ID, endpoint.ID), "error while adding endpoint to engine") ||
+		!assert.NoError(t, client.AddEngineToAgent(agent.ID, engine.ID), "error while adding engine to agent") ||
+		!assert.NoError(t, client.AddAgentToLab(lab.ID, agent.ID), "error while adding agent to lab") {
+		return Lab{}, false
+	}
+	return lab, true
+}
+
+func TestManagementClient_PowerOnAndWait(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	address, stop := startGetResponder(t, "device")
+	defer stop()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	metricsClient, err := NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab, ok := createPowerTestLab(t, client, address)
+	if !ok {
+		return
+	}
+	recordFile := "1.3.6.1.2.1.1.1.0|4|test\n"
+	if !assert.NoError(t, client.UploadRecordFileString(&recordFile, "power/device.snmprec"), "error while uploading record file") {
+		return
+	}
+
+	_, err = client.PowerOnAndWait(lab.ID, PowerOnOptions{})
+	assert.Error(t, err, "no error without any check")
+
+	readiness, err := client.PowerOnAndWait(lab.ID, PowerOnOptions{MetricsClient: metricsClient, VerifySNMP: true, Timeout: 5 * time.Second, PollInterval: 10 * time.Millisecond})
+	if assert.NoError(t, err, "error during PowerOnAndWait") && assert.Len(t, readiness, 1) {
+		assert.True(t, readiness.Ready())
+		assert.Equal(t, address, readiness[0].Address)
+		assert.True(t, readiness[0].Served, "endpoint is not served")
+		assert.True(t, readiness[0].Responding, "endpoint is not responding")
+	}
+
+	lab, err = client.GetLab(lab.ID)
+	if assert.NoError(t, err, "error while getting lab") {
+		assert.Equal(t, "on", lab.Power)
+	}
+}
+
+func TestManagementClient_PowerOnAndWait_Timeout(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	// reserve a port on which nothing answers
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if !assert.NoError(t, err, "error while reserving udp port") {
+		return
+	}
+	address := conn.LocalAddr().String()
+	_ = conn.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab, ok := createPowerTestLab(t, client, address)
+	if !ok {
+		return
+	}
+
+	readiness, err := client.PowerOnAndWait(lab.ID, PowerOnOptions{VerifySNMP: true, Community: "public", Timeout: 300 * time.Millisecond,
+		PollInterval: 10 * time.Millisecond, SNMPTimeout: 50 * time.Millisecond})
+	if assert.Error(t, err, "no error for a lab which does not answer") {
+		notReady, ok := err.(*LabNotReadyError)
+		if assert.True(t, ok, "error is not a LabNotReadyError") {
+			assert.Equal(t, lab.ID, notReady.LabID)
+			assert.Contains(t, notReady.Error(), "lab "+strconv.Itoa(lab.ID)+" was not ready in time")
+		}
+	}
+	if assert.Len(t, readiness, 1) {
+		assert.False(t, readiness[0].Responding)
+		assert.Error(t, readiness[0].Err)
+	}
+}
@@ -0,0 +1,177 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+RetryPolicy configures how often and when failed requests are repeated.
+Requests are retried if the http request itself failed with a transient network error, e.g. a timeout or a refused connection, or the api answered with one of the RetryableStatusCodes.
+By default only the idempotent methods GET, PUT and DELETE are retried.
+*/
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts per request, including the first one.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff limits the time to wait between two attempts.
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff grows with after each attempt.
+	Multiplier float64
+	// Jitter randomizes each backoff by up to the given fraction (0 to 1) in both directions.
+	Jitter float64
+	// RetryableStatusCodes are the http status codes which cause a retry.
+	RetryableStatusCodes []int
+	// RetryPost enables retries for POST requests, which are not idempotent and might create objects twice.
+	RetryPost bool
+	// OnRetry is called before waiting for each retry.
+	OnRetry func(event RetryEvent)
+}
+
+/*
+RetryEvent describes a failed attempt which is going to be retried.
+*/
+type RetryEvent struct {
+	// Attempt is the number of the failed attempt, starting with 1.
+	Attempt int
+	Method  string
+	Path    string
+	// StatusCode is the http status code of the failed attempt or 0 if the http request itself failed.
+	StatusCode int
+	// Err is the error of the failed attempt or nil if the api answered with a retryable status code.
+	Err error
+	// Backoff is the time which is waited before the next attempt.
+	Backoff time.Duration
+}
+
+/*
+DefaultRetryPolicy returns a policy with 3 attempts and exponential backoff starting at 200ms,
+which retries on 502, 503 and 504 responses.
+*/
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       200 * time.Millisecond,
+		MaxBackoff:           5 * time.Second,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{502, 503, 504},
+	}
+}
+
+/*
+SetRetryPolicy sets the retry policy which is applied to every request of the client.
+*/
+func (c *client) SetRetryPolicy(policy RetryPolicy) error {
+	if !c.isValid() {
+		return &NotValidError{}
+	}
+	if policy.MaxAttempts < 1 {
+		return errors.New("invalid max attempts")
+	}
+	if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+		return errors.New("invalid backoff")
+	}
+	if policy.Multiplier < 1 {
+		return errors.New("invalid multiplier")
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return errors.New("invalid jitter")
+	}
+	codes := make([]int, len(policy.RetryableStatusCodes))
+	copy(codes, policy.RetryableStatusCodes)
+	policy.RetryableStatusCodes = codes
+	c.retryPolicy = &policy
+	return nil
+}
+
+/*
+DisableRetries removes the retry policy, so that every request is sent exactly once.
+*/
+func (c *client) DisableRetries() {
+	if c.isValid() {
+		c.retryPolicy = nil
+	}
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, response *resty.Response, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if method == "POST" && !p.RetryPost {
+		return false
+	}
+	if err != nil {
+		return isTransientError(err)
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if response.StatusCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isTransientError reports whether err is a network error which might not occur on the next attempt, e.g. while the api restarts.
+func isTransientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && (netErr.Timeout() || netErr.Temporary()) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// wait notifies the retry hook and sleeps for the backoff of the given attempt.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int, method, path string, response *resty.Response, err error) error {
+	backoff := p.backoff(attempt)
+	if p.OnRetry != nil {
+		event := RetryEvent{
+			Attempt: attempt,
+			Method:  method,
+			Path:    path,
+			Err:     err,
+			Backoff: backoff,
+		}
+		if response != nil {
+			event.StatusCode = response.StatusCode()
+		}
+		p.OnRetry(event)
+	}
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var (
+	jitterRand      = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterRandMutex sync.Mutex
+)
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitterRandMutex.Lock()
+		backoff += backoff * p.Jitter * (2*jitterRand.Float64() - 1)
+		jitterRandMutex.Unlock()
+	}
+	return time.Duration(backoff)
+}
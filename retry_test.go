@@ -0,0 +1,161 @@
+package snmpsimclient
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newRetryTestServer(failures int, status int, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if *requests <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		switch r.Method {
+		case "POST":
+			w.WriteHeader(201)
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		default:
+			w.WriteHeader(200)
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+}
+
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	return policy
+}
+
+func TestClient_RetryPolicy(t *testing.T) {
+	requests := 0
+	server := newRetryTestServer(2, 503, &requests)
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	var events []RetryEvent
+	policy := testRetryPolicy()
+	policy.OnRetry = func(event RetryEvent) {
+		events = append(events, event)
+	}
+	if !assert.NoError(t, client.SetRetryPolicy(policy), "error while setting retry policy") {
+		return
+	}
+
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "error although the request succeeded on the third attempt")
+	assert.Equal(t, 3, requests)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, 1, events[0].Attempt)
+		assert.Equal(t, 503, events[0].StatusCode)
+		assert.Equal(t, "GET", events[1].Method)
+	}
+}
+
+func TestClient_RetryPolicyMaxAttempts(t *testing.T) {
+	requests := 0
+	server := newRetryTestServer(5, 502, &requests)
+	defer server.Close()
+
+	client, err := NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	if !assert.NoError(t, client.SetRetryPolicy(testRetryPolicy()), "error while setting retry policy") {
+		return
+	}
+
+	_, err = client.GetProcesses(nil)
+	if assert.Error(t, err, "no error although all attempts failed") {
+		if err, ok := err.(HTTPError); assert.True(t, ok, "error is not a http error", err.Error()) {
+			assert.Equal(t, 502, err.StatusCode)
+		}
+	}
+	assert.Equal(t, 3, requests)
+}
+
+func TestClient_RetryPolicyPost(t *testing.T) {
+	requests := 0
+	server := newRetryTestServer(1, 503, &requests)
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	policy := testRetryPolicy()
+	if !assert.NoError(t, client.SetRetryPolicy(policy), "error while setting retry policy") {
+		return
+	}
+
+	_, err = client.CreateLab("lab")
+	assert.Error(t, err, "post request was retried without opt-in")
+	assert.Equal(t, 1, requests)
+
+	requests = 0
+	policy.RetryPost = true
+	if !assert.NoError(t, client.SetRetryPolicy(policy), "error while setting retry policy") {
+		return
+	}
+	_, err = client.CreateLab("lab")
+	assert.NoError(t, err, "post request was not retried with opt-in")
+	assert.Equal(t, 2, requests)
+
+	policy.MaxAttempts = 0
+	assert.Error(t, client.SetRetryPolicy(policy), "no error for an invalid retry policy")
+}
+
+type retryTestTransport struct {
+	err      error
+	requests int
+}
+
+func (t *retryTestTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.requests++
+	return nil, t.err
+}
+
+type retryTestTimeout struct{}
+
+func (retryTestTimeout) Error() string   { return "i/o timeout" }
+func (retryTestTimeout) Timeout() bool   { return true }
+func (retryTestTimeout) Temporary() bool { return true }
+
+func TestClient_RetryPolicyErrors(t *testing.T) {
+	transport := &retryTestTransport{err: errors.New("certificate signed by unknown authority")}
+	client, err := NewManagementClient("http://snmpsim.invalid:8000", WithTransport(transport))
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	if !assert.NoError(t, client.SetRetryPolicy(testRetryPolicy()), "error while setting retry policy") {
+		return
+	}
+
+	_, err = client.GetLabs(nil)
+	assert.Error(t, err, "no error although the request failed")
+	assert.Equal(t, 1, transport.requests, "non-transient error was retried")
+
+	transport.requests = 0
+	transport.err = retryTestTimeout{}
+	_, err = client.GetLabs(nil)
+	assert.Error(t, err, "no error although all attempts failed")
+	assert.Equal(t, 3, transport.requests, "timeout was not retried")
+
+	transport.requests = 0
+	transport.err = &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	_, err = client.GetLabs(nil)
+	assert.Error(t, err, "no error although all attempts failed")
+	assert.Equal(t, 3, transport.requests, "refused connection was not retried")
+}
@@ -0,0 +1,142 @@
+package snmprec
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/soniah/gosnmp"
+	"net"
+	"strings"
+)
+
+// errMaxRecords stops a walk when the maximum number of records is reached.
+var errMaxRecords = errors.New("maximum number of records reached")
+
+/*
+Recorder walks a live SNMP agent and records all values as records, e.g. to clone a device into the data dir of a simulated agent.
+*/
+type Recorder struct {
+	// SNMP is the client which is used to walk the agent, it contains the target, the SNMP version, credentials, timeouts and retries.
+	// SNMPv1 agents are walked with GETNEXT, all others with GETBULK and SNMP.MaxRepetitions.
+	// It is connected by Record if it is not connected yet.
+	SNMP *gosnmp.GoSNMP
+	// Subtrees contains the OIDs of the subtrees which are walked, by default the whole 1.3.6.1 tree is walked.
+	Subtrees []string
+	// MaxRecords limits the number of records, 0 means no limit.
+	MaxRecords int
+}
+
+/*
+NewRecorder creates a new recorder for the agent which is configured in the given SNMP client.
+*/
+func NewRecorder(snmp *gosnmp.GoSNMP, subtrees ...string) *Recorder {
+	return &Recorder{SNMP: snmp, Subtrees: subtrees}
+}
+
+/*
+Record walks all subtrees of the agent and returns the records sorted by their OIDs.
+Values which cannot be stored in a record file, e.g. opaque floats, are skipped.
+*/
+func (r *Recorder) Record() (Records, error) {
+	return r.RecordCtx(context.Background())
+}
+
+/*
+RecordCtx is like Record but stops walking when the given context is done.
+*/
+func (r *Recorder) RecordCtx(ctx context.Context) (Records, error) {
+	if r.SNMP == nil {
+		return nil, errors.New("no snmp client")
+	}
+	if r.SNMP.Conn == nil {
+		if err := r.SNMP.Connect(); err != nil {
+			return nil, errors.Wrap(err, "error during snmp connect")
+		}
+		defer func() {
+			_ = r.SNMP.Conn.Close()
+			r.SNMP.Conn = nil
+		}()
+	}
+
+	subtrees := r.Subtrees
+	if len(subtrees) == 0 {
+		subtrees = []string{"1.3.6.1"}
+	}
+
+	var records Records
+	seen := make(map[string]bool)
+	walkFn := func(pdu gosnmp.SnmpPDU) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		record, ok := recordFromPDU(pdu)
+		if !ok || seen[record.OID] {
+			return nil
+		}
+		if r.MaxRecords > 0 && len(records) >= r.MaxRecords {
+			return errMaxRecords
+		}
+		seen[record.OID] = true
+		records = append(records, record)
+		return nil
+	}
+
+	for _, subtree := range subtrees {
+		if err := ValidateOID(strings.TrimPrefix(subtree, ".")); err != nil {
+			return nil, errors.Wrap(err, "invalid subtree")
+		}
+		var err error
+		if r.SNMP.Version == gosnmp.Version1 {
+			err = r.SNMP.Walk(subtree, walkFn)
+		} else {
+			err = r.SNMP.BulkWalk(subtree, walkFn)
+		}
+		if err == errMaxRecords {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error while walking subtree "+subtree)
+		}
+	}
+	records.Sort()
+	return records, nil
+}
+
+// recordFromPDU converts a variable of an SNMP response into a record, ok is false if the value cannot be recorded.
+func recordFromPDU(pdu gosnmp.SnmpPDU) (record Record, ok bool) {
+	oid := strings.TrimPrefix(pdu.Name, ".")
+	switch pdu.Type {
+	case gosnmp.Integer:
+		if i, isInt := pdu.Value.(int); isInt {
+			return NewInteger(oid, int32(i)), true
+		}
+	case gosnmp.OctetString:
+		if b, isBytes := pdu.Value.([]byte); isBytes {
+			return NewOctetString(oid, b), true
+		}
+	case gosnmp.ObjectIdentifier:
+		if s, isString := pdu.Value.(string); isString {
+			return NewObjectIdentifier(oid, s), true
+		}
+	case gosnmp.IPAddress:
+		if s, isString := pdu.Value.(string); isString {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				return NewIPAddress(oid, ip.To4()), true
+			}
+		}
+	case gosnmp.Counter32:
+		return Record{OID: oid, Type: Counter32, Value: gosnmp.ToBigInt(pdu.Value).String()}, true
+	case gosnmp.Gauge32, gosnmp.Uinteger32:
+		return Record{OID: oid, Type: Gauge32, Value: gosnmp.ToBigInt(pdu.Value).String()}, true
+	case gosnmp.TimeTicks:
+		return Record{OID: oid, Type: TimeTicks, Value: gosnmp.ToBigInt(pdu.Value).String()}, true
+	case gosnmp.Counter64:
+		return Record{OID: oid, Type: Counter64, Value: gosnmp.ToBigInt(pdu.Value).String()}, true
+	case gosnmp.Opaque:
+		if b, isBytes := pdu.Value.([]byte); isBytes {
+			return NewOpaque(oid, b), true
+		}
+	case gosnmp.Null:
+		return NewNull(oid), true
+	}
+	return Record{}, false
+}
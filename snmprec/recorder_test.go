@@ -0,0 +1,135 @@
+package snmprec
+
+import (
+	"context"
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startResponder starts a minimal SNMPv2c responder which answers GETNEXT and GETBULK requests from the given records.
+func startResponder(t *testing.T, records Records) (uint16, func()) {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if !assert.NoError(t, err, "error while starting snmp responder") {
+		t.FailNow()
+	}
+	records.Sort()
+
+	go func() {
+		buf := make([]byte, 65535)
+		decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c}
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request, err := decoder.SnmpDecodePacket(buf[:n])
+			if err != nil {
+				continue
+			}
+			count := 1
+			if request.PDUType == gosnmp.GetBulkRequest {
+				count = int(request.MaxRepetitions)
+			}
+			response := &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version2c,
+				Community: request.Community,
+				PDUType:   gosnmp.GetResponse,
+				RequestID: request.RequestID,
+			}
+			oid := strings.TrimPrefix(request.Variables[0].Name, ".")
+			for _, record := range records {
+				if len(response.Variables) == count {
+					break
+				}
+				if CompareOIDs(record.OID, oid) <= 0 {
+					continue
+				}
+				response.Variables = append(response.Variables, pduFromRecord(record))
+			}
+			if len(response.Variables) == 0 {
+				// gosnmp can neither marshal endOfMibView nor an error status, so the end of the mib is reported with an oid outside of all walked subtrees
+				response.Variables = []gosnmp.SnmpPDU{{Name: ".2.0", Type: gosnmp.Null}}
+			}
+			out, err := response.MarshalMsg()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteTo(out, addr)
+		}
+	}()
+
+	return uint16(conn.LocalAddr().(*net.UDPAddr).Port), func() { _ = conn.Close() }
+}
+
+func pduFromRecord(record Record) gosnmp.SnmpPDU {
+	pdu := gosnmp.SnmpPDU{Name: "." + record.OID, Type: gosnmp.Asn1BER(record.Type)}
+	switch record.Type {
+	case Integer:
+		i, _ := record.Int()
+		pdu.Value = int(i)
+	case OctetString:
+		pdu.Value, _ = record.Bytes()
+	case ObjectIdentifier:
+		pdu.Value = "." + record.Value
+	case IPAddress:
+		pdu.Value = record.Value
+	case Counter32, Gauge32, TimeTicks:
+		u, _ := record.Uint()
+		pdu.Value = uint32(u)
+	}
+	return pdu
+}
+
+func TestRecorder_Record(t *testing.T) {
+	agent := Records{
+		NewOctetString("1.3.6.1.2.1.1.1.0", []byte("recorded device")),
+		NewObjectIdentifier("1.3.6.1.2.1.1.2.0", "1.3.6.1.4.1.8072.3.2.10"),
+		NewTimeTicks("1.3.6.1.2.1.1.3.0", 123999999),
+		NewInteger("1.3.6.1.2.1.2.1.0", 2),
+		NewHexString("1.3.6.1.2.1.2.2.1.6.1", []byte{0x00, 0x16, 0x3e, 0x0a, 0x0b, 0x0c}),
+		NewCounter32("1.3.6.1.2.1.2.2.1.10.1", 4294967295),
+		NewGauge32("1.3.6.1.2.1.2.2.1.5.1", 1000000000),
+		NewIPAddress("1.3.6.1.2.1.4.20.1.1.10.0.0.1", net.IPv4(10, 0, 0, 1)),
+		// gosnmp cannot marshal Counter64 values, so the responder cannot serve them
+		NewGauge32("1.3.6.1.2.1.31.1.1.1.15.1", 1000),
+	}
+	port, stop := startResponder(t, agent)
+	defer stop()
+
+	newSNMP := func() *gosnmp.GoSNMP {
+		return &gosnmp.GoSNMP{
+			Target:         "127.0.0.1",
+			Port:           port,
+			Community:      "public",
+			Version:        gosnmp.Version2c,
+			Timeout:        2 * time.Second,
+			MaxRepetitions: 3,
+		}
+	}
+
+	records, err := NewRecorder(newSNMP()).Record()
+	if assert.NoError(t, err, "error during Record") {
+		assert.Equal(t, agent.String(), records.String())
+	}
+
+	records, err = NewRecorder(newSNMP(), "1.3.6.1.2.1.1", "1.3.6.1.2.1.4").Record()
+	if assert.NoError(t, err, "error during Record with subtrees") {
+		assert.Equal(t, Records{agent[0], agent[1], agent[2], agent[7]}.String(), records.String())
+	}
+
+	recorder := NewRecorder(newSNMP())
+	recorder.MaxRecords = 4
+	records, err = recorder.Record()
+	if assert.NoError(t, err, "error during Record with max records") {
+		assert.Equal(t, agent[:4].String(), records.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewRecorder(newSNMP()).RecordCtx(ctx)
+	assert.Error(t, err, "no error when the context was canceled")
+}
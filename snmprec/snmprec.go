@@ -0,0 +1,538 @@
+/*
+Package snmprec parses and writes snmpsim record files.
+
+A record file contains one record per line in the format OID|TAG|VALUE, e.g.
+
+	1.3.6.1.2.1.1.1.0|4|agent1-test-record
+
+The tag is the BER type code of the value, optionally followed by an "x" if the value is hex encoded
+and by a colon and the name of a variation module, e.g. "4x" or "2:numeric".
+Variation modules which provide the types themselves, like sql or redis, are used without a type code, e.g. ":sql".
+*/
+package snmprec
+
+import (
+	"bufio"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Type is the BER type code of a record value.
+*/
+type Type int
+
+// BER type codes supported by snmpsim.
+const (
+	Integer          Type = 2
+	OctetString      Type = 4
+	Null             Type = 5
+	ObjectIdentifier Type = 6
+	IPAddress        Type = 64
+	Counter32        Type = 65
+	Gauge32          Type = 66
+	TimeTicks        Type = 67
+	Opaque           Type = 68
+	Counter64        Type = 70
+)
+
+var typeNames = map[Type]string{
+	Integer:          "Integer",
+	OctetString:      "OctetString",
+	Null:             "Null",
+	ObjectIdentifier: "ObjectIdentifier",
+	IPAddress:        "IPAddress",
+	Counter32:        "Counter32",
+	Gauge32:          "Gauge32",
+	TimeTicks:        "TimeTicks",
+	Opaque:           "Opaque",
+	Counter64:        "Counter64",
+}
+
+func (t Type) String() string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return "Type(" + strconv.Itoa(int(t)) + ")"
+}
+
+/*
+Known returns true if the type is a BER type code supported by snmpsim.
+*/
+func (t Type) Known() bool {
+	_, ok := typeNames[t]
+	return ok
+}
+
+/*
+Record is a single line of a record file.
+*/
+type Record struct {
+	OID string
+	// Type is 0 for records of variation modules which provide the types themselves, e.g. ":sql".
+	Type Type
+	// Hex is true if Value is hex encoded.
+	Hex bool
+	// Variation is the name of the variation module which produces the value, e.g. "numeric".
+	// Values of records with a variation module are passed to the module and are not checked against the type.
+	// Only the values of modules known to take key=value pairs, e.g. numeric or writecache, are checked for that format.
+	Variation string
+	// Value is the value as it is written in the record file.
+	Value string
+}
+
+/*
+Records is an array of records.
+*/
+type Records []Record
+
+/*
+ParseError is returned when a line of a record file cannot be parsed.
+*/
+type ParseError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (p *ParseError) Error() string {
+	return "line " + strconv.Itoa(p.Line) + ": " + p.Err.Error()
+}
+
+/*
+Cause returns the underlying error.
+*/
+func (p *ParseError) Cause() error {
+	return p.Err
+}
+
+/*
+Unwrap returns the underlying error.
+*/
+func (p *ParseError) Unwrap() error {
+	return p.Err
+}
+
+/*
+Parse reads all records from r. Empty lines and lines starting with # are skipped.
+*/
+func Parse(r io.Reader) (Records, error) {
+	var records Records
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		record, err := ParseLine(line)
+		if err != nil {
+			return nil, &ParseError{Line: lineNumber, Text: line, Err: err}
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error while reading records")
+	}
+	return records, nil
+}
+
+/*
+ParseString parses the contents of a record file.
+*/
+func ParseString(s string) (Records, error) {
+	return Parse(strings.NewReader(s))
+}
+
+/*
+ParseLine parses a single record.
+*/
+func ParseLine(line string) (Record, error) {
+	parts := strings.SplitN(line, "|", 3)
+	if len(parts) != 3 {
+		return Record{}, errors.New("record does not have the format OID|TAG|VALUE")
+	}
+	record := Record{OID: parts[0], Value: parts[2]}
+	if err := ValidateOID(record.OID); err != nil {
+		return Record{}, err
+	}
+	var err error
+	record.Type, record.Hex, record.Variation, err = parseTag(parts[1])
+	if err != nil {
+		return Record{}, err
+	}
+	if err := record.Check(); err != nil {
+		return Record{}, err
+	}
+	return record, nil
+}
+
+func parseTag(tag string) (Type, bool, string, error) {
+	variation := ""
+	if i := strings.Index(tag, ":"); i != -1 {
+		variation = tag[i+1:]
+		tag = tag[:i]
+		if err := validateVariation(variation); err != nil {
+			return 0, false, "", err
+		}
+	}
+	if tag == "" && variation != "" {
+		return 0, false, variation, nil
+	}
+	isHex := strings.HasSuffix(tag, "x")
+	tag = strings.TrimSuffix(tag, "x")
+	code, err := strconv.Atoi(tag)
+	if err != nil {
+		return 0, false, "", errors.New("invalid tag " + tag)
+	}
+	t := Type(code)
+	if !t.Known() {
+		return 0, false, "", errors.New("unknown type code " + tag)
+	}
+	return t, isHex, variation, nil
+}
+
+func validateVariation(variation string) error {
+	if variation == "" {
+		return errors.New("empty variation module name")
+	}
+	for _, r := range variation {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return errors.New("invalid variation module name " + variation)
+		}
+	}
+	return nil
+}
+
+// keyValueVariations are the variation modules of snmpsim whose values are comma separated lists of key=value pairs.
+// Other modules, e.g. sql, redis or multiplex, take values in their own formats.
+var keyValueVariations = map[string]bool{
+	"delay":        true,
+	"error":        true,
+	"notification": true,
+	"numeric":      true,
+	"writecache":   true,
+}
+
+// checkVariationValue checks if the value of a record with a variation module which takes key=value pairs is a comma separated list of them.
+func checkVariationValue(variation, value string) error {
+	if value == "" || !keyValueVariations[variation] {
+		return nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		if i := strings.Index(pair, "="); i < 1 {
+			return errors.New("invalid variation module parameter " + pair + ", expected key=value")
+		}
+	}
+	return nil
+}
+
+/*
+ValidateOID checks if the given string is a numeric OID in dotted notation.
+*/
+func ValidateOID(oid string) error {
+	if oid == "" {
+		return errors.New("empty oid")
+	}
+	for _, arc := range strings.Split(oid, ".") {
+		if _, err := strconv.ParseUint(arc, 10, 32); err != nil {
+			return errors.New("invalid oid " + oid)
+		}
+	}
+	return nil
+}
+
+/*
+Tag returns the tag of the record as it is written in the record file.
+*/
+func (r Record) Tag() string {
+	tag := ""
+	if r.Type != 0 || r.Variation == "" {
+		tag = strconv.Itoa(int(r.Type))
+	}
+	if r.Hex {
+		tag += "x"
+	}
+	if r.Variation != "" {
+		tag += ":" + r.Variation
+	}
+	return tag
+}
+
+func (r Record) String() string {
+	return r.OID + "|" + r.Tag() + "|" + r.Value
+}
+
+/*
+Check checks if the value of the record is compatible with its type.
+Values must not contain line breaks as they would start a new record in the record file, binary values have to be hex encoded, see NewOctetString.
+*/
+func (r Record) Check() error {
+	if strings.ContainsAny(r.Value, "\r\n") {
+		return errors.New("value must not contain line breaks, use a hex encoded value instead")
+	}
+	if r.Variation != "" {
+		return checkVariationValue(r.Variation, r.Value)
+	}
+	if r.Hex {
+		switch r.Type {
+		case OctetString, IPAddress, Opaque:
+		default:
+			return errors.New("type " + r.Type.String() + " cannot be hex encoded")
+		}
+	}
+	var err error
+	switch r.Type {
+	case Integer:
+		_, err = r.Int()
+	case Counter32, Gauge32, TimeTicks, Counter64:
+		_, err = r.Uint()
+	case OctetString, Opaque:
+		_, err = r.Bytes()
+	case IPAddress:
+		_, err = r.IP()
+	case ObjectIdentifier:
+		err = ValidateOID(r.Value)
+	case Null:
+		if r.Value != "" {
+			err = errors.New("null value must be empty")
+		}
+	default:
+		err = errors.New("unknown type " + r.Type.String())
+	}
+	return err
+}
+
+/*
+Int returns the value of an Integer record.
+*/
+func (r Record) Int() (int64, error) {
+	if r.Type != Integer {
+		return 0, errors.New("record is not of type Integer")
+	}
+	i, err := strconv.ParseInt(r.Value, 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid Integer value " + r.Value)
+	}
+	return i, nil
+}
+
+/*
+Uint returns the value of a Counter32, Gauge32, TimeTicks or Counter64 record.
+*/
+func (r Record) Uint() (uint64, error) {
+	bitSize := 32
+	switch r.Type {
+	case Counter32, Gauge32, TimeTicks:
+	case Counter64:
+		bitSize = 64
+	default:
+		return 0, errors.New("record of type " + r.Type.String() + " has no unsigned value")
+	}
+	u, err := strconv.ParseUint(r.Value, 10, bitSize)
+	if err != nil {
+		return 0, errors.New("invalid " + r.Type.String() + " value " + r.Value)
+	}
+	return u, nil
+}
+
+/*
+Bytes returns the value of an OctetString or Opaque record, hex encoded values are decoded.
+*/
+func (r Record) Bytes() ([]byte, error) {
+	if r.Type != OctetString && r.Type != Opaque {
+		return nil, errors.New("record of type " + r.Type.String() + " has no bytes value")
+	}
+	if !r.Hex {
+		return []byte(r.Value), nil
+	}
+	b, err := hex.DecodeString(r.Value)
+	if err != nil {
+		return nil, errors.New("invalid hex value " + r.Value)
+	}
+	return b, nil
+}
+
+/*
+IP returns the value of an IPAddress record.
+*/
+func (r Record) IP() (net.IP, error) {
+	if r.Type != IPAddress {
+		return nil, errors.New("record is not of type IPAddress")
+	}
+	if r.Hex {
+		b, err := hex.DecodeString(r.Value)
+		if err != nil || len(b) != net.IPv4len {
+			return nil, errors.New("invalid hex IPAddress value " + r.Value)
+		}
+		return net.IP(b), nil
+	}
+	ip := net.ParseIP(r.Value)
+	if ip == nil || ip.To4() == nil {
+		return nil, errors.New("invalid IPAddress value " + r.Value)
+	}
+	return ip.To4(), nil
+}
+
+/*
+NewInteger creates an Integer record.
+*/
+func NewInteger(oid string, value int32) Record {
+	return Record{OID: oid, Type: Integer, Value: strconv.FormatInt(int64(value), 10)}
+}
+
+/*
+NewOctetString creates an OctetString record. Values which cannot be written as plain text are hex encoded.
+*/
+func NewOctetString(oid string, value []byte) Record {
+	if isPrintable(value) {
+		return Record{OID: oid, Type: OctetString, Value: string(value)}
+	}
+	return NewHexString(oid, value)
+}
+
+/*
+NewHexString creates a hex encoded OctetString record.
+*/
+func NewHexString(oid string, value []byte) Record {
+	return Record{OID: oid, Type: OctetString, Hex: true, Value: hex.EncodeToString(value)}
+}
+
+/*
+NewNull creates a Null record.
+*/
+func NewNull(oid string) Record {
+	return Record{OID: oid, Type: Null}
+}
+
+/*
+NewObjectIdentifier creates an ObjectIdentifier record.
+*/
+func NewObjectIdentifier(oid string, value string) Record {
+	return Record{OID: oid, Type: ObjectIdentifier, Value: strings.TrimPrefix(value, ".")}
+}
+
+/*
+NewIPAddress creates an IPAddress record.
+*/
+func NewIPAddress(oid string, value net.IP) Record {
+	return Record{OID: oid, Type: IPAddress, Value: value.String()}
+}
+
+/*
+NewCounter32 creates a Counter32 record.
+*/
+func NewCounter32(oid string, value uint32) Record {
+	return Record{OID: oid, Type: Counter32, Value: strconv.FormatUint(uint64(value), 10)}
+}
+
+/*
+NewGauge32 creates a Gauge32 record.
+*/
+func NewGauge32(oid string, value uint32) Record {
+	return Record{OID: oid, Type: Gauge32, Value: strconv.FormatUint(uint64(value), 10)}
+}
+
+/*
+NewTimeTicks creates a TimeTicks record.
+*/
+func NewTimeTicks(oid string, value uint32) Record {
+	return Record{OID: oid, Type: TimeTicks, Value: strconv.FormatUint(uint64(value), 10)}
+}
+
+/*
+NewOpaque creates a hex encoded Opaque record.
+*/
+func NewOpaque(oid string, value []byte) Record {
+	return Record{OID: oid, Type: Opaque, Hex: true, Value: hex.EncodeToString(value)}
+}
+
+/*
+NewCounter64 creates a Counter64 record.
+*/
+func NewCounter64(oid string, value uint64) Record {
+	return Record{OID: oid, Type: Counter64, Value: strconv.FormatUint(value, 10)}
+}
+
+// isPrintable checks if the value can be written into a record file without hex encoding.
+func isPrintable(value []byte) bool {
+	for _, b := range value {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+CompareOIDs compares two numeric OIDs in lexicographic order of their arcs.
+The result is -1 if a < b, 0 if a == b and 1 if a > b.
+*/
+func CompareOIDs(a, b string) int {
+	arcsA := strings.Split(a, ".")
+	arcsB := strings.Split(b, ".")
+	for i := 0; i < len(arcsA) && i < len(arcsB); i++ {
+		if arcsA[i] == arcsB[i] {
+			continue
+		}
+		numberA, errA := strconv.ParseUint(arcsA[i], 10, 64)
+		numberB, errB := strconv.ParseUint(arcsB[i], 10, 64)
+		if errA != nil || errB != nil {
+			return strings.Compare(arcsA[i], arcsB[i])
+		}
+		if numberA < numberB {
+			return -1
+		}
+		if numberA > numberB {
+			return 1
+		}
+	}
+	switch {
+	case len(arcsA) < len(arcsB):
+		return -1
+	case len(arcsA) > len(arcsB):
+		return 1
+	}
+	return 0
+}
+
+/*
+Sort sorts the records by their OIDs in lexicographic order, which is the order snmpsim expects.
+*/
+func (r Records) Sort() {
+	sort.SliceStable(r, func(i, j int) bool {
+		return CompareOIDs(r[i].OID, r[j].OID) < 0
+	})
+}
+
+/*
+Write writes the records in their current order to w.
+*/
+func (r Records) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, record := range r {
+		if _, err := bw.WriteString(record.String() + "\n"); err != nil {
+			return errors.Wrap(err, "error while writing records")
+		}
+	}
+	return errors.Wrap(bw.Flush(), "error while writing records")
+}
+
+/*
+String returns the records in their current order as the contents of a record file.
+*/
+func (r Records) String() string {
+	var b strings.Builder
+	for _, record := range r {
+		b.WriteString(record.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
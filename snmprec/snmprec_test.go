@@ -0,0 +1,151 @@
+package snmprec
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	b, err := ioutil.ReadFile("../test-data/snmprecs/TestManagementClient_buildUpSetupAndTestIt/agent1/public.snmprec")
+	if !assert.NoError(t, err, "error while reading test record file") {
+		return
+	}
+	records, err := ParseString(string(b))
+	if !assert.NoError(t, err, "error while parsing record file") {
+		return
+	}
+	if assert.Len(t, records, 5) {
+		assert.Equal(t, Record{OID: "1.3.6.1.2.1.1.1.0", Type: OctetString, Value: "agent1-test-record"}, records[0])
+		assert.Equal(t, ObjectIdentifier, records[1].Type)
+		ticks, err := records[2].Uint()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(123999999), ticks)
+	}
+	assert.Equal(t, strings.TrimSpace(string(b))+"\n", records.String(), "records do not serialize to the original file")
+}
+
+func TestParse_TypedValues(t *testing.T) {
+	records, err := ParseString("1.3.6.1.2.1.2.2.1.6.1|4x|00163e0a0b0c\r\n" +
+		"\n" +
+		"1.3.6.1.2.1.4.20.1.1.10.0.0.1|64|10.0.0.1\n" +
+		"1.3.6.1.2.1.4.20.1.1.10.0.0.2|64x|0a000002\n" +
+		"1.3.6.1.2.1.2.2.1.10.1|65:numeric|rate=100,initial=0\n" +
+		"1.3.6.1.2.1.31.1.1.1.6.1|70|18446744073709551615\n" +
+		"1.3.6.1.2.1.2.2.1.8.1|2|-1\n" +
+		"1.3.6.1.4.1.1.1|5|\n")
+	if !assert.NoError(t, err, "error while parsing records") {
+		return
+	}
+	if !assert.Len(t, records, 7) {
+		return
+	}
+
+	b, err := records[0].Bytes()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x16, 0x3e, 0x0a, 0x0b, 0x0c}, b)
+
+	ip, err := records[1].IP()
+	assert.NoError(t, err)
+	assert.Equal(t, net.IPv4(10, 0, 0, 1).To4(), ip)
+	ip, err = records[2].IP()
+	assert.NoError(t, err)
+	assert.Equal(t, net.IPv4(10, 0, 0, 2).To4(), ip)
+
+	assert.Equal(t, "numeric", records[3].Variation)
+	assert.Equal(t, "65:numeric", records[3].Tag())
+
+	u, err := records[4].Uint()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551615), u)
+
+	i, err := records[5].Int()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), i)
+
+	assert.Equal(t, Null, records[6].Type)
+}
+
+func TestParse_VariationWithoutType(t *testing.T) {
+	records, err := ParseString("1.3.6.1.2.1.1|:redis|key-spaces-id=1234\n" +
+		"1.3.6.1.2.1.2|:multiplex|dir=variation/snapshots,period=10.0\n" +
+		"1.3.6.1.2.1.3|:sql|\n")
+	if !assert.NoError(t, err, "error while parsing records") || !assert.Len(t, records, 3) {
+		return
+	}
+	assert.Equal(t, Record{OID: "1.3.6.1.2.1.1", Variation: "redis", Value: "key-spaces-id=1234"}, records[0])
+	assert.Equal(t, "multiplex", records[1].Variation)
+	assert.Equal(t, Type(0), records[2].Type)
+	assert.Equal(t, ":sql", records[2].Tag())
+	assert.Equal(t, "1.3.6.1.2.1.1|:redis|key-spaces-id=1234\n", Records{records[0]}.String())
+
+	assert.Empty(t, ValidateString("1.3.6.1.2.1.1|:redis|key-spaces-id=1234\n"))
+	_, err = ParseLine("1.3.6.1.2.1.1|x:sql|")
+	assert.Error(t, err, "no error for hex tag without type")
+	_, err = ParseLine("1.3.6.1.2.1.1||value")
+	assert.Error(t, err, "no error for empty tag")
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"missing value":     "1.3.6.1.2.1.1.1.0|4",
+		"invalid oid":       "1.3.6.a.1|4|value",
+		"unknown type":      "1.3.6.1.2.1.1.1.0|42|value",
+		"invalid integer":   "1.3.6.1.2.1.1.1.0|2|abc",
+		"integer too big":   "1.3.6.1.2.1.1.1.0|2|2147483648",
+		"invalid hex":       "1.3.6.1.2.1.1.1.0|4x|0g",
+		"invalid ip":        "1.3.6.1.2.1.1.1.0|64|10.0.0",
+		"hex counter":       "1.3.6.1.2.1.1.1.0|65x|00",
+		"invalid variation": "1.3.6.1.2.1.1.1.0|2:|1",
+	}
+	for name, line := range tests {
+		_, err := ParseString("1.3.6.1.2.1.1.0.0|4|first\n" + line + "\n")
+		if assert.Error(t, err, name) {
+			var parseError *ParseError
+			if assert.True(t, errors.As(err, &parseError), name+": error is not a parse error") {
+				assert.Equal(t, 2, parseError.Line, name)
+				assert.Equal(t, line, parseError.Text, name)
+			}
+		}
+	}
+}
+
+func TestRecord_Check_LineBreaks(t *testing.T) {
+	for _, value := range []string{"first\n1.3.6.1.2.1.1.5.0|4|injected", "first\rsecond"} {
+		record := Record{OID: "1.3.6.1.2.1.1.1.0", Type: OctetString, Value: value}
+		assert.Error(t, record.Check(), "no error for value with line break %q", value)
+	}
+	variation := Record{OID: "1.3.6.1.2.1.1.1.0", Type: OctetString, Variation: "writecache", Value: "value=a\nb"}
+	assert.Error(t, variation.Check(), "no error for variation value with line break")
+
+	record := NewOctetString("1.3.6.1.2.1.1.1.0", []byte("first\nsecond"))
+	assert.True(t, record.Hex, "value with line break was not hex encoded")
+	assert.NoError(t, record.Check())
+	records, err := ParseString(Records{record}.String())
+	if assert.NoError(t, err) && assert.Len(t, records, 1) {
+		b, err := records[0].Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, "first\nsecond", string(b))
+	}
+}
+
+func TestRecords_Sort(t *testing.T) {
+	records := Records{
+		NewOctetString("1.3.6.1.2.1.1.10.0", []byte("c")),
+		NewOctetString("1.3.6.1.2.1.1.9.0", []byte("b")),
+		NewOctetString("1.3.6.1.2.1.1", []byte("a")),
+		NewOctetString("1.3.6.1.2.1.2.1.0", []byte{0x01, 0xff}),
+	}
+	records.Sort()
+	assert.Equal(t, "1.3.6.1.2.1.1|4|a\n"+
+		"1.3.6.1.2.1.1.9.0|4|b\n"+
+		"1.3.6.1.2.1.1.10.0|4|c\n"+
+		"1.3.6.1.2.1.2.1.0|4x|01ff\n", records.String())
+
+	assert.Equal(t, -1, CompareOIDs("1.3.6.1.2", "1.3.6.1.10"))
+	assert.Equal(t, 0, CompareOIDs("1.3.6", "1.3.6"))
+	assert.Equal(t, 1, CompareOIDs("1.3.6.1", "1.3.6"))
+}
@@ -0,0 +1,237 @@
+package snmprec
+
+import (
+	"bufio"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// snmpwalkLine matches the beginning of a line of snmpwalk -On output, e.g. ".1.3.6.1.2.1.1.1.0 = STRING: text".
+var snmpwalkLine = regexp.MustCompile(`^(\.?[0-9]+(?:\.[0-9]+)*) = (.*)$`)
+
+// snmpwalkNonNumericLine matches a line of snmpwalk output with a symbolic OID, e.g. "SNMPv2-MIB::sysDescr.0 = STRING: text".
+var snmpwalkNonNumericLine = regexp.MustCompile(`^[A-Za-z][^ ]*::[^ ]+ = `)
+
+// snmpwalkNoValue contains values snmpwalk prints for OIDs which do not exist.
+var snmpwalkNoValue = []string{
+	"No Such Object available",
+	"No Such Instance currently exists",
+	"No more variables left in this MIB View",
+}
+
+type snmpwalkEntry struct {
+	line  int
+	text  string
+	oid   string
+	value string
+}
+
+/*
+ConvertSnmpwalk converts the output of net-snmp's snmpwalk into records sorted by their OIDs.
+The OIDs have to be numeric (snmpwalk -On), enumerations can be printed as numbers (-Oe) or with their labels.
+OIDs which occur more than once are only converted the first time, OIDs without a value are skipped.
+*/
+func ConvertSnmpwalk(r io.Reader) (Records, error) {
+	var entries []*snmpwalkEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if match := snmpwalkLine.FindStringSubmatch(line); match != nil {
+			entries = append(entries, &snmpwalkEntry{line: lineNumber, text: line, oid: strings.TrimPrefix(match[1], "."), value: match[2]})
+			continue
+		}
+		if snmpwalkNonNumericLine.MatchString(line) {
+			return nil, &ParseError{Line: lineNumber, Text: line, Err: errors.New("oid is not numeric, use snmpwalk -On")}
+		}
+		if len(entries) == 0 {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			return nil, &ParseError{Line: lineNumber, Text: line, Err: errors.New("line does not start with an oid")}
+		}
+		// values of strings can span multiple lines
+		entries[len(entries)-1].value += "\n" + line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error while reading snmpwalk output")
+	}
+
+	var records Records
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if seen[entry.oid] {
+			continue
+		}
+		record, ok, err := convertSnmpwalkValue(entry.oid, strings.TrimRight(entry.value, "\n"))
+		if err != nil {
+			return nil, &ParseError{Line: entry.line, Text: entry.text, Err: err}
+		}
+		if !ok {
+			continue
+		}
+		seen[entry.oid] = true
+		records = append(records, record)
+	}
+	records.Sort()
+	return records, nil
+}
+
+/*
+ConvertSnmpwalkString converts the output of net-snmp's snmpwalk into records, see ConvertSnmpwalk.
+*/
+func ConvertSnmpwalkString(s string) (Records, error) {
+	return ConvertSnmpwalk(strings.NewReader(s))
+}
+
+// convertSnmpwalkValue converts a value like "STRING: text" into a record, ok is false if the oid has no value.
+func convertSnmpwalkValue(oid, value string) (record Record, ok bool, err error) {
+	for _, noValue := range snmpwalkNoValue {
+		if strings.HasPrefix(value, noValue) {
+			return Record{}, false, nil
+		}
+	}
+	if value == `""` || value == "" {
+		return NewOctetString(oid, nil), true, nil
+	}
+	if value == "NULL" {
+		return NewNull(oid), true, nil
+	}
+
+	i := strings.Index(value, ": ")
+	if i == -1 {
+		if strings.HasSuffix(value, ":") {
+			i = len(value) - 1
+		} else {
+			return Record{}, false, errors.New("value " + value + " has no type")
+		}
+	}
+	typeName := value[:i]
+	value = strings.TrimPrefix(value[i:], ":")
+	value = strings.TrimPrefix(value, " ")
+
+	switch typeName {
+	case "STRING":
+		return NewOctetString(oid, []byte(unquoteSnmpwalkString(value))), true, nil
+	case "Hex-STRING", "BITS":
+		b, err := decodeSnmpwalkHex(value)
+		if err != nil {
+			return Record{}, false, err
+		}
+		return NewOctetString(oid, b), true, nil
+	case "INTEGER":
+		number, err := snmpwalkNumber(value)
+		if err != nil {
+			return Record{}, false, err
+		}
+		i, err := strconv.ParseInt(number, 10, 32)
+		if err != nil {
+			return Record{}, false, errors.New("invalid INTEGER value " + value)
+		}
+		return NewInteger(oid, int32(i)), true, nil
+	case "OID":
+		value = strings.TrimPrefix(value, ".")
+		if err := ValidateOID(value); err != nil {
+			return Record{}, false, errors.Wrap(err, "OID value is not numeric, use snmpwalk -On")
+		}
+		return NewObjectIdentifier(oid, value), true, nil
+	case "Timeticks":
+		number := value
+		if strings.HasPrefix(value, "(") && strings.Contains(value, ")") {
+			number = value[1:strings.Index(value, ")")]
+		}
+		u, err := strconv.ParseUint(number, 10, 32)
+		if err != nil {
+			return Record{}, false, errors.New("invalid Timeticks value " + value)
+		}
+		return NewTimeTicks(oid, uint32(u)), true, nil
+	case "Counter32", "Gauge32", "Unsigned32", "UInteger32":
+		number, err := snmpwalkNumber(value)
+		if err != nil {
+			return Record{}, false, err
+		}
+		u, err := strconv.ParseUint(number, 10, 32)
+		if err != nil {
+			return Record{}, false, errors.New("invalid " + typeName + " value " + value)
+		}
+		if typeName == "Counter32" {
+			return NewCounter32(oid, uint32(u)), true, nil
+		}
+		return NewGauge32(oid, uint32(u)), true, nil
+	case "Counter64":
+		number, err := snmpwalkNumber(value)
+		if err != nil {
+			return Record{}, false, err
+		}
+		u, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return Record{}, false, errors.New("invalid Counter64 value " + value)
+		}
+		return NewCounter64(oid, u), true, nil
+	case "IpAddress":
+		ip := net.ParseIP(value)
+		if ip == nil || ip.To4() == nil {
+			return Record{}, false, errors.New("invalid IpAddress value " + value)
+		}
+		return NewIPAddress(oid, ip.To4()), true, nil
+	case "Network Address":
+		b, err := decodeSnmpwalkHex(strings.Replace(value, ":", " ", -1))
+		if err != nil || len(b) != net.IPv4len {
+			return Record{}, false, errors.New("invalid Network Address value " + value)
+		}
+		return NewIPAddress(oid, net.IP(b)), true, nil
+	case "Opaque":
+		b, err := decodeSnmpwalkHex(value)
+		if err != nil {
+			return Record{}, false, errors.New("unsupported Opaque value " + value + ", only hex values are supported")
+		}
+		return NewOpaque(oid, b), true, nil
+	}
+	return Record{}, false, errors.New("unsupported type " + typeName)
+}
+
+// snmpwalkNumber returns the number of values like "5", "up(1)" or "100 seconds".
+func snmpwalkNumber(value string) (string, error) {
+	if open := strings.LastIndex(value, "("); open != -1 && strings.HasSuffix(value, ")") {
+		return value[open+1 : len(value)-1], nil
+	}
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", errors.New("empty number value")
+	}
+	return fields[0], nil
+}
+
+func unquoteSnmpwalkString(value string) string {
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return value
+	}
+	value = value[1 : len(value)-1]
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(value)
+}
+
+// decodeSnmpwalkHex decodes hex values like "00 16 3E 0A" which can span multiple lines, a trailing description like in BITS values is ignored.
+func decodeSnmpwalkHex(value string) ([]byte, error) {
+	var digits strings.Builder
+	for _, field := range strings.Fields(value) {
+		if len(field) != 2 {
+			break
+		}
+		if _, err := hex.DecodeString(field); err != nil {
+			break
+		}
+		digits.WriteString(field)
+	}
+	b, err := hex.DecodeString(digits.String())
+	if err != nil || (digits.Len() == 0 && strings.TrimSpace(value) != "") {
+		return nil, errors.New("invalid hex value " + value)
+	}
+	return b, nil
+}
@@ -0,0 +1,59 @@
+package snmprec
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConvertSnmpwalk(t *testing.T) {
+	walk := `.1.3.6.1.2.1.1.1.0 = STRING: "Linux host 5.4.0 #1 SMP
+second line with a \"quote\""
+.1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.8072.3.2.10
+.1.3.6.1.2.1.1.3.0 = Timeticks: (123999999) 14 days, 8:26:39.99
+.1.3.6.1.2.1.1.4.0 = ""
+.1.3.6.1.2.1.2.1.0 = INTEGER: 2
+.1.3.6.1.2.1.2.2.1.8.1 = INTEGER: up(1)
+.1.3.6.1.2.1.2.2.1.6.1 = Hex-STRING: 00 16 3E 0A 
+0B 0C 
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 4294967295
+.1.3.6.1.2.1.2.2.1.5.1 = Gauge32: 1000000000
+.1.3.6.1.2.1.4.20.1.1.10.0.0.1 = IpAddress: 10.0.0.1
+.1.3.6.1.2.1.31.1.1.1.6.1 = Counter64: 18446744073709551615
+.1.3.6.1.2.1.25.1.1.0 = Timeticks: 42
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 1
+.1.3.6.1.2.1.99.0 = No Such Object available on this agent at this OID
+`
+	records, err := ConvertSnmpwalkString(walk)
+	if !assert.NoError(t, err, "error during ConvertSnmpwalkString") {
+		return
+	}
+
+	expected := "1.3.6.1.2.1.1.1.0|4x|4c696e757820686f737420352e342e3020233120534d500a7365636f6e64206c696e6520776974682061202271756f746522\n" +
+		"1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.8072.3.2.10\n" +
+		"1.3.6.1.2.1.1.3.0|67|123999999\n" +
+		"1.3.6.1.2.1.1.4.0|4|\n" +
+		"1.3.6.1.2.1.2.1.0|2|2\n" +
+		"1.3.6.1.2.1.2.2.1.5.1|66|1000000000\n" +
+		"1.3.6.1.2.1.2.2.1.6.1|4x|00163e0a0b0c\n" +
+		"1.3.6.1.2.1.2.2.1.8.1|2|1\n" +
+		"1.3.6.1.2.1.2.2.1.10.1|65|4294967295\n" +
+		"1.3.6.1.2.1.4.20.1.1.10.0.0.1|64|10.0.0.1\n" +
+		"1.3.6.1.2.1.25.1.1.0|67|42\n" +
+		"1.3.6.1.2.1.31.1.1.1.6.1|70|18446744073709551615\n"
+	assert.Equal(t, expected, records.String())
+	assert.Empty(t, ValidateString(records.String()), "converted records are not valid")
+}
+
+func TestConvertSnmpwalk_Errors(t *testing.T) {
+	_, err := ConvertSnmpwalkString(".1.3.6.1.2.1.1.1.0 = STRING: \"ok\"\nSNMPv2-MIB::sysDescr.0 = STRING: test\n")
+	var parseError *ParseError
+	if assert.True(t, errors.As(err, &parseError), "error is not a parse error") {
+		assert.Equal(t, 2, parseError.Line)
+	}
+
+	_, err = ConvertSnmpwalkString(".1.3.6.1.2.1.1.1.0 = STRING: \"ok\"\n.1.3.6.1.2.1.1.2.0 = Gauge32: many\n")
+	if assert.True(t, errors.As(err, &parseError), "error is not a parse error") {
+		assert.Equal(t, 2, parseError.Line)
+	}
+}
@@ -0,0 +1,105 @@
+package snmprec
+
+import (
+	"bufio"
+	"github.com/pkg/errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+/*
+Problem describes an invalid line of a record file.
+*/
+type Problem struct {
+	Line    int
+	Text    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return "line " + strconv.Itoa(p.Line) + ": " + p.Message
+}
+
+/*
+Problems is an array of problems, it is used as error.
+*/
+type Problems []Problem
+
+func (p Problems) Error() string {
+	var messages []string
+	for _, problem := range p {
+		messages = append(messages, problem.String())
+	}
+	return "invalid record file: " + strings.Join(messages, "; ")
+}
+
+/*
+Validate checks all records read from r and returns all problems that were found.
+Besides the syntax of each line it checks that the OIDs are unique and in strictly increasing order.
+The returned error is only set if r could not be read.
+*/
+func Validate(r io.Reader) (Problems, error) {
+	var problems Problems
+	add := func(line int, text, message string) {
+		problems = append(problems, Problem{Line: line, Text: text, Message: message})
+	}
+
+	previousOID := ""
+	previousLine := 0
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) != 3 {
+			add(lineNumber, line, "record does not have the format OID|TAG|VALUE")
+			continue
+		}
+
+		if err := ValidateOID(parts[0]); err != nil {
+			add(lineNumber, line, err.Error())
+		} else {
+			if previousOID != "" {
+				switch CompareOIDs(previousOID, parts[0]) {
+				case 0:
+					add(lineNumber, line, "duplicate oid "+parts[0]+", first defined in line "+strconv.Itoa(previousLine))
+				case 1:
+					add(lineNumber, line, "oid "+parts[0]+" is not greater than oid "+previousOID+" in line "+strconv.Itoa(previousLine))
+				}
+			}
+			if previousOID == "" || CompareOIDs(previousOID, parts[0]) < 0 {
+				previousOID = parts[0]
+				previousLine = lineNumber
+			}
+		}
+
+		t, isHex, variation, err := parseTag(parts[1])
+		if err != nil {
+			add(lineNumber, line, err.Error())
+			continue
+		}
+		record := Record{OID: parts[0], Type: t, Hex: isHex, Variation: variation, Value: parts[2]}
+		if err := record.Check(); err != nil {
+			add(lineNumber, line, err.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "error while reading records")
+	}
+	return problems, nil
+}
+
+/*
+ValidateString checks the contents of a record file, see Validate.
+*/
+func ValidateString(s string) Problems {
+	problems, _ := Validate(strings.NewReader(s))
+	return problems
+}
@@ -0,0 +1,52 @@
+package snmprec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	problems := ValidateString("1.3.6.1.2.1.1.1.0|4|first\n" +
+		"1.3.6.1.2.1.1.1.0|4|duplicate\n" +
+		"1.3.6.1.2.1.1.0|4|decreasing\n" +
+		"1.3.6.1.2.1.1.2.0|6|1.3.6.x\n" +
+		"1.3.6.1.2.1.1.3.0|99|unknown type\n" +
+		"1.3.6..1.2.1.1.4.0|4|invalid oid\n" +
+		"1.3.6.1.2.1.1.5.0|2:numeric|min\n" +
+		"1.3.6.1.2.1.1.6.0|2:num eric|min=1\n" +
+		"1.3.6.1.2.1.1.7.0|2:numeric|min=1,max=10\n" +
+		"no separators\n")
+
+	if !assert.Len(t, problems, 8) {
+		return
+	}
+	lines := []int{2, 3, 4, 5, 6, 7, 8, 10}
+	for i, line := range lines {
+		assert.Equal(t, line, problems[i].Line)
+	}
+	assert.Contains(t, problems[0].Message, "duplicate oid")
+	assert.Contains(t, problems[1].Message, "not greater than")
+	assert.Equal(t, "1.3.6.1.2.1.1.0|4|decreasing", problems[1].Text)
+	assert.Contains(t, problems[2].Message, "invalid oid 1.3.6.x")
+	assert.Contains(t, problems[3].Message, "unknown type code")
+	assert.Contains(t, problems[4].Message, "invalid oid")
+	assert.Contains(t, problems[5].Message, "variation module parameter")
+	assert.Contains(t, problems[6].Message, "variation module name")
+	assert.Contains(t, problems[7].Message, "OID|TAG|VALUE")
+
+	assert.Empty(t, ValidateString("1.3.6.1.2.1.1.1.0|4|first\n1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.8072.3.2.10\n"))
+}
+
+func TestValidate_VariationValues(t *testing.T) {
+	assert.Empty(t, ValidateString("1.3.6.1.2.1.1|:sql|snmprec\n"+
+		"1.3.6.1.2.1.2|:redis|key-spaces-id=1234\n"+
+		"1.3.6.1.2.1.3|:multiplex|variation/snapshots\n"+
+		"1.3.6.1.2.1.4.1.0|4:subprocess|/usr/bin/uptime\n"),
+		"problems for variation modules with their own value formats")
+
+	problems := ValidateString("1.3.6.1.2.1.1.1.0|4:writecache|first\n1.3.6.1.2.1.1.2.0|2:delay|wait=100,1\n")
+	if assert.Len(t, problems, 2) {
+		assert.Contains(t, problems[0].Message, "variation module parameter")
+		assert.Contains(t, problems[1].Message, "variation module parameter")
+	}
+}
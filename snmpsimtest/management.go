@@ -0,0 +1,597 @@
+package snmpsimtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	kindLab      = "lab"
+	kindAgent    = "agent"
+	kindEngine   = "engine"
+	kindEndpoint = "endpoint"
+	kindUser     = "user"
+	kindTag      = "tag"
+)
+
+// kinds maps the plural form used in paths to the kind of an object.
+var kinds = map[string]string{
+	"labs":      kindLab,
+	"agents":    kindAgent,
+	"engines":   kindEngine,
+	"endpoints": kindEndpoint,
+	"users":     kindUser,
+	"tags":      kindTag,
+}
+
+// childKinds contains the kinds of objects which can be linked to an object of the given kind.
+var childKinds = map[string][]string{
+	kindLab:    {kindAgent},
+	kindAgent:  {kindEngine},
+	kindEngine: {kindEndpoint, kindUser},
+}
+
+// taggableKinds contains all kinds of objects which can be tagged, in the order they are deleted.
+var taggableKinds = []string{kindLab, kindAgent, kindEngine, kindEndpoint, kindUser}
+
+type object struct {
+	id     int
+	fields map[string]interface{}
+	links  map[string][]int
+	tags   []int
+}
+
+type store struct {
+	nextID     int
+	objects    map[string]map[int]*object
+	recordings map[string]string
+}
+
+func newStore() *store {
+	s := &store{
+		nextID:     1,
+		objects:    make(map[string]map[int]*object),
+		recordings: make(map[string]string),
+	}
+	for _, kind := range kinds {
+		s.objects[kind] = make(map[int]*object)
+	}
+	return s
+}
+
+func (s *store) sortedIDs(kind string) []int {
+	var ids []int
+	for id := range s.objects[kind] {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// render returns the json representation of an object including its linked objects and tags.
+func (s *store) render(kind string, o *object) map[string]interface{} {
+	result := s.renderShallow(o)
+	for _, childKind := range childKinds[kind] {
+		children := make([]interface{}, 0)
+		for _, childID := range o.links[childKind] {
+			if child, ok := s.objects[childKind][childID]; ok {
+				children = append(children, s.render(childKind, child))
+			}
+		}
+		result[childKind+"s"] = children
+	}
+	if kind == kindAgent {
+		result["selectors"] = make([]interface{}, 0)
+	}
+	if kind == kindTag {
+		for _, taggedKind := range taggableKinds {
+			tagged := make([]interface{}, 0)
+			for _, id := range s.sortedIDs(taggedKind) {
+				taggedObject := s.objects[taggedKind][id]
+				if containsInt(taggedObject.tags, o.id) {
+					tagged = append(tagged, s.renderShallow(taggedObject))
+				}
+			}
+			result[taggedKind+"s"] = tagged
+		}
+		result["selectors"] = make([]interface{}, 0)
+		return result
+	}
+	tags := make([]interface{}, 0)
+	for _, tagID := range o.tags {
+		if tag, ok := s.objects[kindTag][tagID]; ok {
+			tags = append(tags, s.renderShallow(tag))
+		}
+	}
+	result["tags"] = tags
+	return result
+}
+
+func (s *store) renderShallow(o *object) map[string]interface{} {
+	result := map[string]interface{}{"id": o.id}
+	for key, value := range o.fields {
+		result[key] = value
+	}
+	return result
+}
+
+func (s *store) create(kind string, fields map[string]interface{}) (*object, int, string) {
+	status, msg := s.validate(kind, s.nextID, fields)
+	if status != 0 {
+		return nil, status, msg
+	}
+	if kind == kindLab {
+		fields["power"] = "off"
+	}
+	o := &object{id: s.nextID, fields: fields, links: make(map[string][]int)}
+	s.nextID++
+	s.objects[kind][o.id] = o
+	return o, 0, ""
+}
+
+// update applies the given fields to an existing object, the object is left unchanged if they are invalid.
+func (s *store) update(kind string, id int, changes map[string]interface{}) (*object, int, string) {
+	o, ok := s.objects[kind][id]
+	if !ok {
+		return nil, http.StatusNotFound, kind + " not found"
+	}
+	fields := make(map[string]interface{})
+	for key, value := range o.fields {
+		fields[key] = value
+	}
+	for key, value := range changes {
+		if key == "id" || key == "power" {
+			return nil, http.StatusBadRequest, "field " + key + " cannot be updated"
+		}
+		fields[key] = value
+	}
+	status, msg := s.validate(kind, id, fields)
+	if status != 0 {
+		return nil, status, msg
+	}
+	o.fields = fields
+	return o, 0, ""
+}
+
+// validate checks and completes the fields of the object with the given id, which can be a new object.
+func (s *store) validate(kind string, id int, fields map[string]interface{}) (int, string) {
+	requireString := func(key string) (string, bool) {
+		value, ok := fields[key].(string)
+		return value, ok && value != ""
+	}
+	defaultString := func(key, def string) {
+		if value, ok := fields[key].(string); !ok || value == "" {
+			fields[key] = def
+		}
+	}
+
+	if _, ok := requireString("name"); !ok {
+		return http.StatusBadRequest, "missing name"
+	}
+
+	switch kind {
+	case kindAgent:
+		defaultString("data_dir", ".")
+	case kindEngine:
+		defaultString("engine_id", "auto")
+		if fields["engine_id"] == "auto" {
+			fields["engine_id"] = fmt.Sprintf("80004fb805%016x", id)
+		}
+	case kindEndpoint:
+		defaultString("protocol", "udpv4")
+		protocol := fields["protocol"].(string)
+		if protocol != "udpv4" && protocol != "udpv6" {
+			return http.StatusBadRequest, "invalid protocol " + protocol
+		}
+		address, _ := fields["address"].(string)
+		host, port, err := net.SplitHostPort(address)
+		if err != nil || net.ParseIP(host) == nil {
+			return http.StatusBadRequest, "invalid address " + address
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return http.StatusBadRequest, "invalid port " + port
+		}
+		for _, endpoint := range s.objects[kindEndpoint] {
+			if endpoint.id != id && endpoint.fields["address"] == address && endpoint.fields["protocol"] == protocol {
+				return http.StatusBadRequest, "address " + address + " is already in use"
+			}
+		}
+	case kindUser:
+		user, ok := requireString("user")
+		if !ok {
+			return http.StatusBadRequest, "missing user"
+		}
+		for _, existing := range s.objects[kindUser] {
+			if existing.id != id && existing.fields["user"] == user {
+				return http.StatusBadRequest, "user " + user + " already exists"
+			}
+		}
+		defaultString("auth_proto", "none")
+		defaultString("priv_proto", "none")
+		for _, key := range []string{"auth_key", "priv_key"} {
+			if _, ok := fields[key]; !ok {
+				fields[key] = nil
+			}
+		}
+	case kindTag:
+		if _, ok := fields["description"]; !ok {
+			fields["description"] = ""
+		}
+	}
+	return 0, ""
+}
+
+func (s *store) delete(kind string, id int) bool {
+	if _, ok := s.objects[kind][id]; !ok {
+		return false
+	}
+	delete(s.objects[kind], id)
+	for _, objects := range s.objects {
+		for _, o := range objects {
+			o.links[kind] = removeInt(o.links[kind], id)
+			if kind == kindTag {
+				o.tags = removeInt(o.tags, id)
+			}
+		}
+	}
+	return true
+}
+
+func (s *Server) serveManagement(w http.ResponseWriter, r *http.Request, p string) {
+	if strings.HasPrefix(p, "recordings") {
+		s.serveRecordings(w, r, strings.TrimPrefix(strings.TrimPrefix(p, "recordings"), "/"))
+		return
+	}
+
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	kind, ok := kinds[segments[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if len(segments) == 1 {
+		switch r.Method {
+		case "GET":
+			s.list(w, r, kind)
+		case "POST":
+			s.createObject(w, r, kind, 0)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(segments[1])
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch {
+	case len(segments) == 2:
+		switch r.Method {
+		case "GET":
+			s.get(w, kind, id)
+		case "PUT":
+			s.updateObject(w, r, kind, id)
+		case "DELETE":
+			if !s.store.delete(kind, id) {
+				writeError(w, http.StatusNotFound, kind+" not found")
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	case kind == kindTag && len(segments) == 3 && segments[2] == "objects" && r.Method == "DELETE":
+		s.deleteTaggedObjects(w, id)
+	case kind == kindTag && len(segments) == 3 && r.Method == "POST":
+		objectKind, ok := kinds[segments[2]+"s"]
+		if !ok || objectKind == kindTag {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+		if _, ok := s.store.objects[kindTag][id]; !ok {
+			writeError(w, http.StatusNotFound, "tag not found")
+			return
+		}
+		s.createObject(w, r, objectKind, id)
+	case kind == kindTag && len(segments) == 4:
+		s.tag(w, r, id, segments[2], segments[3])
+	case kind == kindLab && len(segments) == 4 && segments[2] == "power" && r.Method == "PUT":
+		s.power(w, id, segments[3])
+	case len(segments) == 4:
+		s.link(w, r, kind, id, segments[2], segments[3])
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, kind string) {
+	result := make([]interface{}, 0)
+	for _, id := range s.store.sortedIDs(kind) {
+		o := s.store.objects[kind][id]
+		if matchesFilters(s.store.renderShallow(o), r.URL.Query()) && matchesTag(o, r.URL.Query().Get("tag")) {
+			result = append(result, s.store.render(kind, o))
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) get(w http.ResponseWriter, kind string, id int) {
+	o, ok := s.store.objects[kind][id]
+	if !ok {
+		writeError(w, http.StatusNotFound, kind+" not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.store.render(kind, o))
+}
+
+func (s *Server) createObject(w http.ResponseWriter, r *http.Request, kind string, tagID int) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "cannot read body")
+		return
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(body, &fields); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	o, status, msg := s.store.create(kind, fields)
+	if o == nil {
+		writeError(w, status, msg)
+		return
+	}
+	if tagID != 0 {
+		o.tags = append(o.tags, tagID)
+	}
+	writeJSON(w, http.StatusCreated, s.store.render(kind, o))
+}
+
+func (s *Server) updateObject(w http.ResponseWriter, r *http.Request, kind string, id int) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "cannot read body")
+		return
+	}
+	changes := make(map[string]interface{})
+	if err := json.Unmarshal(body, &changes); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	o, status, msg := s.store.update(kind, id, changes)
+	if o == nil {
+		writeError(w, status, msg)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.store.render(kind, o))
+}
+
+func (s *Server) link(w http.ResponseWriter, r *http.Request, kind string, id int, childKind string, childSegment string) {
+	if !containsString(childKinds[kind], childKind) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	childID, err := strconv.Atoi(childSegment)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	parent, parentExists := s.store.objects[kind][id]
+	_, childExists := s.store.objects[childKind][childID]
+
+	switch r.Method {
+	case "PUT":
+		if !parentExists {
+			writeError(w, http.StatusBadRequest, kind+" not found")
+			return
+		}
+		if !childExists {
+			writeError(w, http.StatusNotFound, childKind+" not found")
+			return
+		}
+		if containsInt(parent.links[childKind], childID) {
+			writeError(w, http.StatusBadRequest, childKind+" is already linked")
+			return
+		}
+		parent.links[childKind] = append(parent.links[childKind], childID)
+		writeJSON(w, http.StatusOK, s.store.render(kind, parent))
+	case "DELETE":
+		if !childExists {
+			writeError(w, http.StatusNotFound, childKind+" not found")
+			return
+		}
+		if !parentExists {
+			writeError(w, http.StatusBadRequest, kind+" not found")
+			return
+		}
+		if !containsInt(parent.links[childKind], childID) {
+			writeError(w, http.StatusNotFound, childKind+" is not linked")
+			return
+		}
+		parent.links[childKind] = removeInt(parent.links[childKind], childID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) tag(w http.ResponseWriter, r *http.Request, tagID int, objectKind string, objectSegment string) {
+	if !containsString(taggableKinds, objectKind) {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	objectID, err := strconv.Atoi(objectSegment)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	_, tagExists := s.store.objects[kindTag][tagID]
+	o, objectExists := s.store.objects[objectKind][objectID]
+	if !tagExists || !objectExists {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	switch r.Method {
+	case "PUT":
+		if containsInt(o.tags, tagID) {
+			writeError(w, http.StatusBadRequest, "object is already tagged")
+			return
+		}
+		o.tags = append(o.tags, tagID)
+	case "DELETE":
+		if !containsInt(o.tags, tagID) {
+			writeError(w, http.StatusNotFound, "object is not tagged")
+			return
+		}
+		o.tags = removeInt(o.tags, tagID)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.store.render(objectKind, o))
+}
+
+func (s *Server) power(w http.ResponseWriter, id int, state string) {
+	lab, ok := s.store.objects[kindLab][id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "lab not found")
+		return
+	}
+	if state != "on" && state != "off" {
+		writeError(w, http.StatusBadRequest, "invalid power state "+state)
+		return
+	}
+	lab.fields["power"] = state
+	writeJSON(w, http.StatusOK, s.store.render(kindLab, lab))
+}
+
+func (s *Server) deleteTaggedObjects(w http.ResponseWriter, tagID int) {
+	tag, ok := s.store.objects[kindTag][tagID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "tag not found")
+		return
+	}
+	result := s.store.render(kindTag, tag)
+	for _, kind := range taggableKinds {
+		for _, id := range s.store.sortedIDs(kind) {
+			if containsInt(s.store.objects[kind][id].tags, tagID) {
+				s.store.delete(kind, id)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) serveRecordings(w http.ResponseWriter, r *http.Request, p string) {
+	if p == "" {
+		if r.Method != "GET" {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		var paths []string
+		for recordingPath := range s.store.recordings {
+			paths = append(paths, recordingPath)
+		}
+		sort.Strings(paths)
+		result := make([]interface{}, 0)
+		for i, recordingPath := range paths {
+			name := recordingPath[strings.LastIndex(recordingPath, "/")+1:]
+			result = append(result, map[string]interface{}{"id": i + 1, "name": name, "path": recordingPath})
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	content, exists := s.store.recordings[p]
+	switch r.Method {
+	case "GET":
+		if !exists {
+			writeError(w, http.StatusNotFound, "record file not found")
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(content))
+	case "POST":
+		if exists {
+			writeError(w, http.StatusBadRequest, "record file already exists")
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "cannot read body")
+			return
+		}
+		s.store.recordings[p] = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	case "DELETE":
+		if !exists {
+			writeError(w, http.StatusNotFound, "record file not found")
+			return
+		}
+		delete(s.store.recordings, p)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// matchesFilters checks if all query parameters are equal to the corresponding fields, unknown parameters are ignored like in the real api.
+func matchesFilters(fields map[string]interface{}, query map[string][]string) bool {
+	for key, values := range query {
+		value, ok := fields[key]
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if fmt.Sprint(value) != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesTag checks if the object is tagged with the tag id of the tag filter, an empty filter matches all objects.
+func matchesTag(o *object, tag string) bool {
+	if tag == "" {
+		return true
+	}
+	id, err := strconv.Atoi(tag)
+	return err == nil && containsInt(o.tags, id)
+}
+
+func containsInt(list []int, i int) bool {
+	for _, e := range list {
+		if e == i {
+			return true
+		}
+	}
+	return false
+}
+
+func removeInt(list []int, i int) []int {
+	var result []int
+	for _, e := range list {
+		if e != i {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
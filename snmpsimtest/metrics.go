@@ -0,0 +1,380 @@
+package snmpsimtest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+PacketActivity is a simulated transport endpoint activity which is reported by the packet metrics.
+*/
+type PacketActivity struct {
+	TransportProtocol string
+	LocalAddress      string
+	PeerAddress       string
+
+	Total           int64
+	ParseFailures   int64
+	AuthFailures    int64
+	ContextFailures int64
+}
+
+/*
+MessageActivity is a simulated SNMP message activity which is reported by the message metrics.
+*/
+type MessageActivity struct {
+	TransportProtocol string
+	LocalAddress      string
+	PeerAddress       string
+	EngineID          string
+	SecurityModel     string
+	SecurityLevel     string
+	ContextEngineID   string
+	ContextName       string
+	PDUType           string
+	Recording         string
+
+	PDUs       int64
+	VarBinds   int64
+	Failures   int64
+	Variations []VariationActivity
+}
+
+/*
+VariationActivity is a simulated variation module activity.
+*/
+type VariationActivity struct {
+	Name     string
+	Total    int64
+	Failures int64
+}
+
+type activity struct {
+	packets  []PacketActivity
+	messages []MessageActivity
+	firstHit int
+	lastHit  int
+}
+
+/*
+AddPacketActivity adds packet activity to the packet metrics.
+*/
+func (s *Server) AddPacketActivity(a PacketActivity) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.activity.packets = append(s.activity.packets, a)
+	s.activity.hit()
+}
+
+/*
+AddMessageActivity adds message activity to the message metrics.
+*/
+func (s *Server) AddMessageActivity(a MessageActivity) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.activity.messages = append(s.activity.messages, a)
+	s.activity.hit()
+}
+
+func (a *activity) hit() {
+	now := int(time.Now().Unix())
+	if a.firstHit == 0 {
+		a.firstHit = now
+	}
+	a.lastHit = now
+}
+
+func (a PacketActivity) labels() map[string]string {
+	return map[string]string{
+		"transport_protocol": a.TransportProtocol,
+		"local_address":      a.LocalAddress,
+		"peer_address":       a.PeerAddress,
+	}
+}
+
+func (a MessageActivity) labels() map[string]string {
+	return map[string]string{
+		"transport_protocol": a.TransportProtocol,
+		"local_address":      a.LocalAddress,
+		"peer_address":       a.PeerAddress,
+		"engine_id":          a.EngineID,
+		"security_model":     a.SecurityModel,
+		"security_level":     a.SecurityLevel,
+		"context_engine_id":  a.ContextEngineID,
+		"context_name":       a.ContextName,
+		"pdu_type":           a.PDUType,
+		"recording":          a.Recording,
+	}
+}
+
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request, p string) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	switch {
+	case segments[0] == "processes":
+		s.serveProcesses(w, segments[1:])
+	case len(segments) >= 2 && segments[0] == "activity" && segments[1] == "packets":
+		var labels []map[string]string
+		for _, a := range s.activity.packets {
+			labels = append(labels, a.labels())
+		}
+		if s.serveFilters(w, segments[2:], p, PacketActivity{}.labels(), labels) {
+			return
+		}
+		var result struct {
+			FirstHit        *int  `json:"first_hit"`
+			LastHit         *int  `json:"last_hit"`
+			Total           int64 `json:"total"`
+			ParseFailures   int64 `json:"parse_failures"`
+			AuthFailures    int64 `json:"auth_failures"`
+			ContextFailures int64 `json:"context_failures"`
+		}
+		result.FirstHit, result.LastHit = s.activity.hits()
+		for _, a := range s.activity.packets {
+			if matchesLabels(a.labels(), r.URL.Query()) {
+				result.Total += a.Total
+				result.ParseFailures += a.ParseFailures
+				result.AuthFailures += a.AuthFailures
+				result.ContextFailures += a.ContextFailures
+			}
+		}
+		writeJSON(w, http.StatusOK, result)
+	case len(segments) >= 2 && segments[0] == "activity" && segments[1] == "messages":
+		var labels []map[string]string
+		for _, a := range s.activity.messages {
+			labels = append(labels, a.labels())
+		}
+		if s.serveFilters(w, segments[2:], p, MessageActivity{}.labels(), labels) {
+			return
+		}
+		type variation struct {
+			FirstHit *int   `json:"first_hit"`
+			LastHit  *int   `json:"last_hit"`
+			Name     string `json:"name"`
+			Total    int64  `json:"total"`
+			Failures int64  `json:"failures"`
+		}
+		var result struct {
+			FirstHit   *int        `json:"first_hit"`
+			LastHit    *int        `json:"last_hit"`
+			PDUs       int64       `json:"pdus"`
+			VarBinds   int64       `json:"var_binds"`
+			Failures   int64       `json:"failures"`
+			Variations []variation `json:"variations"`
+		}
+		result.FirstHit, result.LastHit = s.activity.hits()
+		result.Variations = make([]variation, 0)
+		variations := make(map[string]int)
+		for _, a := range s.activity.messages {
+			if !matchesLabels(a.labels(), r.URL.Query()) {
+				continue
+			}
+			result.PDUs += a.PDUs
+			result.VarBinds += a.VarBinds
+			result.Failures += a.Failures
+			for _, v := range a.Variations {
+				i, ok := variations[v.Name]
+				if !ok {
+					i = len(result.Variations)
+					variations[v.Name] = i
+					result.Variations = append(result.Variations, variation{FirstHit: result.FirstHit, LastHit: result.LastHit, Name: v.Name})
+				}
+				result.Variations[i].Total += v.Total
+				result.Variations[i].Failures += v.Failures
+			}
+		}
+		writeJSON(w, http.StatusOK, result)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// serveFilters answers requests for the filters of an activity and returns false if the request is not a filter request.
+func (s *Server) serveFilters(w http.ResponseWriter, segments []string, p string, keys map[string]string, labels []map[string]string) bool {
+	if len(segments) == 0 || segments[0] != "filters" {
+		return false
+	}
+	if len(segments) == 1 {
+		result := make(map[string]string)
+		for key := range keys {
+			result[key] = metricsEndpointPath + strings.Trim(p, "/") + "/" + key
+		}
+		writeJSON(w, http.StatusOK, result)
+		return true
+	}
+	if _, ok := keys[segments[1]]; !ok {
+		writeError(w, http.StatusNotFound, "unknown filter "+segments[1])
+		return true
+	}
+	var values []string
+	seen := make(map[string]bool)
+	for _, l := range labels {
+		if value := l[segments[1]]; value != "" && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	if values == nil {
+		values = make([]string, 0)
+	}
+	writeJSON(w, http.StatusOK, values)
+	return true
+}
+
+func (a activity) hits() (*int, *int) {
+	if a.firstHit == 0 {
+		return nil, nil
+	}
+	firstHit, lastHit := a.firstHit, a.lastHit
+	return &firstHit, &lastHit
+}
+
+func matchesLabels(labels map[string]string, query map[string][]string) bool {
+	for key, values := range query {
+		if value, ok := labels[key]; ok && len(values) > 0 && value != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+type consolePage struct {
+	ID        int    `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Text      string `json:"text"`
+}
+
+type process struct {
+	ID             int    `json:"id"`
+	Path           string `json:"path"`
+	Runtime        int    `json:"runtime"`
+	CPU            int    `json:"cpu"`
+	Memory         int    `json:"memory"`
+	Files          int    `json:"files"`
+	Exits          int    `json:"exits"`
+	Changes        int    `json:"changes"`
+	UpdateInterval int    `json:"update_interval"`
+	LastUpdate     string `json:"last_update"`
+	ConsolePages   struct {
+		Count      int    `json:"count"`
+		LastUpdate string `json:"last_update"`
+	} `json:"console_pages"`
+	Supervisor struct {
+		Hostname string `json:"hostname"`
+		WatchDir string `json:"watch_dir"`
+	} `json:"supervisor"`
+}
+
+type processEndpoint struct {
+	ID       int     `json:"id"`
+	Protocol string  `json:"protocol"`
+	Address  string  `json:"address"`
+	Process  process `json:"process"`
+}
+
+/*
+processes simulates the snmpsim process which serves the endpoints of all powered on labs.
+*/
+func (s *Server) processes() ([]process, []processEndpoint, []consolePage) {
+	var endpoints []processEndpoint
+	for _, labID := range s.store.sortedIDs(kindLab) {
+		lab := s.store.objects[kindLab][labID]
+		if lab.fields["power"] != "on" {
+			continue
+		}
+		for _, agentID := range lab.links[kindAgent] {
+			agent, ok := s.store.objects[kindAgent][agentID]
+			if !ok {
+				continue
+			}
+			for _, engineID := range agent.links[kindEngine] {
+				engine, ok := s.store.objects[kindEngine][engineID]
+				if !ok {
+					continue
+				}
+				for _, endpointID := range engine.links[kindEndpoint] {
+					endpoint, ok := s.store.objects[kindEndpoint][endpointID]
+					if !ok {
+						continue
+					}
+					endpoints = append(endpoints, processEndpoint{
+						ID:       endpointID,
+						Protocol: endpoint.fields["protocol"].(string),
+						Address:  endpoint.fields["address"].(string),
+					})
+				}
+			}
+		}
+	}
+	if len(endpoints) == 0 {
+		return nil, nil, nil
+	}
+
+	now := time.Now().Format("2006-01-02T15:04:05-07:00")
+	p := process{
+		ID:             1,
+		Path:           s.ProcessPath,
+		Memory:         s.ProcessMemory,
+		UpdateInterval: 5,
+		LastUpdate:     now,
+	}
+	p.ConsolePages.Count = 1
+	p.ConsolePages.LastUpdate = now
+	p.Supervisor.Hostname = s.SupervisorHostname
+	p.Supervisor.WatchDir = s.SupervisorWatchDir
+	for i := range endpoints {
+		endpoints[i].Process = p
+	}
+	pages := []consolePage{{ID: 1, Timestamp: now, Text: "snmpsim serving " + strconv.Itoa(len(endpoints)) + " endpoint(s)"}}
+	return []process{p}, endpoints, pages
+}
+
+func (s *Server) serveProcesses(w http.ResponseWriter, segments []string) {
+	processes, endpoints, pages := s.processes()
+	if len(segments) == 0 {
+		if processes == nil {
+			processes = make([]process, 0)
+		}
+		writeJSON(w, http.StatusOK, processes)
+		return
+	}
+
+	id, err := strconv.Atoi(segments[0])
+	if err != nil || len(processes) == 0 || processes[0].ID != id {
+		writeError(w, http.StatusNotFound, "process not found")
+		return
+	}
+	switch {
+	case len(segments) == 1:
+		writeJSON(w, http.StatusOK, processes[0])
+	case len(segments) == 2 && segments[1] == "endpoints":
+		writeJSON(w, http.StatusOK, endpoints)
+	case len(segments) == 3 && segments[1] == "endpoints":
+		for _, endpoint := range endpoints {
+			if strconv.Itoa(endpoint.ID) == segments[2] {
+				writeJSON(w, http.StatusOK, endpoint)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "endpoint not found")
+	case len(segments) == 2 && segments[1] == "console":
+		writeJSON(w, http.StatusOK, pages)
+	case len(segments) == 3 && segments[1] == "console":
+		for _, page := range pages {
+			if strconv.Itoa(page.ID) == segments[2] {
+				writeJSON(w, http.StatusOK, page)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "console page not found")
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Package snmpsimtest provides an in-memory fake of the snmpsim control plane for tests.
+
+The fake implements the parts of the snmpsim/mgmt/v1 and snmpsim/metrics/v1 apis which are used by the
+snmpsimclient package. It keeps all objects in memory, answers with the same status codes as the real api
+and allows to inject faults for single requests. It does not run an SNMP responder.
+*/
+package snmpsimtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mgmtEndpointPath    = "/snmpsim/mgmt/v1/"
+	metricsEndpointPath = "/snmpsim/metrics/v1/"
+)
+
+/*
+Server is a fake snmpsim control plane serving the management and the metrics api on the same address.
+*/
+type Server struct {
+	// URL is the base url of the fake, it can be passed to NewManagementClient and NewMetricsClient.
+	URL string
+
+	// ProcessPath is the path of the simulated snmpsim process which is running while a lab is powered on.
+	ProcessPath string
+	// SupervisorHostname is the hostname reported by the simulated process supervisor.
+	SupervisorHostname string
+	// SupervisorWatchDir is the watch dir reported by the simulated process supervisor.
+	SupervisorWatchDir string
+	// ProcessMemory is the memory usage in MB reported for the simulated process.
+	ProcessMemory int
+
+	server *httptest.Server
+
+	mutex    sync.Mutex
+	username string
+	password string
+	faults   []*Fault
+	store    *store
+	activity activity
+}
+
+/*
+Fault describes an error the fake answers with instead of processing the request.
+*/
+type Fault struct {
+	// Method is the http method the fault applies to, an empty method matches all methods.
+	Method string
+	// Path is a pattern as used by path.Match for the request path, e.g. "/snmpsim/mgmt/v1/engines/*/endpoint/*".
+	Path string
+	// StatusCode is the status code of the response.
+	StatusCode int
+	// Message is the message of the error response.
+	Message string
+	// Delay is waited before the response is sent.
+	Delay time.Duration
+	// Count is the number of requests the fault applies to, 0 means unlimited.
+	Count int
+}
+
+/*
+NewServer starts a new fake control plane. It has to be closed with Close.
+*/
+func NewServer() *Server {
+	s := &Server{
+		ProcessPath:        "/opt/snmpsim/supervised/snmpsim-run-labs.sh",
+		SupervisorHostname: "sim",
+		SupervisorWatchDir: "/opt/snmpsim/supervised",
+		store:              newStore(),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL + "/"
+	return s
+}
+
+/*
+Close shuts down the fake.
+*/
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+/*
+SetBasicAuth makes the fake reject all requests without the given credentials with 401.
+*/
+func (s *Server) SetBasicAuth(username, password string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.username = username
+	s.password = password
+}
+
+/*
+AddFault adds a fault. Faults are checked in the order they were added.
+*/
+func (s *Server) AddFault(fault Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+/*
+ClearFaults removes all faults.
+*/
+func (s *Server) ClearFaults() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = nil
+}
+
+/*
+Reset deletes all objects, record files, activity and faults.
+*/
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.store = newStore()
+	s.activity = activity{}
+	s.faults = nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if fault := s.matchFault(r); fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		writeError(w, fault.StatusCode, fault.Message)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != s.username || password != s.password {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, mgmtEndpointPath):
+		s.serveManagement(w, r, strings.TrimPrefix(r.URL.Path, mgmtEndpointPath))
+	case strings.HasPrefix(r.URL.Path, metricsEndpointPath):
+		s.serveMetrics(w, r, strings.TrimPrefix(r.URL.Path, metricsEndpointPath))
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) matchFault(r *http.Request) *Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != r.Method {
+			continue
+		}
+		if match, err := path.Match(fault.Path, r.URL.Path); err != nil || !match {
+			continue
+		}
+		if fault.Count > 0 {
+			fault.Count--
+			if fault.Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		f := *fault
+		return &f
+	}
+	return nil
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Message: message, Status: status})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
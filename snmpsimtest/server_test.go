@@ -0,0 +1,89 @@
+package snmpsimtest_test
+
+import (
+	snmpsimclient "github.com/inexio/snmpsim-restapi-go-client"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestServer_Faults(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := snmpsimclient.NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	client.DisableRetries()
+
+	server.AddFault(snmpsimtest.Fault{Method: "POST", Path: "/snmpsim/mgmt/v1/labs", StatusCode: 503, Count: 1})
+
+	_, err = client.CreateLab("lab")
+	assert.True(t, errors.Is(err, snmpsimclient.ErrServer), "injected fault was not returned")
+
+	lab, err := client.CreateLab("lab")
+	if assert.NoError(t, err, "fault was applied more often than configured") {
+		assert.Equal(t, "lab", lab.Name)
+		assert.Equal(t, "off", lab.Power)
+	}
+
+	server.AddFault(snmpsimtest.Fault{Path: "/snmpsim/mgmt/v1/labs/*", StatusCode: 500})
+	_, err = client.GetLab(lab.ID)
+	assert.True(t, errors.Is(err, snmpsimclient.ErrServer), "injected fault was not returned")
+
+	server.ClearFaults()
+	_, err = client.GetLab(lab.ID)
+	assert.NoError(t, err, "fault was not cleared")
+}
+
+func TestServer_BasicAuth(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+	server.SetBasicAuth("user", "password")
+
+	client, err := snmpsimclient.NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	_, err = client.GetLabs(nil)
+	assert.True(t, errors.Is(err, snmpsimclient.ErrUnauthorized), "request without credentials was not rejected")
+
+	err = client.SetUsernameAndPassword("user", "password")
+	if !assert.NoError(t, err, "error while setting username and password") {
+		return
+	}
+	_, err = client.GetLabs(nil)
+	assert.NoError(t, err, "request with credentials was rejected")
+}
+
+func TestServer_Activity(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	server.AddPacketActivity(snmpsimtest.PacketActivity{TransportProtocol: "udpv4", LocalAddress: "127.0.0.1:1161", Total: 10, AuthFailures: 1})
+	server.AddPacketActivity(snmpsimtest.PacketActivity{TransportProtocol: "udpv4", LocalAddress: "127.0.0.1:1162", Total: 5})
+
+	client, err := snmpsimclient.NewMetricsClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	packets, err := client.GetPackets(nil)
+	if assert.NoError(t, err, "error during GetPackets") {
+		assert.Equal(t, int64(15), *packets.Total)
+		assert.Equal(t, int64(1), *packets.AuthFailures)
+	}
+
+	packets, err = client.GetPackets(map[string]string{"local_address": "127.0.0.1:1162"})
+	if assert.NoError(t, err, "error during GetPackets") {
+		assert.Equal(t, int64(5), *packets.Total)
+	}
+
+	values, err := client.GetPossibleValuesForPacketFilter("local_address")
+	if assert.NoError(t, err, "error during GetPossibleValuesForPacketFilter") {
+		assert.Equal(t, []string{"127.0.0.1:1161", "127.0.0.1:1162"}, values)
+	}
+}
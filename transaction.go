@@ -0,0 +1,385 @@
+package snmpsimclient
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+/*
+Transaction records every object and link created through it, so that a partially built setup can be undone.
+If one of its calls fails, everything that was recorded so far is undone in reverse order automatically.
+A Transaction is not safe for concurrent use.
+*/
+type Transaction struct {
+	client   *ManagementClient
+	ctx      context.Context
+	steps    []rollbackStep
+	finished bool
+}
+
+type rollbackStep struct {
+	description string
+	undo        func(ctx context.Context) error
+}
+
+/*
+RollbackError is returned when undoing a transaction failed for one or more steps.
+*/
+type RollbackError struct {
+	// Err is the error which caused the rollback, it is nil if Rollback was called explicitly.
+	Err error
+	// RollbackErrors contains an error for every step that could not be undone.
+	RollbackErrors []error
+}
+
+func (r *RollbackError) Error() string {
+	var msgs []string
+	for _, err := range r.RollbackErrors {
+		msgs = append(msgs, err.Error())
+	}
+	msg := "rollback failed: " + strings.Join(msgs, "; ")
+	if r.Err != nil {
+		msg = r.Err.Error() + " // " + msg
+	}
+	return msg
+}
+
+/*
+Unwrap returns the error which caused the rollback.
+*/
+func (r *RollbackError) Unwrap() error {
+	return r.Err
+}
+
+/*
+BeginTransaction starts a new transaction.
+*/
+func (c *ManagementClient) BeginTransaction() *Transaction {
+	return c.BeginTransactionCtx(context.Background())
+}
+
+/*
+BeginTransactionCtx is like BeginTransaction but uses the given context for all requests of the transaction.
+An automatic rollback after a failed call is not bound to the context, so that it also runs when the context was canceled.
+*/
+func (c *ManagementClient) BeginTransactionCtx(ctx context.Context) *Transaction {
+	return &Transaction{client: c, ctx: ctx}
+}
+
+/*
+Commit finishes the transaction and keeps all created objects and links.
+*/
+func (t *Transaction) Commit() error {
+	if t.finished {
+		return errors.New("transaction already finished")
+	}
+	t.finished = true
+	t.steps = nil
+	return nil
+}
+
+/*
+Rollback finishes the transaction and undoes all recorded steps in reverse order.
+All steps are tried, even if some of them fail.
+*/
+func (t *Transaction) Rollback() error {
+	return t.RollbackCtx(t.ctx)
+}
+
+/*
+RollbackCtx is like Rollback but uses the given context for the requests.
+*/
+func (t *Transaction) RollbackCtx(ctx context.Context) error {
+	if t.finished {
+		return errors.New("transaction already finished")
+	}
+	errs := t.rollback(ctx)
+	if len(errs) != 0 {
+		return &RollbackError{RollbackErrors: errs}
+	}
+	return nil
+}
+
+func (t *Transaction) rollback(ctx context.Context) []error {
+	t.finished = true
+	var errs []error
+	for i := len(t.steps) - 1; i >= 0; i-- {
+		err := t.steps[i].undo(ctx)
+		if err != nil {
+			errs = append(errs, errors.Wrap(err, "error while undoing '"+t.steps[i].description+"'"))
+		}
+	}
+	t.steps = nil
+	return errs
+}
+
+// record adds the undo step if the call was successful and there is something to undo, otherwise the transaction is rolled back.
+func (t *Transaction) record(err error, description string, undo func(ctx context.Context) error) error {
+	if err != nil {
+		errs := t.rollback(context.Background())
+		if len(errs) != 0 {
+			return &RollbackError{Err: err, RollbackErrors: errs}
+		}
+		return err
+	}
+	if undo != nil {
+		t.steps = append(t.steps, rollbackStep{description: description, undo: undo})
+	}
+	return nil
+}
+
+func (t *Transaction) check() error {
+	if t.finished {
+		return errors.New("transaction already finished")
+	}
+	if t.client == nil || !t.client.isValid() {
+		return &NotValidError{}
+	}
+	return nil
+}
+
+/*
+CreateLab creates a new lab and records its deletion.
+*/
+func (t *Transaction) CreateLab(name string) (Lab, error) {
+	if err := t.check(); err != nil {
+		return Lab{}, err
+	}
+	lab, err := t.client.CreateLabCtx(t.ctx, name)
+	return lab, t.record(err, "create lab "+name, func(ctx context.Context) error {
+		return t.client.DeleteLabCtx(ctx, lab.ID)
+	})
+}
+
+/*
+CreateLabWithTag creates a new lab tagged with the given tag and records its deletion.
+*/
+func (t *Transaction) CreateLabWithTag(name string, tagID int) (Lab, error) {
+	if err := t.check(); err != nil {
+		return Lab{}, err
+	}
+	lab, err := t.client.CreateLabWithTagCtx(t.ctx, name, tagID)
+	return lab, t.record(err, "create lab "+name, func(ctx context.Context) error {
+		return t.client.DeleteLabCtx(ctx, lab.ID)
+	})
+}
+
+/*
+CreateEngine creates a new engine and records its deletion.
+*/
+func (t *Transaction) CreateEngine(name, engineID string) (Engine, error) {
+	if err := t.check(); err != nil {
+		return Engine{}, err
+	}
+	engine, err := t.client.CreateEngineCtx(t.ctx, name, engineID)
+	return engine, t.record(err, "create engine "+name, func(ctx context.Context) error {
+		return t.client.DeleteEngineCtx(ctx, engine.ID)
+	})
+}
+
+/*
+CreateEngineWithTag creates a new engine tagged with the given tag and records its deletion.
+*/
+func (t *Transaction) CreateEngineWithTag(name, engineID string, tagID int) (Engine, error) {
+	if err := t.check(); err != nil {
+		return Engine{}, err
+	}
+	engine, err := t.client.CreateEngineWithTagCtx(t.ctx, name, engineID, tagID)
+	return engine, t.record(err, "create engine "+name, func(ctx context.Context) error {
+		return t.client.DeleteEngineCtx(ctx, engine.ID)
+	})
+}
+
+/*
+CreateAgent creates a new agent and records its deletion.
+*/
+func (t *Transaction) CreateAgent(name, dataDir string) (Agent, error) {
+	if err := t.check(); err != nil {
+		return Agent{}, err
+	}
+	agent, err := t.client.CreateAgentCtx(t.ctx, name, dataDir)
+	return agent, t.record(err, "create agent "+name, func(ctx context.Context) error {
+		return t.client.DeleteAgentCtx(ctx, agent.ID)
+	})
+}
+
+/*
+CreateAgentWithTag creates a new agent tagged with the given tag and records its deletion.
+*/
+func (t *Transaction) CreateAgentWithTag(name, dataDir string, tagID int) (Agent, error) {
+	if err := t.check(); err != nil {
+		return Agent{}, err
+	}
+	agent, err := t.client.CreateAgentWithTagCtx(t.ctx, name, dataDir, tagID)
+	return agent, t.record(err, "create agent "+name, func(ctx context.Context) error {
+		return t.client.DeleteAgentCtx(ctx, agent.ID)
+	})
+}
+
+/*
+CreateEndpoint creates a new endpoint and records its deletion.
+*/
+func (t *Transaction) CreateEndpoint(name, address, protocol string) (Endpoint, error) {
+	if err := t.check(); err != nil {
+		return Endpoint{}, err
+	}
+	endpoint, err := t.client.CreateEndpointCtx(t.ctx, name, address, protocol)
+	return endpoint, t.record(err, "create endpoint "+name, func(ctx context.Context) error {
+		return t.client.DeleteEndpointCtx(ctx, endpoint.ID)
+	})
+}
+
+/*
+CreateEndpointWithTag creates a new endpoint tagged with the given tag and records its deletion.
+*/
+func (t *Transaction) CreateEndpointWithTag(name, address, protocol string, tagID int) (Endpoint, error) {
+	if err := t.check(); err != nil {
+		return Endpoint{}, err
+	}
+	endpoint, err := t.client.CreateEndpointWithTagCtx(t.ctx, name, address, protocol, tagID)
+	return endpoint, t.record(err, "create endpoint "+name, func(ctx context.Context) error {
+		return t.client.DeleteEndpointCtx(ctx, endpoint.ID)
+	})
+}
+
+/*
+CreateUser creates a new user and records its deletion.
+*/
+func (t *Transaction) CreateUser(user, name, authKey, authProto, privKey, privProto string) (User, error) {
+	if err := t.check(); err != nil {
+		return User{}, err
+	}
+	newUser, err := t.client.CreateUserCtx(t.ctx, user, name, authKey, authProto, privKey, privProto)
+	return newUser, t.record(err, "create user "+name, func(ctx context.Context) error {
+		return t.client.DeleteUserCtx(ctx, newUser.ID)
+	})
+}
+
+/*
+CreateUserWithTag creates a new user tagged with the given tag and records its deletion.
+*/
+func (t *Transaction) CreateUserWithTag(user, name, authKey, authProto, privKey, privProto string, tagID int) (User, error) {
+	if err := t.check(); err != nil {
+		return User{}, err
+	}
+	newUser, err := t.client.CreateUserWithTagCtx(t.ctx, user, name, authKey, authProto, privKey, privProto, tagID)
+	return newUser, t.record(err, "create user "+name, func(ctx context.Context) error {
+		return t.client.DeleteUserCtx(ctx, newUser.ID)
+	})
+}
+
+/*
+CreateTag creates a new tag and records its deletion.
+*/
+func (t *Transaction) CreateTag(name, description string) (Tag, error) {
+	if err := t.check(); err != nil {
+		return Tag{}, err
+	}
+	tag, err := t.client.CreateTagCtx(t.ctx, name, description)
+	return tag, t.record(err, "create tag "+name, func(ctx context.Context) error {
+		return t.client.DeleteTagCtx(ctx, tag.ID)
+	})
+}
+
+/*
+UploadRecordFile uploads the given record file and records its deletion.
+*/
+func (t *Transaction) UploadRecordFile(localPath, remotePath string) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	err := t.client.UploadRecordFileCtx(t.ctx, localPath, remotePath)
+	return t.record(err, "upload record file "+remotePath, func(ctx context.Context) error {
+		return t.client.DeleteRecordFileCtx(ctx, remotePath)
+	})
+}
+
+/*
+UploadRecordFileString uploads the given record data and records its deletion.
+*/
+func (t *Transaction) UploadRecordFileString(recordContents *string, remotePath string) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	err := t.client.UploadRecordFileStringCtx(t.ctx, recordContents, remotePath)
+	return t.record(err, "upload record file "+remotePath, func(ctx context.Context) error {
+		return t.client.DeleteRecordFileCtx(ctx, remotePath)
+	})
+}
+
+/*
+AddAgentToLab adds an Agent to a Lab and records its removal.
+*/
+func (t *Transaction) AddAgentToLab(labID, agentID int) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	err := t.client.AddAgentToLabCtx(t.ctx, labID, agentID)
+	return t.record(err, "add agent "+strconv.Itoa(agentID)+" to lab "+strconv.Itoa(labID), func(ctx context.Context) error {
+		return t.client.RemoveAgentFromLabCtx(ctx, labID, agentID)
+	})
+}
+
+/*
+AddEngineToAgent adds an Engine to an Agent and records its removal.
+*/
+func (t *Transaction) AddEngineToAgent(agentID, engineID int) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	err := t.client.AddEngineToAgentCtx(t.ctx, agentID, engineID)
+	return t.record(err, "add engine "+strconv.Itoa(engineID)+" to agent "+strconv.Itoa(agentID), func(ctx context.Context) error {
+		return t.client.RemoveEngineFromAgentCtx(ctx, agentID, engineID)
+	})
+}
+
+/*
+AddUserToEngine adds an User to an Engine and records its removal.
+*/
+func (t *Transaction) AddUserToEngine(engineID, userID int) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	err := t.client.AddUserToEngineCtx(t.ctx, engineID, userID)
+	return t.record(err, "add user "+strconv.Itoa(userID)+" to engine "+strconv.Itoa(engineID), func(ctx context.Context) error {
+		return t.client.RemoveUserFromEngineCtx(ctx, engineID, userID)
+	})
+}
+
+/*
+AddEndpointToEngine adds an Endpoint to an Engine and records its removal.
+*/
+func (t *Transaction) AddEndpointToEngine(engineID, endpointID int) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	err := t.client.AddEndpointToEngineCtx(t.ctx, engineID, endpointID)
+	return t.record(err, "add endpoint "+strconv.Itoa(endpointID)+" to engine "+strconv.Itoa(engineID), func(ctx context.Context) error {
+		return t.client.RemoveEndpointFromEngineCtx(ctx, engineID, endpointID)
+	})
+}
+
+/*
+SetLabPower activates or deactivates a lab and records the restoration of its previous power state.
+Nothing is recorded if the lab already has the given power state.
+*/
+func (t *Transaction) SetLabPower(labID int, power bool) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	description := "set power of lab " + strconv.Itoa(labID)
+	lab, err := t.client.GetLabCtx(t.ctx, labID)
+	if err != nil {
+		return t.record(errors.Wrap(err, "error while getting lab"), description, nil)
+	}
+	previous := lab.Power == "on"
+	err = t.client.SetLabPowerCtx(t.ctx, labID, power)
+	if err != nil || previous == power {
+		return t.record(err, description, nil)
+	}
+	return t.record(err, description, func(ctx context.Context) error {
+		return t.client.SetLabPowerCtx(ctx, labID, previous)
+	})
+}
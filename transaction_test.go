@@ -0,0 +1,141 @@
+package snmpsimclient
+
+import (
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTransactionTestServer(requests *[]string, failing map[string]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/"+mgmtEndpointPath)
+		*requests = append(*requests, request)
+		if status, ok := failing[request]; ok {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"message": "failure", "status": 400}`))
+			return
+		}
+		switch r.Method {
+		case "POST":
+			w.WriteHeader(201)
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		case "PUT":
+			w.WriteHeader(200)
+		case "DELETE":
+			w.WriteHeader(204)
+		}
+	}))
+}
+
+func TestTransaction_RollbackOnFailure(t *testing.T) {
+	var requests []string
+	server := newTransactionTestServer(&requests, map[string]int{"PUT engines/1/endpoint/1": 400})
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	tx := client.BeginTransaction()
+	_, err = tx.CreateEngine("engine", "010203040507080900000000")
+	if !assert.NoError(t, err, "error while creating engine") {
+		return
+	}
+	_, err = tx.CreateEndpoint("endpoint", "127.0.0.1:1161", "udpv4")
+	if !assert.NoError(t, err, "error while creating endpoint") {
+		return
+	}
+	err = tx.AddEndpointToEngine(1, 1)
+	if assert.Error(t, err, "no error when adding the endpoint failed") {
+		if err, ok := err.(HTTPError); assert.True(t, ok, "error is not a http error", err.Error()) {
+			assert.Equal(t, 400, err.StatusCode)
+		}
+	}
+
+	assert.Equal(t, []string{
+		"POST engines",
+		"POST endpoints",
+		"PUT engines/1/endpoint/1",
+		"DELETE endpoints/1",
+		"DELETE engines/1",
+	}, requests)
+
+	_, err = tx.CreateLab("lab")
+	assert.Error(t, err, "no error when using a finished transaction")
+}
+
+func TestTransaction_Rollback(t *testing.T) {
+	var requests []string
+	server := newTransactionTestServer(&requests, map[string]int{"DELETE engines/1": 400})
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+
+	tx := client.BeginTransaction()
+	_, err = tx.CreateEngine("engine", "010203040507080900000000")
+	assert.NoError(t, err, "error while creating engine")
+	_, err = tx.CreateAgent("agent", "data")
+	assert.NoError(t, err, "error while creating agent")
+	err = tx.AddEngineToAgent(1, 1)
+	assert.NoError(t, err, "error while adding engine to agent")
+
+	err = tx.Rollback()
+	if assert.Error(t, err, "no error when a rollback step failed") {
+		if err, ok := err.(*RollbackError); assert.True(t, ok, "error is not a rollback error", err.Error()) {
+			assert.Len(t, err.RollbackErrors, 1)
+			assert.Nil(t, err.Err)
+		}
+	}
+	assert.Equal(t, []string{
+		"POST engines",
+		"POST agents",
+		"PUT agents/1/engine/1",
+		"DELETE agents/1/engine/1",
+		"DELETE agents/1",
+		"DELETE engines/1",
+	}, requests)
+
+	assert.Error(t, tx.Commit(), "no error when committing a finished transaction")
+}
+
+func TestTransaction_SetLabPower(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	lab, err := client.CreateLab("lab")
+	if !assert.NoError(t, err, "error while creating lab") {
+		return
+	}
+	power := func() string {
+		lab, err := client.GetLab(lab.ID)
+		assert.NoError(t, err, "error while getting lab")
+		return lab.Power
+	}
+
+	tx := client.BeginTransaction()
+	assert.NoError(t, tx.SetLabPower(lab.ID, true))
+	assert.Equal(t, "on", power())
+	assert.NoError(t, tx.Rollback())
+	assert.Equal(t, "off", power(), "rollback did not restore the previous power state")
+
+	//powering on a lab which is already on must not power it off during the rollback
+	assert.NoError(t, client.SetLabPower(lab.ID, true))
+	tx = client.BeginTransaction()
+	assert.NoError(t, tx.SetLabPower(lab.ID, true))
+	assert.NoError(t, tx.Rollback())
+	assert.Equal(t, "on", power(), "rollback changed the power state of a lab which was already on")
+
+	tx = client.BeginTransaction()
+	assert.Error(t, tx.SetLabPower(-1, true), "no error for unknown lab")
+}
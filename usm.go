@@ -0,0 +1,236 @@
+package snmpsimclient
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"github.com/pkg/errors"
+	"hash"
+	"strconv"
+	"strings"
+)
+
+// MinKeyLength is the minimum length of the auth and priv keys of an SNMPv3 user, see RFC 3414.
+const MinKeyLength = 8
+
+// passwordToKeyLength is the number of octets of the expanded passphrase which are hashed to get the master key, see RFC 3414 A.2.
+const passwordToKeyLength = 1048576
+
+/*
+AuthProtocol is the authentication protocol of an SNMPv3 user.
+*/
+type AuthProtocol string
+
+const (
+	// AuthNone disables authentication.
+	AuthNone AuthProtocol = "none"
+	// AuthMD5 is HMAC-MD5-96.
+	AuthMD5 AuthProtocol = "md5"
+	// AuthSHA is HMAC-SHA-96.
+	AuthSHA AuthProtocol = "sha"
+	// AuthSHA224 is HMAC-SHA-224.
+	AuthSHA224 AuthProtocol = "sha224"
+	// AuthSHA256 is HMAC-SHA-256.
+	AuthSHA256 AuthProtocol = "sha256"
+	// AuthSHA384 is HMAC-SHA-384.
+	AuthSHA384 AuthProtocol = "sha384"
+	// AuthSHA512 is HMAC-SHA-512.
+	AuthSHA512 AuthProtocol = "sha512"
+)
+
+var authProtocols = []AuthProtocol{AuthNone, AuthMD5, AuthSHA, AuthSHA224, AuthSHA256, AuthSHA384, AuthSHA512}
+
+/*
+PrivProtocol is the privacy protocol of an SNMPv3 user.
+*/
+type PrivProtocol string
+
+const (
+	// PrivNone disables encryption.
+	PrivNone PrivProtocol = "none"
+	// PrivDES is CBC-DES.
+	PrivDES PrivProtocol = "des"
+	// Priv3DES is CBC-3DES-EDE.
+	Priv3DES PrivProtocol = "3des"
+	// PrivAES is CFB-AES-128, it is an alias of PrivAES128.
+	PrivAES PrivProtocol = "aes"
+	// PrivAES128 is CFB-AES-128.
+	PrivAES128 PrivProtocol = "aes128"
+	// PrivAES192 is CFB-AES-192.
+	PrivAES192 PrivProtocol = "aes192"
+	// PrivAES256 is CFB-AES-256.
+	PrivAES256 PrivProtocol = "aes256"
+)
+
+var privProtocols = []PrivProtocol{PrivNone, PrivDES, Priv3DES, PrivAES, PrivAES128, PrivAES192, PrivAES256}
+
+/*
+ParseAuthProtocol returns the auth protocol with the given name, ignoring case. An empty name is AuthNone.
+*/
+func ParseAuthProtocol(name string) (AuthProtocol, error) {
+	if name == "" {
+		return AuthNone, nil
+	}
+	for _, protocol := range authProtocols {
+		if strings.EqualFold(name, string(protocol)) {
+			return protocol, nil
+		}
+	}
+	return "", errors.New("invalid auth protocol " + name)
+}
+
+/*
+ParsePrivProtocol returns the priv protocol with the given name, ignoring case. An empty name is PrivNone.
+*/
+func ParsePrivProtocol(name string) (PrivProtocol, error) {
+	if name == "" {
+		return PrivNone, nil
+	}
+	for _, protocol := range privProtocols {
+		if strings.EqualFold(name, string(protocol)) {
+			return protocol, nil
+		}
+	}
+	return "", errors.New("invalid priv protocol " + name)
+}
+
+// hash returns the hash function of the auth protocol, it returns nil for AuthNone.
+func (p AuthProtocol) hash() func() hash.Hash {
+	switch p {
+	case AuthMD5:
+		return md5.New
+	case AuthSHA:
+		return sha1.New
+	case AuthSHA224:
+		return sha256.New224
+	case AuthSHA256:
+		return sha256.New
+	case AuthSHA384:
+		return sha512.New384
+	case AuthSHA512:
+		return sha512.New
+	}
+	return nil
+}
+
+// apiName returns the name of the priv protocol as it is sent to the api, snmpsim only knows aes128 as aes.
+func (p PrivProtocol) apiName() string {
+	if p == PrivAES128 {
+		return string(PrivAES)
+	}
+	return string(p)
+}
+
+// keyLength returns the length of the localized key of the priv protocol.
+func (p PrivProtocol) keyLength() int {
+	switch p {
+	case PrivDES, PrivAES, PrivAES128:
+		return 16
+	case PrivAES192:
+		return 24
+	case Priv3DES, PrivAES256:
+		return 32
+	}
+	return 0
+}
+
+/*
+ValidateUSM checks that the protocols are supported and that the keys are long enough to be used with them.
+Privacy requires authentication, so a priv protocol can only be used together with an auth protocol.
+*/
+func ValidateUSM(authKey, authProto, privKey, privProto string) error {
+	auth, err := ParseAuthProtocol(authProto)
+	if err != nil {
+		return err
+	}
+	priv, err := ParsePrivProtocol(privProto)
+	if err != nil {
+		return err
+	}
+	if auth != AuthNone && len(authKey) < MinKeyLength {
+		return errors.New("auth key must have at least " + strconv.Itoa(MinKeyLength) + " characters")
+	}
+	if priv != PrivNone {
+		if auth == AuthNone {
+			return errors.New("priv protocol " + string(priv) + " requires an auth protocol")
+		}
+		if len(privKey) < MinKeyLength {
+			return errors.New("priv key must have at least " + strconv.Itoa(MinKeyLength) + " characters")
+		}
+	}
+	return nil
+}
+
+/*
+PasswordToKey derives the master key from a passphrase with the password to key algorithm of RFC 3414.
+*/
+func PasswordToKey(auth AuthProtocol, passphrase string) ([]byte, error) {
+	newHash := auth.hash()
+	if newHash == nil {
+		return nil, errors.New("auth protocol " + string(auth) + " has no keys")
+	}
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+
+	h := newHash()
+	chunk := make([]byte, 64)
+	for i := 0; i < passwordToKeyLength; i += len(chunk) {
+		for j := range chunk {
+			chunk[j] = passphrase[(i+j)%len(passphrase)]
+		}
+		_, _ = h.Write(chunk)
+	}
+	return h.Sum(nil), nil
+}
+
+/*
+LocalizeAuthKey derives the auth key of a user for the engine with the given engine id from a passphrase, see RFC 3414.
+*/
+func LocalizeAuthKey(auth AuthProtocol, passphrase, engineID string) ([]byte, error) {
+	id, err := ParseEngineID(engineID)
+	if err != nil {
+		return nil, err
+	}
+	return localizeKey(auth, passphrase, id.Bytes())
+}
+
+/*
+LocalizePrivKey derives the priv key of a user for the engine with the given engine id from a passphrase.
+Protocols which need a longer key than the auth protocol provides extend it as described in draft-reeder-snmpv3-usm-3desede.
+*/
+func LocalizePrivKey(auth AuthProtocol, priv PrivProtocol, passphrase, engineID string) ([]byte, error) {
+	length := priv.keyLength()
+	if length == 0 {
+		return nil, errors.New("priv protocol " + string(priv) + " has no keys")
+	}
+	id, err := ParseEngineID(engineID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := localizeKey(auth, passphrase, id.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	for last := key; len(key) < length; {
+		last, err = localizeKey(auth, string(last), id.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		key = append(key, last...)
+	}
+	return key[:length], nil
+}
+
+func localizeKey(auth AuthProtocol, passphrase string, engineID []byte) ([]byte, error) {
+	key, err := PasswordToKey(auth, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	h := auth.hash()()
+	_, _ = h.Write(key)
+	_, _ = h.Write(engineID)
+	_, _ = h.Write(key)
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,111 @@
+package snmpsimclient
+
+import (
+	"encoding/hex"
+	"github.com/inexio/snmpsim-restapi-go-client/snmpsimtest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseProtocols(t *testing.T) {
+	auth, err := ParseAuthProtocol("SHA256")
+	if assert.NoError(t, err) {
+		assert.Equal(t, AuthSHA256, auth)
+	}
+	auth, err = ParseAuthProtocol("")
+	if assert.NoError(t, err) {
+		assert.Equal(t, AuthNone, auth)
+	}
+	_, err = ParseAuthProtocol("sha1024")
+	assert.Error(t, err, "no error for unknown auth protocol")
+
+	priv, err := ParsePrivProtocol("3DES")
+	if assert.NoError(t, err) {
+		assert.Equal(t, Priv3DES, priv)
+	}
+	_, err = ParsePrivProtocol("blowfish")
+	assert.Error(t, err, "no error for unknown priv protocol")
+}
+
+func TestValidateUSM(t *testing.T) {
+	assert.NoError(t, ValidateUSM("", "", "", ""))
+	assert.NoError(t, ValidateUSM("authkey1", "md5", "", ""))
+	assert.NoError(t, ValidateUSM("authkey1", "SHA512", "privkey1", "AES256"))
+	assert.Error(t, ValidateUSM("short", "md5", "", ""), "no error for short auth key")
+	assert.Error(t, ValidateUSM("authkey1", "md5", "short", "des"), "no error for short priv key")
+	assert.Error(t, ValidateUSM("", "", "privkey1", "des"), "no error for priv protocol without auth protocol")
+	assert.Error(t, ValidateUSM("authkey1", "md4", "", ""), "no error for unknown auth protocol")
+}
+
+func TestLocalizeKeys(t *testing.T) {
+	//test vectors from RFC 3414 A.3
+	engineID := "000000000000000000000002"
+	key, err := PasswordToKey(AuthMD5, "maplesyrup")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "9faf3283884e92834ebc9847d8edd963", hex.EncodeToString(key))
+	}
+	key, err = LocalizeAuthKey(AuthMD5, "maplesyrup", engineID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "526f5eed9fcce26f8964c2930787d82b", hex.EncodeToString(key))
+	}
+	key, err = PasswordToKey(AuthSHA, "maplesyrup")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "9fb5cc0381497b3793528939ff788d5d79145211", hex.EncodeToString(key))
+	}
+	key, err = LocalizeAuthKey(AuthSHA, "maplesyrup", engineID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "6695febc9288e36282235fc7151f128497b38f3f", hex.EncodeToString(key))
+	}
+
+	key, err = LocalizePrivKey(AuthSHA, PrivDES, "maplesyrup", engineID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "6695febc9288e36282235fc7151f1284", hex.EncodeToString(key))
+	}
+	key, err = LocalizePrivKey(AuthMD5, PrivAES256, "maplesyrup", engineID)
+	if assert.NoError(t, err) && assert.Len(t, key, 32) {
+		assert.Equal(t, "526f5eed9fcce26f8964c2930787d82b", hex.EncodeToString(key[:16]))
+	}
+
+	_, err = LocalizeAuthKey(AuthNone, "maplesyrup", engineID)
+	assert.Error(t, err, "no error for auth protocol without keys")
+	_, err = LocalizePrivKey(AuthSHA, PrivNone, "maplesyrup", engineID)
+	assert.Error(t, err, "no error for priv protocol without keys")
+	_, err = LocalizeAuthKey(AuthSHA, "maplesyrup", "auto")
+	assert.Error(t, err, "no error for engine id which is not known yet")
+}
+
+func TestManagementClient_CreateUser_Protocols(t *testing.T) {
+	server := snmpsimtest.NewServer()
+	defer server.Close()
+
+	client, err := NewManagementClient(server.URL)
+	if !assert.NoError(t, err, "error while creating a new api client") {
+		return
+	}
+	user, err := client.CreateUser("user1", "user1", "authkey1", "SHA256", "privkey1", "AES128")
+	if assert.NoError(t, err, "error during CreateUser") {
+		assert.Equal(t, string(AuthSHA256), user.AuthProto)
+		assert.Equal(t, string(PrivAES), user.PrivProto, "aes128 was not sent as aes")
+	}
+	_, err = client.CreateUser("user2", "user2", "authkey1", "sha256", "short", "aes128")
+	assert.Error(t, err, "no error for short priv key")
+	_, err = client.CreateUser("user2", "user2", "authkey1", "rot13", "", "")
+	assert.Error(t, err, "no error for unknown auth protocol")
+
+	aes128 := "AES128"
+	updated, err := client.UpdateUser(user.ID, UserUpdate{PrivProto: &aes128})
+	if assert.NoError(t, err, "error during UpdateUser") {
+		assert.Equal(t, string(PrivAES), updated.PrivProto, "aes128 was not sent as aes")
+	}
+
+	invalid := "twofish"
+	_, err = client.UpdateUser(user.ID, UserUpdate{PrivProto: &invalid})
+	assert.Error(t, err, "no error for update with unknown priv protocol")
+	users, err := client.GetUsers(nil)
+	if assert.NoError(t, err, "error during GetUsers") {
+		assert.Len(t, users, 1, "user with invalid protocols was created")
+	}
+
+	definition := UserDefinition{Name: "user1", User: "user1", AuthKey: "authkey1", AuthProto: "sha256", PrivKey: "privkey1", PrivProto: "aes128"}
+	assert.True(t, definition.matches(user), "lab spec user with aes128 does not match the created user")
+}